@@ -0,0 +1,228 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// The maximum number of trades returned by one aggTrades or historicalTrades
+// call.
+const MaxTradesLimit = 1000
+
+// GetAggTrades returns compressed (aggregate) trades, oldest first. Either
+// fromID, if not -1, or the start and end time may be used. If both start
+// and end are set they must be less than an hour apart. A zero limit uses the
+// server default of 500. As the REST API doesn't return the symbol or event
+// fields, Symbol is set from the argument and the event fields are left
+// empty.
+func (c *RestClient) GetAggTrades(symbol string, fromID int64, start time.Time, end time.Time, limit int) ([]StreamAggTrade, error) {
+	endpoint := "/api/v3/aggTrades"
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	if fromID > -1 {
+		params["fromId"] = fromID
+	}
+	if !start.IsZero() {
+		params["startTime"] = start.UnixNano() / int64(time.Millisecond)
+	}
+	if !end.IsZero() {
+		params["endTime"] = end.UnixNano() / int64(time.Millisecond)
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	var response []StreamAggTrade
+	if err := c.genericGetAndDecode(endpoint, params, &response); err != nil {
+		return nil, err
+	}
+	for i := range response {
+		response[i].Symbol = symbol
+	}
+	return response, nil
+}
+
+// GetAggTradesRange returns all the aggregate trades between start and end,
+// paging through them as needed. The first trade is found with a single
+// query from start, the rest are fetched by ID. A zero end is now.
+func (c *RestClient) GetAggTradesRange(symbol string, start time.Time, end time.Time) ([]StreamAggTrade, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	if start.IsZero() {
+		return nil, errors.New("a start time is required")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end %v is before start %v", end, start)
+	}
+
+	// With only a start time the server returns the trades from start on.
+	trades, err := c.GetAggTrades(symbol, -1, start, time.Time{}, MaxTradesLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(trades) == 0 {
+		return trades, nil
+	}
+
+	endMillis := end.UnixNano() / int64(time.Millisecond)
+	for i, trade := range trades {
+		if trade.TradeTimeMillis > endMillis {
+			return trades[:i], nil
+		}
+	}
+	if len(trades) < MaxTradesLimit {
+		return trades, nil
+	}
+	for {
+		last := trades[len(trades)-1]
+		page, err := c.GetAggTrades(symbol, last.TradeID+1, time.Time{}, time.Time{}, MaxTradesLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, trade := range page {
+			if trade.TradeTimeMillis > endMillis {
+				return trades, nil
+			}
+			trades = append(trades, trade)
+		}
+		if len(page) < MaxTradesLimit {
+			return trades, nil
+		}
+	}
+}
+
+type HistoricalTradeResponse struct {
+	ID            int64   `json:"id"`
+	Price         float64 `json:"price,string"`
+	Quantity      float64 `json:"qty,string"`
+	QuoteQuantity float64 `json:"quoteQty,string"`
+	Time          int64   `json:"time"`
+	IsBuyerMaker  bool    `json:"isBuyerMaker"`
+	IsBestMatch   bool    `json:"isBestMatch"`
+}
+
+// GetHistoricalTrades returns individual trades starting at fromID, or the
+// most recent trades if fromID is -1. It requires an API key. These are raw
+// trade IDs, not aggregate trade IDs, so they can't be merged with
+// StreamAggTrade lists.
+func (c *RestClient) GetHistoricalTrades(symbol string, fromID int64, limit int) ([]HistoricalTradeResponse, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	if fromID > -1 {
+		params["fromId"] = fromID
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+
+	httpResponse, err := c.GetWithApiKey("/api/v3/historicalTrades", params)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != 200 {
+		return nil, NewRestApiErrorFromResponse(httpResponse)
+	}
+	var response []HistoricalTradeResponse
+	if err := json.NewDecoder(httpResponse.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// MergeAggTrades merges trades of the same symbol from several sources, such
+// as a backfill and a stream, into one list ordered by ID without
+// duplicates.
+func MergeAggTrades(lists ...[]StreamAggTrade) []StreamAggTrade {
+	byID := map[int64]StreamAggTrade{}
+	for _, list := range lists {
+		for _, trade := range list {
+			byID[trade.TradeID] = trade
+		}
+	}
+	merged := make([]StreamAggTrade, 0, len(byID))
+	for _, trade := range byID {
+		merged = append(merged, trade)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].TradeID < merged[j].TradeID
+	})
+	return merged
+}
+
+// A range of missing trade IDs, inclusive.
+type AggTradeGap struct {
+	FromID int64
+	ToID   int64
+}
+
+// FindAggTradeGaps returns the IDs missing from a list of trades ordered by
+// ID, such as one returned by MergeAggTrades.
+func FindAggTradeGaps(trades []StreamAggTrade) []AggTradeGap {
+	gaps := []AggTradeGap{}
+	for i := 1; i < len(trades); i++ {
+		previous := trades[i-1].TradeID
+		if trades[i].TradeID > previous+1 {
+			gaps = append(gaps, AggTradeGap{
+				FromID: previous + 1,
+				ToID:   trades[i].TradeID - 1,
+			})
+		}
+	}
+	return gaps
+}
+
+// FillAggTradeGaps fetches the trades missing from a list ordered by ID and
+// returns the merged list.
+func (c *RestClient) FillAggTradeGaps(symbol string, trades []StreamAggTrade) ([]StreamAggTrade, error) {
+	missing := []StreamAggTrade{}
+	for _, gap := range FindAggTradeGaps(trades) {
+		fromID := gap.FromID
+		for fromID <= gap.ToID {
+			page, err := c.GetAggTrades(symbol, fromID, time.Time{}, time.Time{}, MaxTradesLimit)
+			if err != nil {
+				return nil, err
+			}
+			if len(page) == 0 {
+				break
+			}
+			for _, trade := range page {
+				if trade.TradeID > gap.ToID {
+					break
+				}
+				missing = append(missing, trade)
+			}
+			fromID = page[len(page)-1].TradeID + 1
+		}
+	}
+	return MergeAggTrades(trades, missing), nil
+}
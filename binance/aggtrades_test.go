@@ -0,0 +1,148 @@
+package binance
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// A server with an aggregate trade every 10 minutes, except for a quiet
+// period of 3 hours after the first trade.
+func newAggTradeServer(t *testing.T) *httptest.Server {
+	var trades []map[string]interface{}
+	tradeTime := int64(0)
+	for id := int64(0); id < 2500; id++ {
+		if id == 1 {
+			tradeTime += int64(3 * time.Hour / time.Millisecond)
+		}
+		trades = append(trades, map[string]interface{}{
+			"a": id, "p": "1.0", "q": "2.0", "f": id, "l": id, "T": tradeTime,
+			"m": true, "M": true,
+		})
+		tradeTime += int64(10 * time.Minute / time.Millisecond)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		page := []map[string]interface{}{}
+		if fromID := query.Get("fromId"); fromID != "" {
+			id, _ := strconv.Atoi(fromID)
+			for ; id < len(trades) && len(page) < limit; id++ {
+				page = append(page, trades[id])
+			}
+		} else {
+			start, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+			end := int64(math.MaxInt64)
+			if query.Get("endTime") != "" {
+				end, _ = strconv.ParseInt(query.Get("endTime"), 10, 64)
+				if end-start >= int64(time.Hour/time.Millisecond) {
+					t.Errorf("window of more than an hour: %s", r.URL.RawQuery)
+				}
+			}
+			for _, trade := range trades {
+				tradeTime := trade["T"].(int64)
+				if tradeTime >= start && tradeTime <= end && len(page) < limit {
+					page = append(page, trade)
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func TestGetAggTradesRange(t *testing.T) {
+	requests := 0
+	server := newAggTradeServer(t)
+	defer server.Close()
+	counter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Redirect(w, r, server.URL+r.URL.String(), http.StatusTemporaryRedirect)
+	}))
+	defer counter.Close()
+	client := NewAnonymousClient(WithBaseURL(counter.URL))
+
+	// Starts in the quiet period, but the first trade is still found with
+	// one request.
+	start := time.Unix(3600, 0)
+	end := start.Add(2 * time.Hour).Add(20000 * time.Minute)
+	trades, err := client.GetAggTradesRange("ETHBTC", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2000 {
+		t.Fatalf("expected 2000 trades, got %d", len(trades))
+	}
+	if trades[0].TradeID != 1 || trades[len(trades)-1].TradeID != 2000 {
+		t.Fatalf("unexpected first and last trades: %d, %d",
+			trades[0].TradeID, trades[len(trades)-1].TradeID)
+	}
+	if trades[0].Symbol != "ETHBTC" {
+		t.Fatalf("expected symbol to be set, got %s", trades[0].Symbol)
+	}
+	if gaps := FindAggTradeGaps(trades); len(gaps) != 0 {
+		t.Fatalf("unexpected gaps: %v", gaps)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+
+	if _, err := client.GetAggTradesRange("ETHBTC", time.Time{}, end); err == nil {
+		t.Fatalf("expected error for a zero start")
+	}
+	if _, err := client.GetAggTradesRange("ETHBTC", end, start); err == nil {
+		t.Fatalf("expected error for end before start")
+	}
+}
+
+func TestMergeAndFillAggTradeGaps(t *testing.T) {
+	server := newAggTradeServer(t)
+	defer server.Close()
+	client := NewAnonymousClient(WithBaseURL(server.URL))
+
+	backfill := []StreamAggTrade{{TradeID: 10}, {TradeID: 11}, {TradeID: 12}}
+	stream := []StreamAggTrade{{TradeID: 12}, {TradeID: 16}, {TradeID: 17}}
+	merged := MergeAggTrades(stream, backfill)
+	if len(merged) != 5 {
+		t.Fatalf("expected 5 trades, got %d", len(merged))
+	}
+	gaps := FindAggTradeGaps(merged)
+	if len(gaps) != 1 || gaps[0] != (AggTradeGap{FromID: 13, ToID: 15}) {
+		t.Fatalf("unexpected gaps: %v", gaps)
+	}
+
+	filled, err := client.FillAggTradeGaps("ETHBTC", merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filled) != 8 || len(FindAggTradeGaps(filled)) != 0 {
+		t.Fatalf("expected 8 trades without gaps, got %d", len(filled))
+	}
+}
+
+func TestGetHistoricalTrades(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-MBX-APIKEY") != "key" {
+			t.Errorf("expected API key header")
+		}
+		if r.URL.Query().Get("signature") != "" {
+			t.Errorf("request should not be signed")
+		}
+		w.Write([]byte(`[{"id":28457,"price":"4.00000100","qty":"12.00000000","quoteQty":"48.000012","time":1499865549590,"isBuyerMaker":true,"isBestMatch":true}]`))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	trades, err := client.GetHistoricalTrades("BNBBTC", 28457, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].ID != 28457 || trades[0].Price != 4.000001 ||
+		trades[0].Quantity != 12 || trades[0].Time != 1499865549590 {
+		t.Fatalf("unexpected trades: %+v", trades)
+	}
+}
@@ -25,77 +25,145 @@
 package binance
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
-	"github.com/gorilla/websocket"
-	"encoding/json"
+	"sync"
 )
 
+// ErrStreamClosed is returned by Next once the stream has been closed.
+var ErrStreamClosed = errors.New("stream closed")
+
+// AggTradeStreamEvent is a trade, an error or, with a nil Trade and Err,
+// the end of the stream. Events sent by SubscribeWithConnectionState may
+// also be a StreamEventConnected event with neither set. Trades may have
+// been missed between a StreamEventDisconnected event and the next
+// StreamEventConnected event.
 type AggTradeStreamEvent struct {
+	Type  StreamEventType
 	Err   error
 	Trade *StreamAggTrade
 }
 
+// AggTradeStream is an aggregate trade stream for a single symbol on top of
+// a ManagedStream, so it reconnects instead of ending on a read error.
 type AggTradeStream struct {
-	ws             *websocket.Conn
-	closeRequested bool
+	stream  *ManagedStream
+	events  chan ManagedStreamEvent
+	runOnce sync.Once
 }
 
-func OpenAggTradeStream(symbol string) (*AggTradeStream, error) {
-	ws, err := openStream(fmt.Sprintf("ws/%s@aggTrade", strings.ToLower(symbol)))
-	if err != nil {
-		return nil, err
-	}
+// NewAggTradeStream creates an aggregate trade stream for symbol. It does
+// not connect until Next or Subscribe is called.
+func NewAggTradeStream(symbol string, opts ...ManagedStreamOption) *AggTradeStream {
 	return &AggTradeStream{
-		ws:             ws,
-		closeRequested: false,
-	}, nil
+		stream: NewManagedSingleStream(
+			fmt.Sprintf("%s@aggTrade", strings.ToLower(symbol)), opts...),
+		events: make(chan ManagedStreamEvent),
+	}
+}
+
+// OpenAggTradeStream creates an aggregate trade stream for symbol and waits
+// for the first connection, returning an error if it fails.
+func OpenAggTradeStream(symbol string, opts ...StreamClientOption) (*AggTradeStream, error) {
+	s := NewAggTradeStream(symbol, WithStreamClientOptions(opts...))
+	s.run()
+	event := <-s.events
+	if event.Type != StreamEventConnected {
+		s.Close()
+		return nil, event.Err
+	}
+	return s, nil
+}
+
+func (c *AggTradeStream) run() {
+	c.runOnce.Do(func() {
+		go c.stream.Run(c.events)
+	})
 }
 
-// Close closes the AggTradeStream. If there is a subscribed channel it will
-// no longer be sent any data. So it is up to the subscriber to stop attempting
-// to read from the channel.
+// Close closes the AggTradeStream. A subscribed channel is sent a
+// StreamEventClosed event, if it is ready to receive it, and then no more
+// events.
 func (c *AggTradeStream) Close() {
-	c.closeRequested = true
-	c.ws.Close()
+	c.stream.Close()
 }
 
-func (c *AggTradeStream) Next() (trade *StreamAggTrade, err error) {
-	_, buf, err := c.ws.ReadMessage()
-	if err != nil {
-		return nil, err
+// nextEvent returns the next event of the managed stream with trade
+// messages decoded.
+func (c *AggTradeStream) nextEvent() AggTradeStreamEvent {
+	for {
+		var event ManagedStreamEvent
+		select {
+		case event = <-c.events:
+		case <-c.stream.closed:
+			return AggTradeStreamEvent{Type: StreamEventClosed}
+		}
+		if event.Type != StreamEventMessage {
+			return AggTradeStreamEvent{Type: event.Type, Err: event.Err}
+		}
+		var trade StreamAggTrade
+		if err := json.Unmarshal(event.Message, &trade); err != nil {
+			return AggTradeStreamEvent{Type: event.Type, Err: err}
+		}
+		if trade.EventType != "aggTrade" {
+			// Not a trade, such as a subscription response.
+			continue
+		}
+		return AggTradeStreamEvent{Type: event.Type, Trade: &trade}
 	}
-	var rawAggTrade StreamAggTrade
-	if err := json.Unmarshal(buf, &rawAggTrade); err != nil {
-		return nil, err
+}
+
+// Next returns the next trade. A lost connection is returned as an error,
+// but the stream reconnects on its own so Next may be called again. Once
+// the stream is closed ErrStreamClosed is returned.
+func (c *AggTradeStream) Next() (trade *StreamAggTrade, err error) {
+	c.run()
+	for {
+		event := c.nextEvent()
+		switch {
+		case event.Type == StreamEventClosed:
+			return nil, ErrStreamClosed
+		case event.Trade != nil || event.Err != nil:
+			return event.Trade, event.Err
+		}
 	}
-	return &rawAggTrade, nil
 }
 
+// Subscribe sends trades and errors to channel until the stream is closed,
+// so is usually run in its own goroutine. A lost connection is sent as a
+// StreamEventDisconnected event with the error, after which the stream
+// reconnects on its own. Once closed, an event with a nil Trade and Err is
+// sent if the channel is ready to receive it.
 func (c *AggTradeStream) Subscribe(channel chan AggTradeStreamEvent) {
+	c.subscribe(channel, false)
+}
+
+// SubscribeWithConnectionState is like Subscribe but also sends
+// StreamEventConnected events, which have a nil Trade and Err, so their
+// Type must be checked to tell them from the end of the stream.
+func (c *AggTradeStream) SubscribeWithConnectionState(channel chan AggTradeStreamEvent) {
+	c.subscribe(channel, true)
+}
+
+func (c *AggTradeStream) subscribe(channel chan AggTradeStreamEvent, connectionState bool) {
+	c.run()
 	for {
-		trade, err := c.Next()
-		if err != nil {
-			if !c.closeRequested {
-				channel <- AggTradeStreamEvent{
-					Err: err,
-				}
-			} else {
-				// Close was requested. Attempt to send down a nil, nil message
-				// as a signal that the stream has now been closed.
-				select {
-				case channel <- AggTradeStreamEvent{
-					Err:   nil,
-					Trade: nil,
-				}:
-				default:
-				}
+		event := c.nextEvent()
+		if event.Type == StreamEventClosed {
+			select {
+			case channel <- event:
+			default:
 			}
 			return
-		} else {
-			channel <- AggTradeStreamEvent{
-				Trade: trade,
-			}
+		}
+		if event.Type == StreamEventConnected && !connectionState {
+			continue
+		}
+		select {
+		case channel <- event:
+		case <-c.stream.closed:
 		}
 	}
 }
@@ -0,0 +1,291 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+// A change to the balance of an asset.
+type BalanceChange struct {
+	Asset    string
+	Previous core.Balance
+	Current  core.Balance
+}
+
+type assetBalance struct {
+	free   float64
+	locked float64
+
+	// The account update time of the last absolute balance, from GetAccount
+	// or an account event. Deltas don't change it as they are stamped with
+	// the event time, which is later than the update time of the account
+	// event that follows them.
+	updateTimeMillis int64
+}
+
+type reservation struct {
+	asset  string
+	amount float64
+}
+
+// BalanceBook is a live view of the account balances, loaded with GetAccount
+// and kept up to date from user stream events.
+type BalanceBook struct {
+	client *RestClient
+
+	lock         sync.Mutex
+	balances     map[string]*assetBalance
+	loaded       bool
+	reservations map[string]reservation
+	onChange     []func(BalanceChange)
+}
+
+func NewBalanceBook(client *RestClient) *BalanceBook {
+	return &BalanceBook{
+		client:       client,
+		balances:     map[string]*assetBalance{},
+		reservations: map[string]reservation{},
+	}
+}
+
+// OnChange registers a callback called after the balance of an asset
+// changes. Callbacks are called without the lock held, so may read the
+// book.
+func (b *BalanceBook) OnChange(callback func(BalanceChange)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.onChange = append(b.onChange, callback)
+}
+
+// Load replaces the balances with those of GetAccount. The first load
+// replaces all balances, later loads only those not updated since the
+// account snapshot, as stream events may arrive while it is fetched.
+func (b *BalanceBook) Load() error {
+	account, err := b.client.GetAccount()
+	if err != nil {
+		return err
+	}
+	b.lock.Lock()
+	force := !b.loaded
+	b.loaded = true
+	changes := []BalanceChange{}
+	seen := map[string]bool{}
+	for _, balance := range account.Balances {
+		seen[balance.Asset] = true
+		changes = b.set(changes, balance.Asset, balance.Free, balance.Locked,
+			account.UpdateTimeMillis, force)
+	}
+	for asset := range b.balances {
+		if !seen[asset] {
+			changes = b.set(changes, asset, 0, 0, account.UpdateTimeMillis, force)
+		}
+	}
+	b.lock.Unlock()
+	b.notify(changes)
+	return nil
+}
+
+// Attach updates the book from the events of stream, and reloads it each
+// time the stream connects as events may have been missed.
+func (b *BalanceBook) Attach(stream *UserStream) {
+	stream.OnAccountInfo(b.ApplyAccountInfo)
+	stream.OnAccountPosition(b.ApplyAccountPosition)
+	stream.OnBalanceUpdate(b.ApplyBalanceUpdate)
+	stream.OnConnectionState(func(state StreamEventType, err error) {
+		if state == StreamEventConnected {
+			go b.Load()
+		}
+	})
+}
+
+// ApplyAccountInfo applies the balances of an outboundAccountInfo event.
+func (b *BalanceBook) ApplyAccountInfo(info *StreamOutboundAccountInfo) {
+	b.applyBalances(info.Balances, info.LastAccountUpdateTime)
+}
+
+// ApplyAccountPosition applies the changed balances of an
+// outboundAccountPosition event.
+func (b *BalanceBook) ApplyAccountPosition(position *StreamOutboundAccountPosition) {
+	b.applyBalances(position.Balances, position.LastAccountUpdateTime)
+}
+
+func (b *BalanceBook) applyBalances(balances []StreamAccountInfoBalance, updateTimeMillis int64) {
+	b.lock.Lock()
+	changes := []BalanceChange{}
+	for _, balance := range balances {
+		changes = b.set(changes, balance.Asset, balance.Free, balance.Locked,
+			updateTimeMillis, false)
+	}
+	b.lock.Unlock()
+	b.notify(changes)
+}
+
+// ApplyBalanceUpdate applies the deposit, withdrawal or transfer of a
+// balanceUpdate event to the free balance.
+func (b *BalanceBook) ApplyBalanceUpdate(update *StreamBalanceUpdate) {
+	b.lock.Lock()
+	changes := []BalanceChange{}
+	current := b.balances[update.Asset]
+	free, locked := update.Delta, 0.0
+	updateTimeMillis := int64(0)
+	if current != nil {
+		if update.ClearTimeMillis <= current.updateTimeMillis {
+			// Cleared before the current balance was updated, so already
+			// included in it. The event time can't be used as it may be
+			// after the update time of a snapshot that includes it.
+			b.lock.Unlock()
+			return
+		}
+		free += current.free
+		locked = current.locked
+		updateTimeMillis = current.updateTimeMillis
+	}
+	changes = b.set(changes, update.Asset, free, locked, updateTimeMillis, false)
+	b.lock.Unlock()
+	b.notify(changes)
+}
+
+// set updates the balance of an asset, appending to changes if it changed.
+// Updates older than the current balance are ignored unless force is set.
+// Must be called with the lock held.
+func (b *BalanceBook) set(changes []BalanceChange, asset string, free float64, locked float64, updateTimeMillis int64, force bool) []BalanceChange {
+	current, ok := b.balances[asset]
+	if !ok {
+		current = &assetBalance{}
+		b.balances[asset] = current
+	} else if !force && updateTimeMillis < current.updateTimeMillis {
+		return changes
+	}
+	previous := *current
+	current.free = free
+	current.locked = locked
+	current.updateTimeMillis = updateTimeMillis
+	if previous.free == free && previous.locked == locked {
+		return changes
+	}
+	return append(changes, BalanceChange{
+		Asset: asset,
+		Previous: core.Balance{
+			Asset:  asset,
+			Free:   previous.free,
+			Locked: previous.locked,
+		},
+		Current: core.Balance{
+			Asset:  asset,
+			Free:   free,
+			Locked: locked,
+		},
+	})
+}
+
+func (b *BalanceBook) notify(changes []BalanceChange) {
+	if len(changes) == 0 {
+		return
+	}
+	b.lock.Lock()
+	callbacks := b.onChange
+	b.lock.Unlock()
+	for _, change := range changes {
+		for _, callback := range callbacks {
+			callback(change)
+		}
+	}
+}
+
+// Balance returns the free and locked balance of an asset.
+func (b *BalanceBook) Balance(asset string) core.Balance {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	balance := core.Balance{
+		Asset: asset,
+	}
+	if current, ok := b.balances[asset]; ok {
+		balance.Free = current.free
+		balance.Locked = current.locked
+	}
+	return balance
+}
+
+// Balances returns the non-zero balances ordered by asset.
+func (b *BalanceBook) Balances() []core.Balance {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	balances := []core.Balance{}
+	for asset, current := range b.balances {
+		if current.free == 0 && current.locked == 0 {
+			continue
+		}
+		balances = append(balances, core.Balance{
+			Asset:  asset,
+			Free:   current.free,
+			Locked: current.locked,
+		})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances
+}
+
+// Reserve sets aside an amount of an asset under an ID, usually the client
+// order ID of an order about to be placed. Binance locks the funds of open
+// orders itself; reservations cover orders that are not yet acknowledged, so
+// concurrent checks don't spend the same funds twice.
+func (b *BalanceBook) Reserve(id string, asset string, amount float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.reservations[id] = reservation{
+		asset:  asset,
+		amount: amount,
+	}
+}
+
+// Release removes a reservation, once the order is open or failed.
+func (b *BalanceBook) Release(id string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.reservations, id)
+}
+
+// Available returns the free balance of an asset, which already excludes the
+// funds locked by open orders, less the reservations for the asset.
+func (b *BalanceBook) Available(asset string) float64 {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	available := 0.0
+	if current, ok := b.balances[asset]; ok {
+		available = current.free
+	}
+	for _, reserved := range b.reservations {
+		if reserved.asset == asset {
+			available -= reserved.amount
+		}
+	}
+	return available
+}
@@ -0,0 +1,160 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBalanceBook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"makerCommission":15,"takerCommission":15,"buyerCommission":0,"sellerCommission":0,"canTrade":true,"canWithdraw":true,"canDeposit":true,"updateTime":1000,"balances":[{"asset":"BTC","free":"1.5","locked":"0.5"},{"asset":"ETH","free":"10.0","locked":"0.0"}]}`))
+	}))
+	defer server.Close()
+
+	book := NewBalanceBook(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	changes := []BalanceChange{}
+	book.OnChange(func(change BalanceChange) {
+		changes = append(changes, change)
+	})
+	if err := book.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if balance := book.Balance("BTC"); balance.Free != 1.5 || balance.Locked != 0.5 {
+		t.Fatalf("unexpected BTC balance: %+v", balance)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes from load, got %d", len(changes))
+	}
+
+	book.ApplyAccountPosition(&StreamOutboundAccountPosition{
+		LastAccountUpdateTime: 2000,
+		Balances: []StreamAccountInfoBalance{
+			{Asset: "BTC", Free: 1.0, Locked: 1.0},
+		},
+	})
+	// Older than the last update, ignored.
+	book.ApplyAccountPosition(&StreamOutboundAccountPosition{
+		LastAccountUpdateTime: 1500,
+		Balances: []StreamAccountInfoBalance{
+			{Asset: "BTC", Free: 5.0, Locked: 0},
+		},
+	})
+	book.ApplyBalanceUpdate(&StreamBalanceUpdate{
+		EventTimeMillis: 3000,
+		ClearTimeMillis: 3000,
+		Asset:           "ETH",
+		Delta:           -4,
+	})
+
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %d: %+v", len(changes), changes)
+	}
+	if change := changes[2]; change.Previous.Free != 1.5 || change.Current.Free != 1.0 {
+		t.Fatalf("unexpected BTC change: %+v", change)
+	}
+	if balance := book.Balance("ETH"); balance.Free != 6 {
+		t.Fatalf("unexpected ETH balance: %+v", balance)
+	}
+
+	book.Reserve("order-1", "BTC", 0.25)
+	book.Reserve("order-2", "ETH", 1)
+	if available := book.Available("BTC"); available != 0.75 {
+		t.Fatalf("expected 0.75 BTC available, got %f", available)
+	}
+	book.Release("order-1")
+	if available := book.Available("BTC"); available != 1.0 {
+		t.Fatalf("expected 1 BTC available, got %f", available)
+	}
+	if balances := book.Balances(); len(balances) != 2 || balances[0].Asset != "BTC" {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+}
+
+func TestBalanceBookEventOrdering(t *testing.T) {
+	updateTime := 1000
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"updateTime":%d,"balances":[{"asset":"BTC","free":"1.0","locked":"0.0"}]}`, updateTime)
+	}))
+	defer server.Close()
+
+	book := NewBalanceBook(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	if err := book.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A deposit is sent as a balanceUpdate followed by an
+	// outboundAccountPosition with an update time before the event time of
+	// the balanceUpdate.
+	book.ApplyBalanceUpdate(&StreamBalanceUpdate{
+		EventTimeMillis: 2005,
+		ClearTimeMillis: 2004,
+		Asset:           "BTC",
+		Delta:           0.5,
+	})
+	if balance := book.Balance("BTC"); balance.Free != 1.5 {
+		t.Fatalf("unexpected BTC balance after delta: %+v", balance)
+	}
+	book.ApplyAccountPosition(&StreamOutboundAccountPosition{
+		EventTimeMillis:       2006,
+		LastAccountUpdateTime: 2004,
+		Balances: []StreamAccountInfoBalance{
+			{Asset: "BTC", Free: 1.25, Locked: 0.25},
+		},
+	})
+	if balance := book.Balance("BTC"); balance.Free != 1.25 || balance.Locked != 0.25 {
+		t.Fatalf("position after delta not applied: %+v", balance)
+	}
+
+	// A reload fetched before the last position must not replace it.
+	updateTime = 1500
+	if err := book.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if balance := book.Balance("BTC"); balance.Free != 1.25 {
+		t.Fatalf("reload replaced a newer balance: %+v", balance)
+	}
+
+	updateTime = 3000
+	if err := book.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if balance := book.Balance("BTC"); balance.Free != 1.0 {
+		t.Fatalf("newer reload not applied: %+v", balance)
+	}
+}
+
+func TestBalanceBookUpdateAfterLoad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"updateTime":3000,"balances":[{"asset":"BTC","free":"1.0","locked":"0.0"}]}`))
+	}))
+	defer server.Close()
+
+	book := NewBalanceBook(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	if err := book.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cleared before the snapshot but received after it, so already
+	// included in the loaded balance.
+	book.ApplyBalanceUpdate(&StreamBalanceUpdate{
+		EventTimeMillis: 3100,
+		ClearTimeMillis: 2500,
+		Asset:           "BTC",
+		Delta:           0.5,
+	})
+	if balance := book.Balance("BTC"); balance.Free != 1.0 {
+		t.Fatalf("update included in the snapshot applied again: %+v", balance)
+	}
+
+	book.ApplyBalanceUpdate(&StreamBalanceUpdate{
+		EventTimeMillis: 3300,
+		ClearTimeMillis: 3200,
+		Asset:           "BTC",
+		Delta:           0.5,
+	})
+	if balance := book.Balance("BTC"); balance.Free != 1.5 {
+		t.Fatalf("update after the snapshot not applied: %+v", balance)
+	}
+}
@@ -0,0 +1,201 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorCode is the code of a Binance API error response.
+type ErrorCode int64
+
+const (
+	ErrorCodeUnknown             ErrorCode = -1000
+	ErrorCodeDisconnected        ErrorCode = -1001
+	ErrorCodeUnauthorized        ErrorCode = -1002
+	ErrorCodeTooManyRequests     ErrorCode = -1003
+	ErrorCodeUnexpectedResponse  ErrorCode = -1006
+	ErrorCodeTimeout             ErrorCode = -1007
+	ErrorCodeInvalidMessage      ErrorCode = -1013
+	ErrorCodeTooManyOrders       ErrorCode = -1015
+	ErrorCodeServiceShuttingDown ErrorCode = -1016
+	ErrorCodeInvalidTimestamp    ErrorCode = -1021
+	ErrorCodeInvalidSignature    ErrorCode = -1022
+	ErrorCodeInvalidListenKey    ErrorCode = -1125
+	ErrorCodeNewOrderRejected    ErrorCode = -2010
+	ErrorCodeCancelRejected      ErrorCode = -2011
+	ErrorCodeNoSuchOrder         ErrorCode = -2013
+	ErrorCodeBadApiKeyFormat     ErrorCode = -2014
+	ErrorCodeRejectedApiKey      ErrorCode = -2015
+)
+
+// Errors that a RestApiError can be matched against with errors.Is.
+var (
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrUnknownOrder        = errors.New("unknown order")
+	ErrFilterFailure       = errors.New("filter failure")
+	ErrInvalidTimestamp    = errors.New("timestamp outside of recvWindow")
+	ErrTooManyRequests     = errors.New("too many requests")
+	ErrIPBanned            = errors.New("ip banned")
+)
+
+type RestApiError struct {
+	StatusCode int
+	Body       []byte
+
+	// The code and message of the error payload, if the body was one.
+	Code ErrorCode
+	Msg  string
+
+	// The value of the Retry-After header sent with 429 and 418 responses.
+	RetryAfter time.Duration
+}
+
+func NewRestApiErrorFromResponse(r *http.Response) *RestApiError {
+	body, _ := ioutil.ReadAll(r.Body)
+	apiError := &RestApiError{
+		StatusCode: r.StatusCode,
+		Body:       body,
+	}
+
+	var payload struct {
+		Code ErrorCode `json:"code"`
+		Msg  string    `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil {
+		apiError.Code = payload.Code
+		apiError.Msg = payload.Msg
+	}
+
+	apiError.RetryAfter = parseRetryAfter(r)
+
+	return apiError
+}
+
+// parseRetryAfter returns the duration of the Retry-After header, in
+// seconds, or 0 if there isn't one.
+func parseRetryAfter(r *http.Response) time.Duration {
+	if retryAfter := r.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
+
+func (e *RestApiError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("%d: %s", e.Code, e.Msg)
+	}
+	return string(e.Body)
+}
+
+// Is allows a RestApiError to be matched against the Err* values of this
+// package with errors.Is.
+func (e *RestApiError) Is(target error) bool {
+	switch target {
+	case ErrInsufficientBalance:
+		return e.Code == ErrorCodeNewOrderRejected &&
+			strings.Contains(strings.ToLower(e.Msg), "insufficient balance")
+	case ErrUnknownOrder:
+		return e.Code == ErrorCodeNoSuchOrder ||
+			(e.Code == ErrorCodeCancelRejected &&
+				strings.Contains(strings.ToLower(e.Msg), "unknown order"))
+	case ErrFilterFailure:
+		return e.Code == ErrorCodeInvalidMessage &&
+			strings.HasPrefix(e.Msg, "Filter failure")
+	case ErrInvalidTimestamp:
+		return e.Code == ErrorCodeInvalidTimestamp
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests ||
+			e.Code == ErrorCodeTooManyRequests ||
+			e.Code == ErrorCodeTooManyOrders
+	case ErrIPBanned:
+		return e.StatusCode == http.StatusTeapot
+	}
+	return false
+}
+
+// FailedFilter returns the name of the filter for a filter failure, for
+// example "LOT_SIZE".
+func (e *RestApiError) FailedFilter() string {
+	if !e.Is(ErrFilterFailure) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(e.Msg, "Filter failure:"))
+}
+
+// IsRateLimited returns true if the request was rejected for exceeding a
+// rate limit, including an IP ban for repeatedly doing so.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrTooManyRequests) || errors.Is(err, ErrIPBanned)
+}
+
+// IsRetryable returns true if the error is transient and the same request
+// may succeed if sent again, after RetryAfter if it is set. Order
+// rejections, such as insufficient balance or a filter failure, are not
+// retryable. Note that the execution status of an order that failed with a
+// 5xx response is unknown, so it should be queried before being retried.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiError *RestApiError
+	if errors.As(err, &apiError) {
+		if IsRateLimited(apiError) || apiError.Is(ErrInvalidTimestamp) {
+			return true
+		}
+		switch apiError.Code {
+		case ErrorCodeUnknown, ErrorCodeDisconnected,
+			ErrorCodeUnexpectedResponse, ErrorCodeTimeout,
+			ErrorCodeServiceShuttingDown:
+			return true
+		}
+		return apiError.StatusCode >= 500
+	}
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return netError.Timeout()
+	}
+	return false
+}
+
+// RetryAfter returns how long to wait before retrying a request that failed
+// with err, or 0 if the server did not say.
+func RetryAfter(err error) time.Duration {
+	var apiError *RestApiError
+	if errors.As(err, &apiError) {
+		return apiError.RetryAfter
+	}
+	return 0
+}
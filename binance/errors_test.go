@@ -0,0 +1,68 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestErrorResponse(statusCode int, body string, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func TestRestApiErrorClassification(t *testing.T) {
+	err := NewRestApiErrorFromResponse(newTestErrorResponse(400,
+		`{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, nil))
+	if err.Code != ErrorCodeNewOrderRejected {
+		t.Fatalf("unexpected code: %d", err.Code)
+	}
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("expected insufficient balance error")
+	}
+	if IsRetryable(err) {
+		t.Errorf("insufficient balance should not be retryable")
+	}
+
+	err = NewRestApiErrorFromResponse(newTestErrorResponse(400,
+		`{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`, nil))
+	if !errors.Is(err, ErrFilterFailure) || err.FailedFilter() != "LOT_SIZE" {
+		t.Errorf("expected LOT_SIZE filter failure, got %v", err)
+	}
+
+	err = NewRestApiErrorFromResponse(newTestErrorResponse(400,
+		`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`, nil))
+	if !errors.Is(err, ErrInvalidTimestamp) || !IsRetryable(err) {
+		t.Errorf("expected retryable timestamp error")
+	}
+
+	// Wrapped errors are still classified.
+	wrapped := fmt.Errorf("failed to cancel: %w", NewRestApiErrorFromResponse(
+		newTestErrorResponse(400, `{"code":-2011,"msg":"Unknown order sent."}`, nil)))
+	if !errors.Is(wrapped, ErrUnknownOrder) {
+		t.Errorf("expected unknown order error")
+	}
+}
+
+func TestRestApiErrorRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+	err := NewRestApiErrorFromResponse(newTestErrorResponse(418,
+		`{"code":-1003,"msg":"Way too many requests; IP banned until 1507725176595."}`, header))
+	if !errors.Is(err, ErrIPBanned) || !IsRateLimited(err) || !IsRetryable(err) {
+		t.Errorf("expected rate limited ip ban")
+	}
+	if RetryAfter(err) != 120*time.Second {
+		t.Errorf("unexpected retry after: %v", RetryAfter(err))
+	}
+}
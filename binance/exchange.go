@@ -0,0 +1,234 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+const ExchangeName = "Binance"
+
+var _ core.Exchange = (*Exchange)(nil)
+
+// Exchange adapts a RestClient to the core.Exchange interface.
+type Exchange struct {
+	client *RestClient
+}
+
+func NewExchange(client *RestClient) *Exchange {
+	return &Exchange{
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+// Tickers returns the tickers for the requested symbols, or all symbols if
+// none are requested.
+func (e *Exchange) Tickers(symbols ...string) ([]core.Ticker, error) {
+	prices, err := e.client.GetAllPriceTicker()
+	if err != nil {
+		return nil, err
+	}
+	books, err := e.client.GetAllOrderBookTickers()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	wanted := map[string]bool{}
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	bookTickers := map[string]OrderBookTickerResponse{}
+	for _, book := range books {
+		bookTickers[book.Symbol] = book
+	}
+
+	tickers := []core.Ticker{}
+	for _, price := range prices {
+		if len(wanted) > 0 && !wanted[price.Symbol] {
+			continue
+		}
+		book := bookTickers[price.Symbol]
+		tickers = append(tickers, core.Ticker{
+			Exchange:  ExchangeName,
+			Symbol:    price.Symbol,
+			Timestamp: now,
+			Bid:       book.BidPrice,
+			Ask:       book.AskPrice,
+			Last:      price.Price,
+		})
+	}
+	return tickers, nil
+}
+
+func (e *Exchange) OrderBook(symbol string, depth int) (*core.OrderBook, error) {
+	response, err := e.client.GetDepth(symbol, depth)
+	if err != nil {
+		return nil, err
+	}
+	book := &core.OrderBook{
+		Exchange:  ExchangeName,
+		Symbol:    symbol,
+		Timestamp: time.Now(),
+	}
+	for _, bid := range response.Bids {
+		book.Bids = append(book.Bids, core.OrderBookEntry{
+			Price:    bid.Price,
+			Quantity: bid.Quantity,
+		})
+	}
+	for _, ask := range response.Asks {
+		book.Asks = append(book.Asks, core.OrderBookEntry{
+			Price:    ask.Price,
+			Quantity: ask.Quantity,
+		})
+	}
+	return book, nil
+}
+
+func (e *Exchange) Balances() ([]core.Balance, error) {
+	account, err := e.client.GetAccount()
+	if err != nil {
+		return nil, err
+	}
+	balances := []core.Balance{}
+	for _, balance := range account.Balances {
+		if balance.Free == 0 && balance.Locked == 0 {
+			continue
+		}
+		balances = append(balances, core.Balance{
+			Asset:  balance.Asset,
+			Free:   balance.Free,
+			Locked: balance.Locked,
+		})
+	}
+	return balances, nil
+}
+
+func (e *Exchange) PlaceOrder(order core.OrderRequest) (*core.Order, error) {
+	params := OrderParameters{
+		Symbol:           order.Symbol,
+		Side:             OrderSide(order.Side),
+		Type:             OrderType(order.Type),
+		Quantity:         order.Quantity,
+		Price:            order.Price,
+		NewClientOrderId: order.ClientOrderID,
+		NewOrderRespType: OrderResponseTypeResult,
+	}
+	if params.Type == OrderTypeLimit {
+		params.TimeInForce = TimeInForceGTC
+	}
+
+	response, err := e.client.PostOrder(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.Order{
+		Exchange:      ExchangeName,
+		Symbol:        response.Symbol,
+		OrderID:       strconv.FormatInt(response.OrderId, 10),
+		ClientOrderID: response.ClientOrderId,
+		Side:          order.Side,
+		Type:          order.Type,
+		Status:        core.OrderStatus(response.Status),
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		ExecutedQty:   response.ExecutedQty,
+		Timestamp:     millisToTime(response.TransactionTimeMillis),
+	}, nil
+}
+
+func (e *Exchange) CancelOrder(symbol string, orderID string) error {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return err
+	}
+	_, err = e.client.CancelOrder(symbol, id)
+	return err
+}
+
+func (e *Exchange) QueryOrder(symbol string, orderID string) (*core.Order, error) {
+	id, err := strconv.ParseInt(orderID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	response, err := e.client.GetOrderByOrderId(symbol, id)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Order{
+		Exchange:      ExchangeName,
+		Symbol:        response.Symbol,
+		OrderID:       strconv.FormatInt(response.OrderId, 10),
+		ClientOrderID: response.ClientOrderId,
+		Side:          core.OrderSide(response.Side),
+		Type:          core.OrderType(response.Type),
+		Status:        core.OrderStatus(response.Status),
+		Price:         response.Price,
+		Quantity:      response.OrigQty,
+		ExecutedQty:   response.ExecutedQty,
+		Timestamp:     millisToTime(response.TimeMillis),
+	}, nil
+}
+
+func (e *Exchange) Trades(symbol string) ([]core.Trade, error) {
+	response, err := e.client.GetMytrades(symbol, 1000, -1)
+	if err != nil {
+		return nil, err
+	}
+	trades := []core.Trade{}
+	for _, trade := range response {
+		side := core.OrderSideSell
+		if trade.IsBuyer {
+			side = core.OrderSideBuy
+		}
+		trades = append(trades, core.Trade{
+			Exchange:  ExchangeName,
+			Symbol:    symbol,
+			TradeID:   strconv.FormatInt(trade.ID, 10),
+			OrderID:   strconv.FormatInt(trade.OrderID, 10),
+			Side:      side,
+			Price:     trade.Price,
+			Quantity:  trade.Quantity,
+			Fee:       trade.Commission,
+			FeeAsset:  trade.CommissionAsset,
+			Timestamp: millisToTime(trade.TimeMillis),
+		})
+	}
+	return trades, nil
+}
+
+func millisToTime(millis int64) time.Time {
+	return time.Unix(0, millis*int64(time.Millisecond))
+}
@@ -30,33 +30,104 @@ type SymbolInfo struct {
 	TickSize float64
 	StepSize float64
 	MinNotional float64
+
+	// PRICE_FILTER.
+	MinPrice float64
+	MaxPrice float64
+
+	// LOT_SIZE.
+	MinQty float64
+	MaxQty float64
+
+	// MARKET_LOT_SIZE, used for the quantity of MARKET orders.
+	MarketMinQty   float64
+	MarketMaxQty   float64
+	MarketStepSize float64
+
+	// MIN_NOTIONAL or NOTIONAL.
+	MaxNotional         float64
+	MinNotionalToMarket bool
+
+	// PERCENT_PRICE, relative to the average price over AvgPriceMins.
+	MultiplierUp   float64
+	MultiplierDown float64
+	AvgPriceMins   int64
+
+	// MAX_NUM_ORDERS and MAX_NUM_ALGO_ORDERS.
+	MaxNumOrders     int64
+	MaxNumAlgoOrders int64
+
+	// ICEBERG_PARTS.
+	IcebergParts int64
+
+	OrderTypes     []OrderType
+	IcebergAllowed bool
+	OcoAllowed     bool
 }
 
 type ExchangeInfoService struct {
 	Symbols map[string]SymbolInfo
+	client  *RestClient
 }
 
 func NewExchangeInfoService() *ExchangeInfoService {
+	return NewExchangeInfoServiceWithClient(NewAnonymousClient())
+}
+
+// NewExchangeInfoServiceWithClient creates an ExchangeInfoService that
+// updates using the provided client.
+func NewExchangeInfoServiceWithClient(client *RestClient) *ExchangeInfoService {
 	return &ExchangeInfoService{
 		Symbols: make(map[string]SymbolInfo),
+		client:  client,
 	}
 }
 
 func (s *ExchangeInfoService) Update() error {
-	exchangeInfo, err := GetExchangeInfo()
+	exchangeInfo, err := s.client.GetExchangeInfo()
 	if err != nil {
 		return err
 	}
 	for _, symbol := range exchangeInfo.Symbols {
-		symbolInfo := SymbolInfo{}
+		symbolInfo := SymbolInfo{
+			IcebergAllowed: symbol.IcebergAllowed,
+			OcoAllowed:     symbol.OcoAllowed,
+		}
+		for _, orderType := range symbol.OrderTypes {
+			symbolInfo.OrderTypes = append(symbolInfo.OrderTypes,
+				OrderType(orderType))
+		}
 		for _, filter := range symbol.Filters {
 			switch filter.FilterType {
 			case "PRICE_FILTER":
 				symbolInfo.TickSize = filter.TickSize
+				symbolInfo.MinPrice = filter.MinPrice
+				symbolInfo.MaxPrice = filter.MaxPrice
 			case "MIN_NOTIONAL":
 				symbolInfo.MinNotional = filter.MinNotional
+				symbolInfo.MinNotionalToMarket = filter.ApplyToMarket
+			case "NOTIONAL":
+				symbolInfo.MinNotional = filter.MinNotional
+				symbolInfo.MaxNotional = filter.MaxNotional
+				symbolInfo.MinNotionalToMarket = filter.ApplyMinToMarket
 			case "LOT_SIZE":
 				symbolInfo.StepSize = filter.StepSize
+				symbolInfo.MinQty = filter.MinQty
+				symbolInfo.MaxQty = filter.MaxQty
+			case "MARKET_LOT_SIZE":
+				symbolInfo.MarketStepSize = filter.StepSize
+				symbolInfo.MarketMinQty = filter.MinQty
+				symbolInfo.MarketMaxQty = filter.MaxQty
+			case "PERCENT_PRICE":
+				symbolInfo.MultiplierUp = filter.MultiplierUp
+				symbolInfo.MultiplierDown = filter.MultiplierDown
+				symbolInfo.AvgPriceMins = filter.AvgPriceMins
+			case "MAX_NUM_ORDERS":
+				symbolInfo.MaxNumOrders = filter.MaxNumOrders
+			case "MAX_NUM_ALGO_ORDERS":
+				symbolInfo.MaxNumAlgoOrders = filter.MaxNumAlgoOrders
+			case "ICEBERG_PARTS":
+				symbolInfo.IcebergParts = filter.Limit
 			}
 		}
 		s.Symbols[symbol.Symbol] = symbolInfo
@@ -98,4 +169,34 @@ func (s *ExchangeInfoService) GetStepSize(symbol string) (float64, error) {
 		return 0, fmt.Errorf("symbol not found")
 	}
 	return symbolInfo.StepSize, nil
-}
\ No newline at end of file
+}
+
+// ValidateOrderType returns an error if the order type, or an iceberg order,
+// is not allowed for the symbol.
+func (s *ExchangeInfoService) ValidateOrderType(symbol string, orderType OrderType, iceberg bool) error {
+	symbolInfo, ok := s.Symbols[symbol]
+	if !ok {
+		return fmt.Errorf("symbol not found")
+	}
+	if iceberg && !symbolInfo.IcebergAllowed {
+		return fmt.Errorf("iceberg orders not allowed for %s", symbol)
+	}
+	for _, allowed := range symbolInfo.OrderTypes {
+		if allowed == orderType {
+			return nil
+		}
+	}
+	return fmt.Errorf("order type %s not allowed for %s", orderType, symbol)
+}
+
+// ValidateOco returns an error if OCO orders are not allowed for the symbol.
+func (s *ExchangeInfoService) ValidateOco(symbol string) error {
+	symbolInfo, ok := s.Symbols[symbol]
+	if !ok {
+		return fmt.Errorf("symbol not found")
+	}
+	if !symbolInfo.OcoAllowed {
+		return fmt.Errorf("oco orders not allowed for %s", symbol)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var KlineCSVHeader = []string{
+	"open_time",
+	"open",
+	"high",
+	"low",
+	"close",
+	"volume",
+	"close_time",
+	"quote_volume",
+	"trades",
+	"taker_buy_base_volume",
+	"taker_buy_quote_volume",
+}
+
+// KlineBackfill downloads the klines of a symbol into a CSV file, one page
+// at a time. If the file already has klines the backfill resumes after the
+// last one, so an interrupted backfill can be run again.
+type KlineBackfill struct {
+	Client   *RestClient
+	Symbol   string
+	Interval string
+
+	// The range to download. A zero Start begins at the first kline of the
+	// symbol, and a zero End at the last closed kline.
+	Start time.Time
+	End   time.Time
+
+	Filename string
+
+	// Called, if set, after each page is written with the number of klines
+	// written so far and the last one written.
+	Progress func(count int, last Kline)
+}
+
+// Run downloads and appends klines until the end of the range, returning the
+// number of klines written.
+func (b *KlineBackfill) Run() (int, error) {
+	start := b.Start
+	if start.IsZero() {
+		start = time.Unix(0, 0)
+	}
+
+	last, err := lastKlineOpenTime(b.Filename)
+	if err != nil {
+		return 0, err
+	}
+	if last >= 0 {
+		start = time.Unix(0, (last+1)*int64(time.Millisecond))
+	}
+
+	file, err := os.OpenFile(b.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	writer := csv.NewWriter(file)
+	if info.Size() == 0 {
+		writer.Write(KlineCSVHeader)
+	}
+
+	count := 0
+	for {
+		klines, err := b.Client.GetKlines(b.Symbol, b.Interval, start, b.End,
+			MaxKlinesLimit)
+		if err != nil {
+			writer.Flush()
+			return count, err
+		}
+
+		now := time.Now()
+		written := 0
+		for _, kline := range klines {
+			// Stop at the kline still in progress, as it would be resumed
+			// after and never completed.
+			if kline.CloseTime().After(now) {
+				break
+			}
+			writer.Write(klineToCSV(kline))
+			written++
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return count, err
+		}
+		count += written
+
+		if written > 0 && b.Progress != nil {
+			b.Progress(count, klines[written-1])
+		}
+		if written < len(klines) || len(klines) < MaxKlinesLimit {
+			return count, nil
+		}
+		start = time.Unix(0, (klines[len(klines)-1].OpenTimeMillis+1)*int64(time.Millisecond))
+	}
+}
+
+func klineToCSV(kline Kline) []string {
+	return []string{
+		strconv.FormatInt(kline.OpenTimeMillis, 10),
+		formatDecimal(kline.Open),
+		formatDecimal(kline.High),
+		formatDecimal(kline.Low),
+		formatDecimal(kline.Close),
+		formatDecimal(kline.Volume),
+		strconv.FormatInt(kline.CloseTimeMillis, 10),
+		formatDecimal(kline.QuoteVolume),
+		strconv.FormatInt(kline.NumberOfTrades, 10),
+		formatDecimal(kline.TakerBuyBaseVolume),
+		formatDecimal(kline.TakerBuyQuoteVolume),
+	}
+}
+
+// lastKlineOpenTime returns the open time of the last kline in a backfill
+// file, or -1 if the file doesn't exist or has no klines. Only the end of the
+// file is read. A last line cut short by an interrupted write is truncated
+// so the kline is downloaded again.
+func lastKlineOpenTime(filename string) (int64, error) {
+	file, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return -1, nil
+	} else if err != nil {
+		return -1, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return -1, err
+	}
+	const tailSize = 4096
+	offset := info.Size() - tailSize
+	if offset < 0 {
+		offset = 0
+	}
+	tail := make([]byte, info.Size()-offset)
+	if _, err := file.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return -1, err
+	}
+
+	// Every line written ends with a newline, so anything after the last
+	// one is an incomplete line.
+	if len(tail) > 0 && tail[len(tail)-1] != '\n' {
+		end := bytes.LastIndexByte(tail, '\n') + 1
+		if end == 0 && offset > 0 {
+			return -1, fmt.Errorf("%s: no complete line in last %d bytes",
+				filename, tailSize)
+		}
+		if err := file.Truncate(offset + int64(end)); err != nil {
+			return -1, err
+		}
+		tail = tail[:end]
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	line := lines[len(lines)-1]
+	if len(line) == 0 || bytes.HasPrefix(line, []byte(KlineCSVHeader[0])) {
+		return -1, nil
+	}
+	fields := bytes.Split(line, []byte(","))
+	if len(fields) != len(KlineCSVHeader) {
+		return -1, fmt.Errorf("%s: invalid last line: %s", filename, line)
+	}
+	openTime, err := strconv.ParseInt(string(fields[0]), 10, 64)
+	if err != nil {
+		return -1, fmt.Errorf("%s: invalid last line: %s", filename, line)
+	}
+	return openTime, nil
+}
@@ -0,0 +1,121 @@
+package binance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetKlines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("interval") != "1h" || r.URL.Query().Get("startTime") != "1499040000000" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[[1499040000000,"0.01634790","0.80000000","0.01575800","0.01577100","148976.11427815",1499644799999,"2434.19055334",308,"1756.87402397","28.46694368","17928899.62484339"]]`))
+	}))
+	defer server.Close()
+
+	client := NewAnonymousClient(WithBaseURL(server.URL))
+	klines, err := client.GetKlines("ETHBTC", "1h", time.Unix(1499040000, 0), time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(klines) != 1 {
+		t.Fatalf("expected 1 kline, got %d", len(klines))
+	}
+	kline := klines[0]
+	if kline.High != 0.8 || kline.NumberOfTrades != 308 || kline.CloseTimeMillis != 1499644799999 {
+		t.Fatalf("unexpected kline: %+v", kline)
+	}
+}
+
+// A server with 2500 one minute klines from the epoch.
+func newKlineServer() *httptest.Server {
+	const total = 2500
+	minute := int64(time.Minute / time.Millisecond)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime, _ := strconv.ParseInt(r.URL.Query().Get("startTime"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		endTime := int64(total * minute)
+		if value := r.URL.Query().Get("endTime"); value != "" {
+			endTime, _ = strconv.ParseInt(value, 10, 64)
+		}
+		klines := [][]interface{}{}
+		first := (startTime + minute - 1) / minute
+		for i := first; i < total && i*minute <= endTime && len(klines) < limit; i++ {
+			klines = append(klines, []interface{}{
+				i * minute, "1", "2", "0.5", "1.5", "10", (i+1)*minute - 1,
+				"15", 3, "5", "7.5", "0",
+			})
+		}
+		json.NewEncoder(w).Encode(klines)
+	}))
+}
+
+func TestKlineBackfillResume(t *testing.T) {
+	server := newKlineServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "klines")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "ETHBTC-1m.csv")
+
+	backfill := KlineBackfill{
+		Client:   NewAnonymousClient(WithBaseURL(server.URL)),
+		Symbol:   "ETHBTC",
+		Interval: "1m",
+		End:      time.Unix(0, 0).Add(1199 * time.Minute),
+		Filename: filename,
+	}
+	count, err := backfill.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1200 {
+		t.Fatalf("expected 1200 klines, got %d", count)
+	}
+
+	// Leave a partial line as if interrupted mid write.
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("72000000,0.1,")
+	file.Close()
+
+	backfill.End = time.Time{}
+	count, err = backfill.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1300 {
+		t.Fatalf("expected 1300 more klines, got %d", count)
+	}
+
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	if len(lines) != 2501 {
+		t.Fatalf("expected a header and 2500 klines, got %d lines", len(lines))
+	}
+	if !strings.HasPrefix(lines[1200], "71940000,") || !strings.HasPrefix(lines[1201], "72000000,") {
+		t.Fatalf("unexpected lines around resume: %s %s", lines[1200], lines[1201])
+	}
+	for _, line := range lines {
+		if fields := strings.Split(line, ","); len(fields) != len(KlineCSVHeader) {
+			t.Fatalf("incomplete line: %s", line)
+		}
+	}
+}
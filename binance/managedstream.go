@@ -0,0 +1,447 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Binance disconnects websocket connections after 24 hours, so the managed
+// stream reconnects on its own terms a little before that.
+const DefaultMaxConnectionAge = 23*time.Hour + 50*time.Minute
+
+const (
+	DefaultStreamReadTimeout  = time.Minute
+	DefaultStreamPingInterval = 20 * time.Second
+	DefaultMinReconnectDelay  = time.Second
+	DefaultMaxReconnectDelay  = time.Minute
+)
+
+// ErrConnectionExpired is the cause of a disconnect made by the managed
+// stream because the connection reached its maximum age.
+var ErrConnectionExpired = errors.New("connection reached maximum age")
+
+type StreamEventType int
+
+const (
+	// A message was received.
+	StreamEventMessage StreamEventType = iota
+
+	// The stream is connected and the stream set is restored.
+	StreamEventConnected
+
+	// The connection was lost or could not be made; Err holds the cause.
+	// Messages may have been missed until the next StreamEventConnected.
+	StreamEventDisconnected
+
+	// The stream was closed with Close. No more events will be sent.
+	StreamEventClosed
+)
+
+func (t StreamEventType) String() string {
+	switch t {
+	case StreamEventMessage:
+		return "message"
+	case StreamEventConnected:
+		return "connected"
+	case StreamEventDisconnected:
+		return "disconnected"
+	case StreamEventClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("StreamEventType(%d)", int(t))
+}
+
+type ManagedStreamEvent struct {
+	Type    StreamEventType
+	Message []byte
+	Err     error
+}
+
+// ManagedStreamOption configures optional settings of a ManagedStream.
+type ManagedStreamOption func(*ManagedStream)
+
+// WithStreamClientOptions sets the options of the StreamClient created for
+// each connection.
+func WithStreamClientOptions(opts ...StreamClientOption) ManagedStreamOption {
+	return func(s *ManagedStream) {
+		s.clientOpts = opts
+	}
+}
+
+// WithReadTimeout sets how long the connection may go without receiving
+// anything, including pongs, before it is considered stale and replaced.
+func WithReadTimeout(timeout time.Duration) ManagedStreamOption {
+	return func(s *ManagedStream) {
+		s.readTimeout = timeout
+	}
+}
+
+// WithPingInterval sets how often a ping is sent to keep the connection
+// alive and detect a stale connection. It should be less than the read
+// timeout.
+func WithPingInterval(interval time.Duration) ManagedStreamOption {
+	return func(s *ManagedStream) {
+		s.pingInterval = interval
+	}
+}
+
+// WithReconnectDelay sets the delay before the first reconnect attempt, and
+// the maximum delay it is doubled up to on consecutive failures.
+func WithReconnectDelay(min time.Duration, max time.Duration) ManagedStreamOption {
+	return func(s *ManagedStream) {
+		s.minReconnectDelay = min
+		s.maxReconnectDelay = max
+	}
+}
+
+// WithMaxConnectionAge sets the age after which a connection is replaced.
+func WithMaxConnectionAge(age time.Duration) ManagedStreamOption {
+	return func(s *ManagedStream) {
+		s.maxConnectionAge = age
+	}
+}
+
+// ManagedStream is a stream connection that reconnects when the connection
+// is lost, goes stale or is about to be dropped by Binance, restoring the
+// same set of streams.
+type ManagedStream struct {
+	clientOpts        []StreamClientOption
+	readTimeout       time.Duration
+	pingInterval      time.Duration
+	minReconnectDelay time.Duration
+	maxReconnectDelay time.Duration
+	maxConnectionAge  time.Duration
+
+	single bool
+
+	lock    sync.Mutex
+	streams []string
+	client  *StreamClient
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewManagedStream creates a managed stream for the combined stream
+// endpoint. It does not connect until Run is called.
+func NewManagedStream(streams []string, opts ...ManagedStreamOption) *ManagedStream {
+	s := &ManagedStream{
+		readTimeout:       DefaultStreamReadTimeout,
+		pingInterval:      DefaultStreamPingInterval,
+		minReconnectDelay: DefaultMinReconnectDelay,
+		maxReconnectDelay: DefaultMaxReconnectDelay,
+		maxConnectionAge:  DefaultMaxConnectionAge,
+		streams:           append([]string{}, streams...),
+		closed:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewManagedSingleStream creates a managed stream for the single (raw)
+// stream endpoint.
+func NewManagedSingleStream(stream string, opts ...ManagedStreamOption) *ManagedStream {
+	s := NewManagedStream([]string{stream}, opts...)
+	s.single = true
+	return s
+}
+
+// Streams returns the names of the streams that are restored on reconnect.
+func (s *ManagedStream) Streams() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string{}, s.streams...)
+}
+
+// Close closes the stream. Run will send a StreamEventClosed event, if the
+// channel is ready to receive it, and return.
+func (s *ManagedStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.lock.Lock()
+		if s.client != nil {
+			s.client.Close()
+		}
+		s.lock.Unlock()
+	})
+}
+
+func (s *ManagedStream) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run connects and sends events to channel until Close is called, so is
+// usually run in its own goroutine.
+func (s *ManagedStream) Run(channel chan<- ManagedStreamEvent) {
+	delay := s.minReconnectDelay
+	for {
+		client, err := s.connect()
+		if err == nil {
+			delay = s.minReconnectDelay
+			if !s.send(channel, ManagedStreamEvent{Type: StreamEventConnected}) {
+				break
+			}
+			err = s.readLoop(client, channel)
+			client.Close()
+			s.lock.Lock()
+			s.client = nil
+			s.lock.Unlock()
+		}
+		if s.isClosed() {
+			break
+		}
+		if !s.send(channel, ManagedStreamEvent{
+			Type: StreamEventDisconnected,
+			Err:  err,
+		}) {
+			break
+		}
+
+		select {
+		case <-s.closed:
+		case <-time.After(delay):
+		}
+		if s.isClosed() {
+			break
+		}
+		delay *= 2
+		if delay > s.maxReconnectDelay {
+			delay = s.maxReconnectDelay
+		}
+	}
+
+	select {
+	case channel <- ManagedStreamEvent{Type: StreamEventClosed}:
+	default:
+	}
+}
+
+// send sends an event unless the stream is closed first.
+func (s *ManagedStream) send(channel chan<- ManagedStreamEvent, event ManagedStreamEvent) bool {
+	select {
+	case <-s.closed:
+		return false
+	case channel <- event:
+		return true
+	}
+}
+
+func (s *ManagedStream) connect() (*StreamClient, error) {
+	streams := s.Streams()
+	if s.single && len(streams) == 0 {
+		return nil, fmt.Errorf("no streams")
+	}
+
+	client := NewStreamClient(s.clientOpts...)
+	var err error
+	if s.single {
+		err = client.ConnectSingle(streams[0])
+	} else {
+		err = client.Connect(streams...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.isClosed() {
+		client.Close()
+		return nil, fmt.Errorf("stream closed")
+	}
+	s.client = client
+
+	// The single stream endpoint takes only one stream in the URL, so the
+	// rest are subscribed to once the read loop is running to receive the
+	// response, along with any streams added while connecting. On failure
+	// the connection is dropped to try again.
+	var restore []string
+	if s.single {
+		restore = append(restore, streams[1:]...)
+	}
+	for _, stream := range s.streams {
+		if !containsString(streams, stream) {
+			restore = append(restore, stream)
+		}
+	}
+	if len(restore) > 0 {
+		go func() {
+			if err := client.Subscribe(restore...); err != nil {
+				client.Close()
+			}
+		}()
+	}
+
+	return client, nil
+}
+
+// Subscribe adds streams to the live connection, if any, and to the set
+// restored on reconnect.
+func (s *ManagedStream) Subscribe(streams ...string) error {
+	s.lock.Lock()
+	client := s.client
+	for _, stream := range streams {
+		if !containsString(s.streams, stream) {
+			s.streams = append(s.streams, stream)
+		}
+	}
+	s.lock.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	if err := client.Subscribe(streams...); err != nil {
+		if _, ok := err.(*StreamMethodError); ok {
+			// Rejected by the server, so don't restore them either.
+			s.removeStreams(streams)
+		}
+		return err
+	}
+	return nil
+}
+
+// Unsubscribe removes streams from the live connection, if any, and from
+// the set restored on reconnect.
+func (s *ManagedStream) Unsubscribe(streams ...string) error {
+	s.removeStreams(streams)
+	s.lock.Lock()
+	client := s.client
+	s.lock.Unlock()
+	if client == nil {
+		return nil
+	}
+	return client.Unsubscribe(streams...)
+}
+
+// ListSubscriptions asks the server for the streams of the live connection.
+func (s *ManagedStream) ListSubscriptions() ([]string, error) {
+	s.lock.Lock()
+	client := s.client
+	s.lock.Unlock()
+	if client == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	return client.ListSubscriptions()
+}
+
+func (s *ManagedStream) removeStreams(streams []string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	remaining := []string{}
+	for _, stream := range s.streams {
+		if !containsString(streams, stream) {
+			remaining = append(remaining, stream)
+		}
+	}
+	s.streams = remaining
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ManagedStream) readLoop(client *StreamClient, channel chan<- ManagedStreamEvent) error {
+	conn := client.Conn
+	extendDeadline := func() {
+		conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+	}
+	extendDeadline()
+	conn.SetPongHandler(func(string) error {
+		extendDeadline()
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		extendDeadline()
+		err := conn.WriteControl(websocket.PongMessage, []byte(data),
+			time.Now().Add(s.readTimeout))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	expired := make(chan struct{})
+	go func() {
+		ping := time.NewTicker(s.pingInterval)
+		defer ping.Stop()
+		age := time.NewTimer(s.maxConnectionAge)
+		defer age.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-age.C:
+				close(expired)
+				conn.Close()
+				return
+			case <-ping.C:
+				conn.WriteControl(websocket.PingMessage, nil,
+					time.Now().Add(s.readTimeout))
+			}
+		}
+	}()
+
+	for {
+		_, message, err := client.Next()
+		if err != nil {
+			select {
+			case <-expired:
+				return ErrConnectionExpired
+			default:
+				return err
+			}
+		}
+		// Nothing is read while waiting on a slow consumer, so the deadline
+		// is cleared until the message is delivered.
+		conn.SetReadDeadline(time.Time{})
+		if !s.send(channel, ManagedStreamEvent{
+			Type:    StreamEventMessage,
+			Message: message,
+		}) {
+			return nil
+		}
+		extendDeadline()
+	}
+}
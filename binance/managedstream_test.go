@@ -0,0 +1,281 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestManagedStreamReconnects(t *testing.T) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("streams") != "ethbtc@trade/bnbbtc@trade" {
+			t.Errorf("unexpected streams: %s", r.URL.RawQuery)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&connections, 1)
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"n":1}`))
+		if n == 1 {
+			// Drop the first connection after one message.
+			return
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	stream := NewManagedStream([]string{"ethbtc@trade", "bnbbtc@trade"},
+		WithStreamClientOptions(WithStreamURL(
+			"ws"+strings.TrimPrefix(server.URL, "http"))),
+		WithReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	events := make(chan ManagedStreamEvent, 1)
+	go stream.Run(events)
+
+	expected := []StreamEventType{
+		StreamEventConnected,
+		StreamEventMessage,
+		StreamEventDisconnected,
+		StreamEventConnected,
+		StreamEventMessage,
+	}
+	for _, eventType := range expected {
+		select {
+		case event := <-events:
+			if event.Type != eventType {
+				t.Fatalf("expected %s event, got %s (%v)", eventType,
+					event.Type, event.Err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %s event", eventType)
+		}
+	}
+
+	stream.Close()
+	select {
+	case event := <-events:
+		if event.Type != StreamEventClosed {
+			t.Fatalf("expected closed event, got %s", event.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for closed event")
+	}
+}
+
+// serveStream starts a websocket server that calls handler with each
+// connection, returning the server and its stream URL.
+func serveStream(t *testing.T, handler func(n int32, conn *websocket.Conn)) (*httptest.Server, string) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(atomic.AddInt32(&connections, 1), conn)
+	}))
+	return server, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func expectStreamEvent(t *testing.T, events chan ManagedStreamEvent, eventType StreamEventType) ManagedStreamEvent {
+	select {
+	case event := <-events:
+		if event.Type != eventType {
+			t.Fatalf("expected %s event, got %s (%v)", eventType,
+				event.Type, event.Err)
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for %s event", eventType)
+	}
+	return ManagedStreamEvent{}
+}
+
+func readUntilClosed(conn *websocket.Conn) {
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+func TestManagedStreamReconnectsWhenStale(t *testing.T) {
+	server, url := serveStream(t, func(n int32, conn *websocket.Conn) {
+		if n == 1 {
+			// Send nothing and don't read, so pings go unanswered.
+			time.Sleep(time.Second)
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"n":2}`))
+		readUntilClosed(conn)
+	})
+	defer server.Close()
+
+	stream := NewManagedStream([]string{"ethbtc@trade"},
+		WithStreamClientOptions(WithStreamURL(url)),
+		WithReadTimeout(50*time.Millisecond),
+		WithPingInterval(10*time.Millisecond),
+		WithReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	events := make(chan ManagedStreamEvent, 1)
+	go stream.Run(events)
+	defer stream.Close()
+
+	expectStreamEvent(t, events, StreamEventConnected)
+	event := expectStreamEvent(t, events, StreamEventDisconnected)
+	if netErr, ok := event.Err.(interface{ Timeout() bool }); !ok || !netErr.Timeout() {
+		t.Fatalf("expected a timeout, got %v", event.Err)
+	}
+	expectStreamEvent(t, events, StreamEventConnected)
+	expectStreamEvent(t, events, StreamEventMessage)
+}
+
+func TestManagedStreamReplacesAtMaxAge(t *testing.T) {
+	server, url := serveStream(t, func(n int32, conn *websocket.Conn) {
+		readUntilClosed(conn)
+	})
+	defer server.Close()
+
+	stream := NewManagedStream([]string{"ethbtc@trade"},
+		WithStreamClientOptions(WithStreamURL(url)),
+		WithMaxConnectionAge(50*time.Millisecond),
+		WithReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	events := make(chan ManagedStreamEvent, 1)
+	go stream.Run(events)
+	defer stream.Close()
+
+	expectStreamEvent(t, events, StreamEventConnected)
+	event := expectStreamEvent(t, events, StreamEventDisconnected)
+	if event.Err != ErrConnectionExpired {
+		t.Fatalf("expected ErrConnectionExpired, got %v", event.Err)
+	}
+	expectStreamEvent(t, events, StreamEventConnected)
+}
+
+func TestManagedStreamSlowConsumer(t *testing.T) {
+	server, url := serveStream(t, func(n int32, conn *websocket.Conn) {
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"n":1}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"n":2}`))
+		readUntilClosed(conn)
+	})
+	defer server.Close()
+
+	stream := NewManagedStream([]string{"ethbtc@trade"},
+		WithStreamClientOptions(WithStreamURL(url)),
+		WithReadTimeout(50*time.Millisecond),
+		WithPingInterval(10*time.Millisecond))
+	events := make(chan ManagedStreamEvent)
+	go stream.Run(events)
+	defer stream.Close()
+
+	expectStreamEvent(t, events, StreamEventConnected)
+	// Hold up the first message for longer than the read timeout.
+	time.Sleep(200 * time.Millisecond)
+	expectStreamEvent(t, events, StreamEventMessage)
+	expectStreamEvent(t, events, StreamEventMessage)
+}
+
+func TestAggTradeStreamReconnects(t *testing.T) {
+	server, url := serveStream(t, func(n int32, conn *websocket.Conn) {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+			`{"e":"aggTrade","s":"ETHBTC","a":%d,"p":"0.03","q":"1"}`, n)))
+		if n == 1 {
+			return
+		}
+		readUntilClosed(conn)
+	})
+	defer server.Close()
+
+	stream := NewAggTradeStream("ETHBTC",
+		WithStreamClientOptions(WithStreamURL(url)),
+		WithReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	events := make(chan AggTradeStreamEvent, 1)
+	go stream.SubscribeWithConnectionState(events)
+
+	expected := []struct {
+		eventType StreamEventType
+		tradeID   int64
+	}{
+		{StreamEventConnected, 0},
+		{StreamEventMessage, 1},
+		{StreamEventDisconnected, 0},
+		{StreamEventConnected, 0},
+		{StreamEventMessage, 2},
+	}
+	for _, e := range expected {
+		select {
+		case event := <-events:
+			if event.Type != e.eventType {
+				t.Fatalf("expected %s event, got %s (%v)", e.eventType,
+					event.Type, event.Err)
+			}
+			if e.tradeID > 0 && (event.Trade == nil || event.Trade.TradeID != e.tradeID) {
+				t.Fatalf("expected trade %d, got %+v", e.tradeID, event.Trade)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for %s event", e.eventType)
+		}
+	}
+
+	stream.Close()
+	if _, err := stream.Next(); err != ErrStreamClosed {
+		t.Fatalf("expected ErrStreamClosed, got %v", err)
+	}
+}
+
+func TestAggTradeStreamSubscribe(t *testing.T) {
+	server, url := serveStream(t, func(n int32, conn *websocket.Conn) {
+		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(
+			`{"e":"aggTrade","s":"ETHBTC","a":%d,"p":"0.03","q":"1"}`, n)))
+		if n == 1 {
+			return
+		}
+		readUntilClosed(conn)
+	})
+	defer server.Close()
+
+	stream := NewAggTradeStream("ETHBTC",
+		WithStreamClientOptions(WithStreamURL(url)),
+		WithReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	events := make(chan AggTradeStreamEvent, 1)
+	go stream.Subscribe(events)
+
+	// Without connection state every event but the last has a trade or an
+	// error.
+	next := func() AggTradeStreamEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+		return AggTradeStreamEvent{}
+	}
+	if event := next(); event.Trade == nil || event.Trade.TradeID != 1 {
+		t.Fatalf("expected trade 1, got %+v", event)
+	}
+	if event := next(); event.Type != StreamEventDisconnected || event.Err == nil {
+		t.Fatalf("expected a disconnect error, got %+v", event)
+	}
+	if event := next(); event.Trade == nil || event.Trade.TradeID != 2 {
+		t.Fatalf("expected trade 2, got %+v", event)
+	}
+
+	stream.Close()
+	if event := next(); event.Type != StreamEventClosed || event.Trade != nil || event.Err != nil {
+		t.Fatalf("expected the stream to be closed, got %+v", event)
+	}
+}
@@ -0,0 +1,373 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The number of levels requested for the depth snapshot.
+const DefaultOrderBookSnapshotLimit = 1000
+
+// Diff events buffered while waiting for a snapshot beyond this number are
+// dropped, oldest first.
+const maxOrderBookBuffer = 10000
+
+var (
+	// A diff event did not follow on from the previous one. The book needs
+	// a new snapshot.
+	ErrOrderBookGap = errors.New("gap in order book updates")
+
+	ErrOrderBookNotSynced = errors.New("order book not synced")
+	ErrInsufficientDepth  = errors.New("insufficient order book depth")
+)
+
+// OrderBook is a local copy of the order book of a symbol, built from a
+// depth snapshot and kept up to date with the <symbol>@depth diff stream.
+type OrderBook struct {
+	client        *RestClient
+	symbol        string
+	snapshotLimit int
+
+	// Bids are kept sorted highest first, asks lowest first.
+	lock         sync.RWMutex
+	bids         []DepthEntry
+	asks         []DepthEntry
+	lastUpdateID int64
+	synced       bool
+
+	// Set after a snapshot until the first diff event is applied, which
+	// may overlap the snapshot.
+	first bool
+
+	// Diff events received while not synced.
+	buffer []StreamDepthUpdate
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+func NewOrderBook(client *RestClient, symbol string) *OrderBook {
+	return &OrderBook{
+		client:        client,
+		symbol:        strings.ToUpper(symbol),
+		snapshotLimit: DefaultOrderBookSnapshotLimit,
+		closed:        make(chan struct{}),
+	}
+}
+
+// SetSnapshotLimit sets the number of levels requested for the snapshot.
+func (b *OrderBook) SetSnapshotLimit(limit int) {
+	b.snapshotLimit = limit
+}
+
+func (b *OrderBook) Symbol() string {
+	return b.symbol
+}
+
+// The name of the diff stream that should be fed to Update.
+func (b *OrderBook) StreamName() string {
+	return fmt.Sprintf("%s@depth@100ms", strings.ToLower(b.symbol))
+}
+
+// Synced returns true if the book has a snapshot and all diff events since.
+func (b *OrderBook) Synced() bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.synced
+}
+
+// LastUpdateID returns the ID of the last update applied to the book.
+func (b *OrderBook) LastUpdateID() int64 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.lastUpdateID
+}
+
+// Reset marks the book as not synced, for example after the diff stream was
+// disconnected. Updates are buffered until the next Sync.
+func (b *OrderBook) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.synced = false
+	b.buffer = nil
+}
+
+// Sync fetches a snapshot and applies the diff events buffered since the
+// book was last synced. The diff stream must already be feeding Update so
+// no events are missed while the snapshot is fetched.
+func (b *OrderBook) Sync() error {
+	snapshot, err := b.client.GetDepth(b.symbol, b.snapshotLimit)
+	if err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.bids = nil
+	b.asks = nil
+	for _, entry := range snapshot.Bids {
+		b.bids = setLevel(b.bids, entry, true)
+	}
+	for _, entry := range snapshot.Asks {
+		b.asks = setLevel(b.asks, entry, false)
+	}
+	b.lastUpdateID = snapshot.LastUpdateID
+	b.synced = true
+	b.first = true
+
+	buffer := b.buffer
+	b.buffer = nil
+	for i := range buffer {
+		if err := b.apply(&buffer[i]); err != nil {
+			// The snapshot is older than the buffered events. Keep the
+			// events from the gap on for the next snapshot.
+			b.synced = false
+			b.buffer = buffer[i:]
+			return err
+		}
+	}
+	return nil
+}
+
+// Update applies a diff event, or buffers it if the book is not synced. If
+// the event does not follow on from the last one ErrOrderBookGap is
+// returned and the book is no longer synced until the next Sync.
+func (b *OrderBook) Update(update *StreamDepthUpdate) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !b.synced {
+		b.bufferUpdate(update)
+		return nil
+	}
+	if err := b.apply(update); err != nil {
+		b.synced = false
+		b.buffer = nil
+		b.bufferUpdate(update)
+		return err
+	}
+	return nil
+}
+
+func (b *OrderBook) bufferUpdate(update *StreamDepthUpdate) {
+	if len(b.buffer) >= maxOrderBookBuffer {
+		b.buffer = b.buffer[1:]
+	}
+	b.buffer = append(b.buffer, *update)
+}
+
+// apply an update following the sequencing rules of the diff stream. Must
+// be called with the lock held.
+func (b *OrderBook) apply(update *StreamDepthUpdate) error {
+	// Already part of the snapshot.
+	if update.FinalUpdateID <= b.lastUpdateID {
+		return nil
+	}
+
+	if b.first {
+		// The first event must contain the update following the snapshot.
+		if update.FirstUpdateID > b.lastUpdateID+1 {
+			return ErrOrderBookGap
+		}
+	} else if update.FirstUpdateID != b.lastUpdateID+1 {
+		return ErrOrderBookGap
+	}
+
+	for _, entry := range update.Bids {
+		b.bids = setLevel(b.bids, entry, true)
+	}
+	for _, entry := range update.Asks {
+		b.asks = setLevel(b.asks, entry, false)
+	}
+	b.lastUpdateID = update.FinalUpdateID
+	b.first = false
+	return nil
+}
+
+// setLevel inserts, replaces or removes a level, keeping the levels sorted
+// highest first if descending, otherwise lowest first.
+func setLevel(levels []DepthEntry, entry DepthEntry, descending bool) []DepthEntry {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price <= entry.Price
+		}
+		return levels[i].Price >= entry.Price
+	})
+	found := i < len(levels) && levels[i].Price == entry.Price
+	switch {
+	case entry.Quantity == 0:
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+	case found:
+		levels[i] = entry
+	default:
+		levels = append(levels, DepthEntry{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = entry
+	}
+	return levels
+}
+
+// BestBid returns the highest bid. False is returned if the book is not
+// synced or has no bids.
+func (b *OrderBook) BestBid() (DepthEntry, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if !b.synced || len(b.bids) == 0 {
+		return DepthEntry{}, false
+	}
+	return b.bids[0], true
+}
+
+// BestAsk returns the lowest ask. False is returned if the book is not
+// synced or has no asks.
+func (b *OrderBook) BestAsk() (DepthEntry, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if !b.synced || len(b.asks) == 0 {
+		return DepthEntry{}, false
+	}
+	return b.asks[0], true
+}
+
+// Depth returns up to levels bids, highest first, and asks, lowest first. If
+// levels is 0 all levels are returned. Nothing is returned if the book is
+// not synced.
+func (b *OrderBook) Depth(levels int) (bids []DepthEntry, asks []DepthEntry) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if !b.synced {
+		return nil, nil
+	}
+	return topLevels(b.bids, levels), topLevels(b.asks, levels)
+}
+
+// topLevels returns a copy of up to limit levels, or all if limit is 0.
+func topLevels(levels []DepthEntry, limit int) []DepthEntry {
+	if limit > 0 && len(levels) > limit {
+		levels = levels[:limit]
+	}
+	return append([]DepthEntry{}, levels...)
+}
+
+// VWAP returns the volume weighted average price of filling quantity
+// against the book: the asks for a buy, the bids for a sell.
+func (b *OrderBook) VWAP(side OrderSide, quantity float64) (float64, error) {
+	if quantity <= 0 {
+		return 0, fmt.Errorf("quantity must be greater than 0")
+	}
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if !b.synced {
+		return 0, ErrOrderBookNotSynced
+	}
+	levels := b.asks
+	if side == OrderSideSell {
+		levels = b.bids
+	}
+
+	remaining := quantity
+	cost := 0.0
+	for _, level := range levels {
+		fill := level.Quantity
+		if fill > remaining {
+			fill = remaining
+		}
+		cost += fill * level.Price
+		remaining -= fill
+		if remaining <= 0 {
+			return cost / quantity, nil
+		}
+	}
+	return 0, ErrInsufficientDepth
+}
+
+// Run maintains the book from its own managed diff stream until Close is
+// called. The book is resynced whenever the stream reconnects or a gap is
+// detected.
+func (b *OrderBook) Run(opts ...ManagedStreamOption) {
+	stream := NewManagedSingleStream(b.StreamName(), opts...)
+	events := make(chan ManagedStreamEvent)
+	go stream.Run(events)
+	defer stream.Close()
+
+	syncing := false
+	syncDone := make(chan error, 1)
+	resync := func(delay time.Duration) {
+		syncing = true
+		go func() {
+			time.Sleep(delay)
+			syncDone <- b.Sync()
+		}()
+	}
+
+	for {
+		select {
+		case <-b.closed:
+			return
+		case err := <-syncDone:
+			syncing = false
+			if err != nil {
+				// Retry after a short wait, as more events are buffered.
+				resync(time.Second)
+			}
+		case event := <-events:
+			switch event.Type {
+			case StreamEventConnected:
+				b.Reset()
+				if !syncing {
+					resync(0)
+				}
+			case StreamEventDisconnected:
+				b.Reset()
+			case StreamEventMessage:
+				message, err := DecodeSingleStreamMessage(b.StreamName(),
+					event.Message)
+				if err != nil || message.DepthUpdate == nil {
+					continue
+				}
+				err = b.Update(message.DepthUpdate)
+				if err == ErrOrderBookGap && !syncing {
+					resync(0)
+				}
+			}
+		}
+	}
+}
+
+// Close stops Run.
+func (b *OrderBook) Close() {
+	b.once.Do(func() {
+		close(b.closed)
+	})
+}
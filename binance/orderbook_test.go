@@ -0,0 +1,120 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestOrderBook(t *testing.T) (*OrderBook, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/depth" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"lastUpdateId":100,"bids":[["0.0024","10"],["0.0023","5"]],"asks":[["0.0026","2"],["0.0027","8"]]}`))
+	}))
+	client := NewAnonymousClient(WithBaseURL(server.URL))
+	return NewOrderBook(client, "bnbbtc"), server.Close
+}
+
+func TestOrderBookSync(t *testing.T) {
+	book, done := newTestOrderBook(t)
+	defer done()
+
+	// Buffered before the snapshot: one already included, one spanning it.
+	book.Update(&StreamDepthUpdate{FirstUpdateID: 95, FinalUpdateID: 99})
+	book.Update(&StreamDepthUpdate{FirstUpdateID: 99, FinalUpdateID: 102,
+		Bids: []DepthEntry{{Price: 0.0024, Quantity: 0}}})
+	if book.Synced() {
+		t.Fatalf("expected book not to be synced before snapshot")
+	}
+	if err := book.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if book.LastUpdateID() != 102 {
+		t.Fatalf("expected last update ID 102, got %d", book.LastUpdateID())
+	}
+	if bid, ok := book.BestBid(); !ok || bid.Price != 0.0023 {
+		t.Fatalf("unexpected best bid: %+v", bid)
+	}
+
+	if err := book.Update(&StreamDepthUpdate{FirstUpdateID: 103, FinalUpdateID: 104,
+		Asks: []DepthEntry{{Price: 0.0025, Quantity: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if ask, ok := book.BestAsk(); !ok || ask.Price != 0.0025 {
+		t.Fatalf("unexpected best ask: %+v", ask)
+	}
+	bids, asks := book.Depth(2)
+	if len(bids) != 1 || len(asks) != 2 || asks[1].Price != 0.0026 {
+		t.Fatalf("unexpected depth: %+v %+v", bids, asks)
+	}
+
+	// Buy 3: 1 at 0.0025 and 2 at 0.0026.
+	vwap, err := book.VWAP(OrderSideBuy, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (0.0025 + 2*0.0026) / 3; vwap != expected {
+		t.Fatalf("expected VWAP %f, got %f", expected, vwap)
+	}
+	if _, err := book.VWAP(OrderSideSell, 6); err != ErrInsufficientDepth {
+		t.Fatalf("expected insufficient depth, got %v", err)
+	}
+}
+
+func TestOrderBookGap(t *testing.T) {
+	book, done := newTestOrderBook(t)
+	defer done()
+
+	// The first buffered event is newer than the snapshot.
+	book.Update(&StreamDepthUpdate{FirstUpdateID: 105, FinalUpdateID: 106})
+	if err := book.Sync(); err != ErrOrderBookGap {
+		t.Fatalf("expected gap, got %v", err)
+	}
+	if book.Synced() {
+		t.Fatalf("expected book not to be synced")
+	}
+
+	book.Reset()
+	if err := book.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Update(&StreamDepthUpdate{FirstUpdateID: 101, FinalUpdateID: 101}); err != nil {
+		t.Fatal(err)
+	}
+	if err := book.Update(&StreamDepthUpdate{FirstUpdateID: 103, FinalUpdateID: 104}); err != ErrOrderBookGap {
+		t.Fatalf("expected gap, got %v", err)
+	}
+	if _, ok := book.BestBid(); ok {
+		t.Fatalf("expected no best bid while not synced")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var bids []DepthEntry
+	for _, entry := range []DepthEntry{
+		{Price: 2, Quantity: 1},
+		{Price: 4, Quantity: 1},
+		{Price: 3, Quantity: 1},
+		{Price: 1, Quantity: 1},
+		{Price: 3, Quantity: 5},
+		{Price: 4, Quantity: 0},
+		{Price: 9, Quantity: 0},
+	} {
+		bids = setLevel(bids, entry, true)
+	}
+	expected := []DepthEntry{
+		{Price: 3, Quantity: 5},
+		{Price: 2, Quantity: 1},
+		{Price: 1, Quantity: 1},
+	}
+	if len(bids) != len(expected) {
+		t.Fatalf("unexpected bids: %+v", bids)
+	}
+	for i := range expected {
+		if bids[i] != expected[i] {
+			t.Fatalf("unexpected bids: %+v", bids)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Names of the symbol filters, as found in the exchange info response.
+const (
+	FilterPrice            = "PRICE_FILTER"
+	FilterPercentPrice     = "PERCENT_PRICE"
+	FilterLotSize          = "LOT_SIZE"
+	FilterMarketLotSize    = "MARKET_LOT_SIZE"
+	FilterMinNotional      = "MIN_NOTIONAL"
+	FilterNotional         = "NOTIONAL"
+	FilterIcebergParts     = "ICEBERG_PARTS"
+	FilterMaxNumOrders     = "MAX_NUM_ORDERS"
+	FilterMaxNumAlgoOrders = "MAX_NUM_ALGO_ORDERS"
+)
+
+// FilterError is returned when an order fails one of the symbol filters.
+type FilterError struct {
+	Symbol string
+	Filter string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Symbol, e.Filter, e.Reason)
+}
+
+func newFilterError(symbol string, filter string, format string, args ...interface{}) *FilterError {
+	return &FilterError{
+		Symbol: symbol,
+		Filter: filter,
+		Reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// NormalizeOrder rounds the prices of the order to the tick size and the
+// quantities down to the step size of the symbol.
+func (s *ExchangeInfoService) NormalizeOrder(order *OrderParameters) error {
+	info, err := s.GetSymbol(order.Symbol)
+	if err != nil {
+		return err
+	}
+	info.NormalizeOrder(order)
+	return nil
+}
+
+// ValidateOrder checks the order against the filters of the symbol. The
+// referencePrice, usually the current average price, is used for the
+// PERCENT_PRICE filter and the notional value of MARKET orders. Pass 0 if
+// it is not known to skip those checks.
+//
+// An order without a quote order quantity fails LOT_SIZE if its quantity
+// is 0, such as after NormalizeOrder rounded it down to the step size.
+func (s *ExchangeInfoService) ValidateOrder(order OrderParameters, referencePrice float64) error {
+	info, err := s.GetSymbol(order.Symbol)
+	if err != nil {
+		return err
+	}
+	return info.ValidateOrder(order, referencePrice)
+}
+
+// ValidateOpenOrderCount checks that placing another order of the type will
+// not exceed the MAX_NUM_ORDERS or MAX_NUM_ALGO_ORDERS filters, given the
+// number of orders, and algo (stop) orders, already open on the symbol.
+// PostOrder doesn't check these filters, so it is up to the caller.
+func (s *ExchangeInfoService) ValidateOpenOrderCount(symbol string, orderType OrderType, openOrders int64, openAlgoOrders int64) error {
+	info, err := s.GetSymbol(symbol)
+	if err != nil {
+		return err
+	}
+	if info.MaxNumOrders > 0 && openOrders+1 > info.MaxNumOrders {
+		return newFilterError(symbol, FilterMaxNumOrders,
+			"%d open orders, maximum is %d", openOrders, info.MaxNumOrders)
+	}
+	if orderType.IsStop() && info.MaxNumAlgoOrders > 0 &&
+		openAlgoOrders+1 > info.MaxNumAlgoOrders {
+		return newFilterError(symbol, FilterMaxNumAlgoOrders,
+			"%d open algo orders, maximum is %d", openAlgoOrders,
+			info.MaxNumAlgoOrders)
+	}
+	return nil
+}
+
+// NeedsReferencePrice returns true if validating the order uses a
+// reference price, for the PERCENT_PRICE filter or the notional value of a
+// MARKET order.
+func (info *SymbolInfo) NeedsReferencePrice(order OrderParameters) bool {
+	if order.Type.IsLimit() {
+		return info.MultiplierUp > 0 || info.MultiplierDown > 0
+	}
+	return order.Type == OrderTypeMarket && order.QuoteOrderQty <= 0 &&
+		info.MinNotionalToMarket
+}
+
+func (info *SymbolInfo) NormalizeOrder(order *OrderParameters) {
+	if order.Price > 0 {
+		order.Price = RoundToStep(order.Price, info.TickSize)
+	}
+	if order.StopPrice > 0 {
+		order.StopPrice = RoundToStep(order.StopPrice, info.TickSize)
+	}
+	if order.Quantity > 0 {
+		order.Quantity = RoundDownToStep(order.Quantity, info.StepSize)
+		if order.Type == OrderTypeMarket {
+			order.Quantity = RoundDownToStep(order.Quantity, info.MarketStepSize)
+		}
+	}
+	if order.IcebergQty > 0 {
+		order.IcebergQty = RoundDownToStep(order.IcebergQty, info.StepSize)
+	}
+}
+
+func (info *SymbolInfo) ValidateOrder(order OrderParameters, referencePrice float64) error {
+	symbol := order.Symbol
+
+	// PRICE_FILTER.
+	prices := []float64{}
+	if order.Type.IsLimit() {
+		prices = append(prices, order.Price)
+	}
+	if order.Type.IsStop() {
+		prices = append(prices, order.StopPrice)
+	}
+	for _, price := range prices {
+		if info.MinPrice > 0 && price < info.MinPrice {
+			return newFilterError(symbol, FilterPrice,
+				"price %s below minimum %s", formatDecimal(price),
+				formatDecimal(info.MinPrice))
+		}
+		if info.MaxPrice > 0 && price > info.MaxPrice {
+			return newFilterError(symbol, FilterPrice,
+				"price %s above maximum %s", formatDecimal(price),
+				formatDecimal(info.MaxPrice))
+		}
+		if !IsMultipleOfStep(price, info.TickSize) {
+			return newFilterError(symbol, FilterPrice,
+				"price %s not a multiple of tick size %s",
+				formatDecimal(price), formatDecimal(info.TickSize))
+		}
+	}
+
+	// PERCENT_PRICE.
+	if order.Type.IsLimit() && referencePrice > 0 {
+		if info.MultiplierUp > 0 && order.Price > referencePrice*info.MultiplierUp {
+			return newFilterError(symbol, FilterPercentPrice,
+				"price %s more than %s times average price %s",
+				formatDecimal(order.Price), formatDecimal(info.MultiplierUp),
+				formatDecimal(referencePrice))
+		}
+		if info.MultiplierDown > 0 && order.Price < referencePrice*info.MultiplierDown {
+			return newFilterError(symbol, FilterPercentPrice,
+				"price %s less than %s times average price %s",
+				formatDecimal(order.Price), formatDecimal(info.MultiplierDown),
+				formatDecimal(referencePrice))
+		}
+	}
+
+	// LOT_SIZE and MARKET_LOT_SIZE. Unless the order is for a quote
+	// quantity, a quantity was given that may have been rounded down to 0.
+	if order.QuoteOrderQty <= 0 {
+		if order.Quantity <= 0 {
+			return newFilterError(symbol, FilterLotSize,
+				"quantity %s must be at least step size %s",
+				formatDecimal(order.Quantity), formatDecimal(info.StepSize))
+		}
+		if err := validateLotSize(symbol, FilterLotSize, order.Quantity,
+			info.MinQty, info.MaxQty, info.StepSize); err != nil {
+			return err
+		}
+		if order.Type == OrderTypeMarket {
+			if err := validateLotSize(symbol, FilterMarketLotSize,
+				order.Quantity, info.MarketMinQty, info.MarketMaxQty,
+				info.MarketStepSize); err != nil {
+				return err
+			}
+		}
+	}
+
+	// ICEBERG_PARTS.
+	if order.IcebergQty > 0 {
+		if err := validateLotSize(symbol, FilterLotSize, order.IcebergQty,
+			info.MinQty, info.MaxQty, info.StepSize); err != nil {
+			return err
+		}
+		parts := int64(math.Ceil(order.Quantity / order.IcebergQty))
+		if info.IcebergParts > 0 && parts > info.IcebergParts {
+			return newFilterError(symbol, FilterIcebergParts,
+				"%d parts, maximum is %d", parts, info.IcebergParts)
+		}
+	}
+
+	// MIN_NOTIONAL.
+	notional := 0.0
+	if order.Type == OrderTypeMarket {
+		if order.QuoteOrderQty > 0 {
+			notional = order.QuoteOrderQty
+		} else if info.MinNotionalToMarket {
+			notional = order.Quantity * referencePrice
+		}
+	} else if order.Type.IsLimit() {
+		notional = order.Quantity * order.Price
+	} else {
+		notional = order.Quantity * order.StopPrice
+	}
+	if notional > 0 {
+		if info.MinNotional > 0 && notional < info.MinNotional {
+			return newFilterError(symbol, FilterMinNotional,
+				"notional %s below minimum %s", formatDecimal(notional),
+				formatDecimal(info.MinNotional))
+		}
+		if info.MaxNotional > 0 && notional > info.MaxNotional {
+			return newFilterError(symbol, FilterNotional,
+				"notional %s above maximum %s", formatDecimal(notional),
+				formatDecimal(info.MaxNotional))
+		}
+	}
+
+	return nil
+}
+
+func validateLotSize(symbol string, filter string, quantity float64, minQty float64, maxQty float64, stepSize float64) error {
+	if minQty > 0 && quantity < minQty {
+		return newFilterError(symbol, filter, "quantity %s below minimum %s",
+			formatDecimal(quantity), formatDecimal(minQty))
+	}
+	if maxQty > 0 && quantity > maxQty {
+		return newFilterError(symbol, filter, "quantity %s above maximum %s",
+			formatDecimal(quantity), formatDecimal(maxQty))
+	}
+	if !IsMultipleOfStep(quantity, stepSize) {
+		return newFilterError(symbol, filter,
+			"quantity %s not a multiple of step size %s",
+			formatDecimal(quantity), formatDecimal(stepSize))
+	}
+	return nil
+}
+
+// RoundToStep rounds value to the nearest multiple of step. A step of 0
+// returns the value unchanged.
+func RoundToStep(value float64, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	return roundToPrecision(math.Floor(value/step+0.5)*step, stepPrecision(step))
+}
+
+// RoundDownToStep rounds value down to a multiple of step. A step of 0
+// returns the value unchanged.
+func RoundDownToStep(value float64, step float64) float64 {
+	if step <= 0 {
+		return value
+	}
+	// The small epsilon prevents values that are already a multiple, but
+	// not exactly representable, from being rounded down a step.
+	return roundToPrecision(math.Floor(value/step+1e-9)*step, stepPrecision(step))
+}
+
+// IsMultipleOfStep returns true if value is a multiple of step, allowing
+// for floating point error. A step of 0 always returns true.
+func IsMultipleOfStep(value float64, step float64) bool {
+	if step <= 0 {
+		return true
+	}
+	steps := value / step
+	return math.Abs(steps-math.Round(steps)) < 1e-6
+}
+
+// stepPrecision returns the number of decimal places in a step size such as
+// 0.00100000.
+func stepPrecision(step float64) int {
+	formatted := strconv.FormatFloat(step, 'f', -1, 64)
+	if i := strings.IndexByte(formatted, '.'); i > -1 {
+		return len(formatted) - i - 1
+	}
+	return 0
+}
+
+func roundToPrecision(value float64, precision int) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', precision, 64), 64)
+	return rounded
+}
@@ -0,0 +1,116 @@
+package binance
+
+import (
+	"testing"
+)
+
+func testSymbolInfo() SymbolInfo {
+	return SymbolInfo{
+		TickSize:       0.000001,
+		MinPrice:       0.000001,
+		MaxPrice:       100000,
+		StepSize:       0.001,
+		MinQty:         0.001,
+		MaxQty:         100000,
+		MarketStepSize: 0.01,
+		MarketMinQty:   0.01,
+		MarketMaxQty:   1000,
+		MinNotional:    0.001,
+		MaxNotional:    1000,
+		MultiplierUp:   5,
+		MultiplierDown: 0.2,
+		MaxNumOrders:   200,
+		IcebergParts:   10,
+	}
+}
+
+func TestRoundToStep(t *testing.T) {
+	if v := RoundToStep(0.0712345, 0.000001); v != 0.071235 {
+		t.Errorf("expected 0.071235, got %v", v)
+	}
+	if v := RoundDownToStep(1.23456, 0.001); v != 1.234 {
+		t.Errorf("expected 1.234, got %v", v)
+	}
+	if v := RoundDownToStep(0.3, 0.1); v != 0.3 {
+		t.Errorf("expected 0.3, got %v", v)
+	}
+	if v := RoundDownToStep(12.5, 0); v != 12.5 {
+		t.Errorf("expected 12.5, got %v", v)
+	}
+}
+
+func TestNormalizeAndValidateOrder(t *testing.T) {
+	info := testSymbolInfo()
+	order := OrderParameters{
+		Symbol:      "ETHBTC",
+		Side:        OrderSideBuy,
+		Type:        OrderTypeLimit,
+		TimeInForce: TimeInForceGTC,
+		Quantity:    1.23456,
+		Price:       0.0712345,
+	}
+	if err := info.ValidateOrder(order, 0); err == nil {
+		t.Fatalf("expected error before normalizing")
+	}
+	info.NormalizeOrder(&order)
+	if order.Quantity != 1.234 || order.Price != 0.071235 {
+		t.Fatalf("unexpected normalized order: %+v", order)
+	}
+	if err := info.ValidateOrder(order, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateOrderFilterErrors(t *testing.T) {
+	info := testSymbolInfo()
+	limit := func(quantity float64, price float64) OrderParameters {
+		return OrderParameters{
+			Symbol:      "ETHBTC",
+			Side:        OrderSideBuy,
+			Type:        OrderTypeLimit,
+			TimeInForce: TimeInForceGTC,
+			Quantity:    quantity,
+			Price:       price,
+		}
+	}
+
+	tests := []struct {
+		order          OrderParameters
+		referencePrice float64
+		filter         string
+	}{
+		{limit(1, 0.0000001), 0, FilterPrice},
+		{limit(0.0001, 0.07), 0, FilterLotSize},
+		{limit(0, 0.07), 0, FilterLotSize},
+		{limit(0.01, 0.07), 0, FilterMinNotional},
+		{limit(20000, 0.07), 0, FilterNotional},
+		{limit(1, 0.5), 0.07, FilterPercentPrice},
+		{OrderParameters{Symbol: "ETHBTC", Side: OrderSideSell,
+			Type: OrderTypeMarket, Quantity: 0.005}, 0, FilterMarketLotSize},
+		{OrderParameters{Symbol: "ETHBTC", Side: OrderSideSell,
+			Type: OrderTypeLimit, TimeInForce: TimeInForceGTC, Quantity: 1,
+			Price: 0.07, IcebergQty: 0.05}, 0, FilterIcebergParts},
+	}
+	for _, test := range tests {
+		err := info.ValidateOrder(test.order, test.referencePrice)
+		filterError, ok := err.(*FilterError)
+		if !ok {
+			t.Errorf("expected %s filter error, got %v", test.filter, err)
+			continue
+		}
+		if filterError.Filter != test.filter {
+			t.Errorf("expected %s filter error, got %v", test.filter, err)
+		}
+	}
+}
+
+func TestValidateOpenOrderCount(t *testing.T) {
+	service := NewExchangeInfoService()
+	service.Symbols["ETHBTC"] = testSymbolInfo()
+	if err := service.ValidateOpenOrderCount("ETHBTC", OrderTypeLimit, 199, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.ValidateOpenOrderCount("ETHBTC", OrderTypeLimit, 200, 0); err == nil {
+		t.Fatal("expected MAX_NUM_ORDERS error")
+	}
+}
@@ -0,0 +1,256 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// OcoOrderParameters are the parameters for a new one-cancels-the-other
+// order list, made up of a LIMIT_MAKER order at Price and a STOP_LOSS (or
+// STOP_LOSS_LIMIT if StopLimitPrice is set) order at StopPrice.
+type OcoOrderParameters struct {
+	Symbol            string
+	Side              OrderSide
+	Quantity          float64
+	ListClientOrderId string
+
+	// The limit order.
+	Price              float64
+	LimitClientOrderId string
+	LimitIcebergQty    float64
+
+	// The stop order.
+	StopPrice            float64
+	StopLimitPrice       float64
+	StopLimitTimeInForce TimeInForce
+	StopClientOrderId    string
+	StopIcebergQty       float64
+
+	NewOrderRespType OrderResponseType
+}
+
+// Validate checks the required parameters and the price relationship of
+// the two orders.
+func (o *OcoOrderParameters) Validate() error {
+	if o.Symbol == "" {
+		return fmt.Errorf("symbol required")
+	}
+	if o.Side != OrderSideBuy && o.Side != OrderSideSell {
+		return fmt.Errorf("invalid side: %s", o.Side)
+	}
+	if o.Quantity <= 0 {
+		return fmt.Errorf("oco order requires a quantity")
+	}
+	if o.Price <= 0 {
+		return fmt.Errorf("oco order requires a price")
+	}
+	if o.StopPrice <= 0 {
+		return fmt.Errorf("oco order requires a stop price")
+	}
+	if o.StopLimitPrice > 0 && o.StopLimitTimeInForce == "" {
+		return fmt.Errorf("oco order with a stop limit price requires a stop limit time in force")
+	}
+	if o.Side == OrderSideSell && o.Price <= o.StopPrice {
+		return fmt.Errorf("oco sell order requires price above stop price")
+	}
+	if o.Side == OrderSideBuy && o.Price >= o.StopPrice {
+		return fmt.Errorf("oco buy order requires price below stop price")
+	}
+	return nil
+}
+
+type OrderListOrder struct {
+	Symbol        string `json:"symbol"`
+	OrderId       int64  `json:"orderId"`
+	ClientOrderId string `json:"clientOrderId"`
+}
+
+// OrderListResponse is returned when placing, cancelling or querying an
+// order list. OrderReports is only set when placing or cancelling.
+type OrderListResponse struct {
+	OrderListId           int64               `json:"orderListId"`
+	ContingencyType       string              `json:"contingencyType"`
+	ListStatusType        string              `json:"listStatusType"`
+	ListOrderStatus       string              `json:"listOrderStatus"`
+	ListClientOrderId     string              `json:"listClientOrderId"`
+	TransactionTimeMillis int64               `json:"transactionTime"`
+	Symbol                string              `json:"symbol"`
+	Orders                []OrderListOrder    `json:"orders"`
+	OrderReports          []PostOrderResponse `json:"orderReports"`
+}
+
+// legs returns the limit and stop orders of the list.
+func (o *OcoOrderParameters) legs() (limit OrderParameters, stop OrderParameters) {
+	limit = OrderParameters{
+		Symbol:     o.Symbol,
+		Side:       o.Side,
+		Type:       OrderTypeLimitMaker,
+		Quantity:   o.Quantity,
+		Price:      o.Price,
+		IcebergQty: o.LimitIcebergQty,
+	}
+	stop = OrderParameters{
+		Symbol:     o.Symbol,
+		Side:       o.Side,
+		Type:       OrderTypeStopLoss,
+		Quantity:   o.Quantity,
+		StopPrice:  o.StopPrice,
+		IcebergQty: o.StopIcebergQty,
+	}
+	if o.StopLimitPrice > 0 {
+		stop.Type = OrderTypeStopLossLimit
+		stop.Price = o.StopLimitPrice
+		stop.TimeInForce = o.StopLimitTimeInForce
+	}
+	return limit, stop
+}
+
+// PostOcoOrder places a new OCO order list. With an exchange info service
+// both orders are normalized and checked against the symbol filters, as
+// with PostOrder.
+func (c *RestClient) PostOcoOrder(order OcoOrderParameters) (*OrderListResponse, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	if c.exchangeInfo != nil {
+		if err := c.exchangeInfo.ValidateOco(order.Symbol); err != nil {
+			return nil, err
+		}
+		limit, stop := order.legs()
+		if err := c.applyOrderFilters(&limit, &stop); err != nil {
+			return nil, err
+		}
+		order.Quantity = limit.Quantity
+		order.Price = limit.Price
+		order.LimitIcebergQty = limit.IcebergQty
+		order.StopPrice = stop.StopPrice
+		order.StopIcebergQty = stop.IcebergQty
+		if order.StopLimitPrice > 0 {
+			order.StopLimitPrice = stop.Price
+		}
+	}
+
+	params := map[string]interface{}{
+		"symbol":    order.Symbol,
+		"side":      order.Side,
+		"quantity":  formatDecimal(order.Quantity),
+		"price":     formatDecimal(order.Price),
+		"stopPrice": formatDecimal(order.StopPrice),
+	}
+	if order.ListClientOrderId != "" {
+		params["listClientOrderId"] = order.ListClientOrderId
+	}
+	if order.LimitClientOrderId != "" {
+		params["limitClientOrderId"] = order.LimitClientOrderId
+	}
+	if order.LimitIcebergQty > 0 {
+		params["limitIcebergQty"] = formatDecimal(order.LimitIcebergQty)
+	}
+	if order.StopLimitPrice > 0 {
+		params["stopLimitPrice"] = formatDecimal(order.StopLimitPrice)
+		params["stopLimitTimeInForce"] = order.StopLimitTimeInForce
+	}
+	if order.StopClientOrderId != "" {
+		params["stopClientOrderId"] = order.StopClientOrderId
+	}
+	if order.StopIcebergQty > 0 {
+		params["stopIcebergQty"] = formatDecimal(order.StopIcebergQty)
+	}
+	if order.NewOrderRespType != "" {
+		params["newOrderRespType"] = order.NewOrderRespType
+	}
+
+	httpResponse, err := c.Post("/api/v3/order/oco", params)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOrderListResponse(httpResponse)
+}
+
+// CancelOrderList cancels all the orders of an order list.
+func (c *RestClient) CancelOrderList(symbol string, orderListId int64) (*OrderListResponse, error) {
+	params := map[string]interface{}{
+		"symbol":      symbol,
+		"orderListId": orderListId,
+	}
+	httpResponse, err := c.Delete("/api/v3/orderList", params)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOrderListResponse(httpResponse)
+}
+
+// CancelOrderListByClientId cancels all the orders of an order list using
+// the list client order ID.
+func (c *RestClient) CancelOrderListByClientId(symbol string, listClientOrderId string) (*OrderListResponse, error) {
+	params := map[string]interface{}{
+		"symbol":            symbol,
+		"listClientOrderId": listClientOrderId,
+	}
+	httpResponse, err := c.Delete("/api/v3/orderList", params)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOrderListResponse(httpResponse)
+}
+
+// GetOrderList returns the status of an order list.
+func (c *RestClient) GetOrderList(orderListId int64) (*OrderListResponse, error) {
+	params := map[string]interface{}{
+		"orderListId": orderListId,
+	}
+	httpResponse, err := c.GetWithAuth("/api/v3/orderList", params)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOrderListResponse(httpResponse)
+}
+
+// GetOrderListByClientId returns the status of an order list using the
+// list client order ID.
+func (c *RestClient) GetOrderListByClientId(listClientOrderId string) (*OrderListResponse, error) {
+	params := map[string]interface{}{
+		"origClientOrderId": listClientOrderId,
+	}
+	httpResponse, err := c.GetWithAuth("/api/v3/orderList", params)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeOrderListResponse(httpResponse)
+}
+
+func (c *RestClient) decodeOrderListResponse(httpResponse *http.Response) (*OrderListResponse, error) {
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, NewRestApiErrorFromResponse(httpResponse)
+	}
+	var response OrderListResponse
+	if _, err := c.decodeBody(httpResponse, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
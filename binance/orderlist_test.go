@@ -0,0 +1,195 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testOcoResponse = `{"orderListId":0,"contingencyType":"OCO","listStatusType":"EXEC_STARTED","listOrderStatus":"EXECUTING","listClientOrderId":"JYVpp3F0f5CAG15DhtrqLp","transactionTime":1563417480525,"symbol":"LTCBTC","orders":[{"symbol":"LTCBTC","orderId":2,"clientOrderId":"Kk7sqHb9J6mJWTMDVW7Vos"},{"symbol":"LTCBTC","orderId":3,"clientOrderId":"xTXKaGYd4bluPVp78IVRvl"}],"orderReports":[{"symbol":"LTCBTC","orderId":2,"orderListId":0,"clientOrderId":"Kk7sqHb9J6mJWTMDVW7Vos","transactTime":1563417480525,"price":"0.000000","origQty":"0.624363","executedQty":"0.000000","cummulativeQuoteQty":"0.000000","status":"NEW","timeInForce":"GTC","type":"STOP_LOSS_LIMIT","side":"BUY","stopPrice":"0.960664"},{"symbol":"LTCBTC","orderId":3,"orderListId":0,"clientOrderId":"xTXKaGYd4bluPVp78IVRvl","transactTime":1563417480525,"price":"0.036435","origQty":"0.624363","executedQty":"0.000000","cummulativeQuoteQty":"0.000000","status":"NEW","timeInForce":"GTC","type":"LIMIT_MAKER","side":"BUY"}]}`
+
+func TestPostOcoOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v3/order/oco" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		query := r.URL.Query()
+		expected := map[string]string{
+			"symbol":               "LTCBTC",
+			"side":                 "BUY",
+			"quantity":             "0.624363",
+			"price":                "0.036435",
+			"stopPrice":            "0.960664",
+			"stopLimitPrice":       "0.96",
+			"stopLimitTimeInForce": "GTC",
+			"listClientOrderId":    "JYVpp3F0f5CAG15DhtrqLp",
+		}
+		for name, value := range expected {
+			if query.Get(name) != value {
+				t.Errorf("expected %s=%s, got %q", name, value, query.Get(name))
+			}
+		}
+		if query.Get("limitIcebergQty") != "" || query.Get("stopClientOrderId") != "" {
+			t.Errorf("unexpected parameters: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(testOcoResponse))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	response, err := client.PostOcoOrder(OcoOrderParameters{
+		Symbol:               "LTCBTC",
+		Side:                 OrderSideBuy,
+		Quantity:             0.624363,
+		ListClientOrderId:    "JYVpp3F0f5CAG15DhtrqLp",
+		Price:                0.036435,
+		StopPrice:            0.960664,
+		StopLimitPrice:       0.96,
+		StopLimitTimeInForce: TimeInForceGTC,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ListClientOrderId != "JYVpp3F0f5CAG15DhtrqLp" || len(response.Orders) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	if len(response.OrderReports) != 2 {
+		t.Fatalf("expected 2 order reports, got %d", len(response.OrderReports))
+	}
+	report := response.OrderReports[0]
+	if report.Type != OrderTypeStopLossLimit || report.StopPrice != 0.960664 ||
+		report.OrigQty != 0.624363 {
+		t.Errorf("unexpected stop order report: %+v", report)
+	}
+	if report := response.OrderReports[1]; report.Type != OrderTypeLimitMaker ||
+		report.Price != 0.036435 {
+		t.Errorf("unexpected limit order report: %+v", report)
+	}
+}
+
+func TestCancelAndGetOrderList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/orderList" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("orderListId") != "0" {
+			t.Errorf("expected orderListId=0, got %s", r.URL.RawQuery)
+		}
+		switch r.Method {
+		case "DELETE":
+			if query.Get("symbol") != "LTCBTC" {
+				t.Errorf("expected symbol=LTCBTC, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(testOcoResponse))
+		case "GET":
+			w.Write([]byte(`{"orderListId":0,"contingencyType":"OCO","listStatusType":"ALL_DONE","listOrderStatus":"ALL_DONE","listClientOrderId":"JYVpp3F0f5CAG15DhtrqLp","transactionTime":1563417480525,"symbol":"LTCBTC","orders":[{"symbol":"LTCBTC","orderId":2,"clientOrderId":"Kk7sqHb9J6mJWTMDVW7Vos"},{"symbol":"LTCBTC","orderId":3,"clientOrderId":"xTXKaGYd4bluPVp78IVRvl"}]}`))
+		default:
+			t.Errorf("unexpected method: %s", r.Method)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	cancelled, err := client.CancelOrderList("LTCBTC", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancelled.OrderReports) != 2 || cancelled.OrderReports[1].OrderId != 3 {
+		t.Fatalf("unexpected order reports: %+v", cancelled.OrderReports)
+	}
+
+	list, err := client.GetOrderList(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.ListOrderStatus != "ALL_DONE" || len(list.Orders) != 2 ||
+		len(list.OrderReports) != 0 {
+		t.Fatalf("unexpected order list: %+v", list)
+	}
+}
+
+func TestOcoOrderParametersValidate(t *testing.T) {
+	valid := OcoOrderParameters{
+		Symbol:    "LTCBTC",
+		Side:      OrderSideSell,
+		Quantity:  1,
+		Price:     0.02,
+		StopPrice: 0.01,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]func(o *OcoOrderParameters){
+		"no symbol":   func(o *OcoOrderParameters) { o.Symbol = "" },
+		"bad side":    func(o *OcoOrderParameters) { o.Side = "HOLD" },
+		"no quantity": func(o *OcoOrderParameters) { o.Quantity = 0 },
+		"no price":    func(o *OcoOrderParameters) { o.Price = 0 },
+		"no stop":     func(o *OcoOrderParameters) { o.StopPrice = 0 },
+		"no stop tif": func(o *OcoOrderParameters) { o.StopLimitPrice = 0.009 },
+		"sell below":  func(o *OcoOrderParameters) { o.Price = 0.005 },
+		"buy above":   func(o *OcoOrderParameters) { o.Side = OrderSideBuy },
+	}
+	for name, modify := range invalid {
+		order := valid
+		modify(&order)
+		if err := order.Validate(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestPostOcoOrderFilters(t *testing.T) {
+	var orders int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/avgPrice":
+			w.Write([]byte(`{"mins":5,"price":"0.07000000"}`))
+		default:
+			orders++
+			query := r.URL.Query()
+			if query.Get("price") != "0.071235" || query.Get("stopLimitPrice") != "0.06" ||
+				query.Get("quantity") != "1.234" {
+				t.Errorf("expected normalized parameters, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(testOcoResponse))
+		}
+	}))
+	defer server.Close()
+
+	info := testSymbolInfo()
+	info.OcoAllowed = true
+	service := NewExchangeInfoService()
+	service.Symbols["ETHBTC"] = info
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL),
+		WithExchangeInfoService(service))
+
+	order := OcoOrderParameters{
+		Symbol:               "ETHBTC",
+		Side:                 OrderSideSell,
+		Quantity:             1.23456,
+		Price:                0.0712345,
+		StopPrice:            0.061,
+		StopLimitPrice:       0.06,
+		StopLimitTimeInForce: TimeInForceGTC,
+	}
+
+	// The stop limit price is less than 0.2 times the average price.
+	invalid := order
+	invalid.StopLimitPrice = 0.01
+	_, err := client.PostOcoOrder(invalid)
+	if filterError, ok := err.(*FilterError); !ok || filterError.Filter != FilterPercentPrice {
+		t.Fatalf("expected %s filter error, got %v", FilterPercentPrice, err)
+	}
+	if orders != 0 {
+		t.Fatalf("expected no orders to be sent, got %d", orders)
+	}
+
+	if _, err := client.PostOcoOrder(order); err != nil {
+		t.Fatal(err)
+	}
+	if orders != 1 {
+		t.Fatalf("expected 1 order to be sent, got %d", orders)
+	}
+}
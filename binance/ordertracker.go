@@ -0,0 +1,462 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrOrderNotTracked = errors.New("order not tracked")
+	ErrWaitTimeout     = errors.New("timeout waiting for order")
+)
+
+// How many times a reconcile after the stream connects is attempted, with
+// the delay doubling from reconcileRetryDelay after each failure.
+const (
+	reconcileAttempts   = 5
+	reconcileRetryDelay = time.Second
+)
+
+// TrackedOrder is the state of an order as known to an OrderTracker.
+type TrackedOrder struct {
+	Symbol             string
+	OrderID            int64
+	ClientOrderID      string
+	Side               OrderSide
+	Type               OrderType
+	Price              float64
+	StopPrice          float64
+	Quantity           float64
+	Status             OrderStatus
+	RejectReason       string
+	ExecutedQty        float64
+	CumulativeQuoteQty float64
+	UpdateTimeMillis   int64
+
+	// The fills seen in execution reports. Fills made while the stream
+	// was disconnected are included in ExecutedQty and CumulativeQuoteQty
+	// after reconciling, but not here.
+	Fills []OrderFill
+
+	// Commission paid per asset, from Fills.
+	Commissions map[string]float64
+}
+
+// AveragePrice returns the average execution price of the order, or 0 if
+// nothing has been executed.
+func (o *TrackedOrder) AveragePrice() float64 {
+	if o.ExecutedQty == 0 {
+		return 0
+	}
+	return o.CumulativeQuoteQty / o.ExecutedQty
+}
+
+func (o *TrackedOrder) copy() TrackedOrder {
+	order := *o
+	order.Fills = append([]OrderFill{}, o.Fills...)
+	order.Commissions = map[string]float64{}
+	for asset, commission := range o.Commissions {
+		order.Commissions[asset] = commission
+	}
+	return order
+}
+
+type orderKey struct {
+	symbol  string
+	orderID int64
+}
+
+// OrderTracker keeps the state of orders up to date from the execution
+// reports of a user stream, seeded from and reconciled with the REST API.
+type OrderTracker struct {
+	client *RestClient
+
+	lock       sync.Mutex
+	orders     map[orderKey]*TrackedOrder
+	byClientID map[string]orderKey
+	symbols    map[string]bool
+
+	// Closed and replaced on each change to wake up waiters.
+	changed chan struct{}
+
+	onReconcileError []func(error)
+
+	// Incremented on each connection state change, so a reconcile retrying
+	// for an earlier connection gives up.
+	connection int64
+	retryDelay time.Duration
+}
+
+func NewOrderTracker(client *RestClient) *OrderTracker {
+	return &OrderTracker{
+		client:     client,
+		orders:     map[orderKey]*TrackedOrder{},
+		byClientID: map[string]orderKey{},
+		symbols:    map[string]bool{},
+		changed:    make(chan struct{}),
+		retryDelay: reconcileRetryDelay,
+	}
+}
+
+// OnReconcileError registers a callback called when a reconcile after the
+// stream connects fails. Until a retry succeeds orders may be stale, so
+// waits may time out on orders that are already done.
+func (t *OrderTracker) OnReconcileError(callback func(error)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.onReconcileError = append(t.onReconcileError, callback)
+}
+
+// Seed loads the open orders of a symbol, or of all symbols if empty. The
+// symbol is also reloaded by Reconcile.
+func (t *OrderTracker) Seed(symbol string) error {
+	orders, err := t.client.GetOpenOrders(symbol)
+	if err != nil {
+		return err
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.symbols[symbol] = true
+	for i := range orders {
+		t.updateFromQuery(&orders[i])
+	}
+	t.notify()
+	return nil
+}
+
+// Attach applies the execution reports of stream, and reconciles each time
+// the stream connects as reports may have been missed. A failed reconcile
+// is reported to the OnReconcileError callbacks and retried with backoff.
+func (t *OrderTracker) Attach(stream *UserStream) {
+	stream.OnExecutionReport(t.Apply)
+	stream.OnConnectionState(func(state StreamEventType, err error) {
+		t.lock.Lock()
+		t.connection++
+		connection := t.connection
+		t.lock.Unlock()
+		if state == StreamEventConnected {
+			go t.reconcileWithRetry(connection)
+		}
+	})
+}
+
+// reconcileWithRetry reconciles until it succeeds, the attempts run out or
+// the connection state changes.
+func (t *OrderTracker) reconcileWithRetry(connection int64) {
+	delay := t.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := t.Reconcile()
+		if err == nil {
+			return
+		}
+		t.lock.Lock()
+		callbacks := t.onReconcileError
+		current := t.connection == connection
+		t.lock.Unlock()
+		if !current {
+			return
+		}
+		for _, callback := range callbacks {
+			callback(err)
+		}
+		if attempt == reconcileAttempts {
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Track adds an order placed with PostOrder.
+func (t *OrderTracker) Track(response *PostOrderResponse) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	order := t.getOrCreate(response.Symbol, response.OrderId, response.ClientOrderId)
+	if order.Status == "" || !order.Status.IsTerminal() {
+		if response.Status != "" {
+			order.Status = response.Status
+		}
+		order.Side = response.Side
+		order.Type = response.Type
+		order.Price = response.Price
+		order.StopPrice = response.StopPrice
+		order.Quantity = response.OrigQty
+		if response.ExecutedQty > order.ExecutedQty {
+			order.ExecutedQty = response.ExecutedQty
+			order.CumulativeQuoteQty = response.CumulativeQuoteQty
+		}
+		for _, fill := range response.Fills {
+			order.addFill(fill)
+		}
+	}
+	t.notify()
+}
+
+// Apply updates the order of an execution report.
+func (t *OrderTracker) Apply(report *StreamExecutionReport) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// The client order ID of a cancel report is that of the cancel request,
+	// the order's own is the original one.
+	clientOrderID := report.ClientOrderID
+	if report.CurrentOrderStatus == OrderStatusCanceled && report.OriginalClientOrderID != "" {
+		clientOrderID = report.OriginalClientOrderID
+	}
+	order := t.getOrCreate(report.Symbol, report.OrderID, clientOrderID)
+
+	if report.CurrentExecutionType == ExecutionTypeTrade {
+		order.addFill(OrderFill{
+			TradeID:         report.TradeID,
+			Price:           report.LastExecutedPrice,
+			Quantity:        report.LastExecutedQuantity,
+			Commission:      report.CommissionAmount,
+			CommissionAsset: report.CommissionAsset,
+		})
+	}
+
+	// Reports can arrive after a reconcile has already seen a later
+	// state.
+	if report.TransactionTimeMillis < order.UpdateTimeMillis ||
+		(order.Status.IsTerminal() && !report.CurrentOrderStatus.IsTerminal()) {
+		t.notify()
+		return
+	}
+
+	order.Side = report.Side
+	order.Type = OrderType(report.OrderType)
+	order.Price = report.Price
+	order.StopPrice = report.StopPrice
+	order.Quantity = report.Quantity
+	order.Status = report.CurrentOrderStatus
+	order.RejectReason = report.OrderRejectReason
+	if report.CumulativeFilledQuantity >= order.ExecutedQty {
+		order.ExecutedQty = report.CumulativeFilledQuantity
+		order.CumulativeQuoteQty = report.CumulativeQuoteQuantity
+	}
+	order.UpdateTimeMillis = report.TransactionTimeMillis
+	t.notify()
+}
+
+// Reconcile reloads the open orders of the seeded symbols and queries the
+// tracked orders that are no longer open, to catch up on changes missed
+// while the user stream was disconnected.
+func (t *OrderTracker) Reconcile() error {
+	t.lock.Lock()
+	symbols := []string{}
+	for symbol := range t.symbols {
+		symbols = append(symbols, symbol)
+	}
+	t.lock.Unlock()
+
+	open := map[orderKey]bool{}
+	for _, symbol := range symbols {
+		orders, err := t.client.GetOpenOrders(symbol)
+		if err != nil {
+			return err
+		}
+		t.lock.Lock()
+		for i := range orders {
+			t.updateFromQuery(&orders[i])
+			open[orderKey{orders[i].Symbol, orders[i].OrderId}] = true
+		}
+		t.notify()
+		t.lock.Unlock()
+	}
+
+	t.lock.Lock()
+	pending := []orderKey{}
+	for key, order := range t.orders {
+		if !order.Status.IsTerminal() && !open[key] {
+			pending = append(pending, key)
+		}
+	}
+	t.lock.Unlock()
+
+	for _, key := range pending {
+		order, err := t.client.GetOrderByOrderId(key.symbol, key.orderID)
+		if err != nil {
+			return err
+		}
+		t.lock.Lock()
+		t.updateFromQuery(&order)
+		t.notify()
+		t.lock.Unlock()
+	}
+	return nil
+}
+
+// Must be called with the lock held.
+func (t *OrderTracker) getOrCreate(symbol string, orderID int64, clientOrderID string) *TrackedOrder {
+	key := orderKey{symbol, orderID}
+	order, ok := t.orders[key]
+	if !ok {
+		order = &TrackedOrder{
+			Symbol:      symbol,
+			OrderID:     orderID,
+			Commissions: map[string]float64{},
+		}
+		t.orders[key] = order
+	}
+	if clientOrderID != "" && order.ClientOrderID == "" {
+		order.ClientOrderID = clientOrderID
+		t.byClientID[clientOrderID] = key
+	}
+	return order
+}
+
+// Must be called with the lock held.
+func (t *OrderTracker) updateFromQuery(response *QueryOrderResponse) {
+	order := t.getOrCreate(response.Symbol, response.OrderId, response.ClientOrderId)
+	if response.UpdateTimeMillis < order.UpdateTimeMillis {
+		return
+	}
+	order.Side = response.Side
+	order.Type = response.Type
+	order.Price = response.Price
+	order.StopPrice = response.StopPrice
+	order.Quantity = response.OrigQty
+	order.Status = response.Status
+	order.ExecutedQty = response.ExecutedQty
+	order.CumulativeQuoteQty = response.CumulativeQuoteQty
+	order.UpdateTimeMillis = response.UpdateTimeMillis
+}
+
+func (o *TrackedOrder) addFill(fill OrderFill) {
+	for _, existing := range o.Fills {
+		if existing.TradeID == fill.TradeID {
+			return
+		}
+	}
+	o.Fills = append(o.Fills, fill)
+	if fill.CommissionAsset != "" {
+		o.Commissions[fill.CommissionAsset] += fill.Commission
+	}
+}
+
+// Must be called with the lock held.
+func (t *OrderTracker) notify() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// Order returns a copy of a tracked order.
+func (t *OrderTracker) Order(symbol string, orderID int64) (TrackedOrder, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	order, ok := t.orders[orderKey{symbol, orderID}]
+	if !ok {
+		return TrackedOrder{}, false
+	}
+	return order.copy(), true
+}
+
+// OrderByClientID returns a copy of a tracked order by its client order ID.
+func (t *OrderTracker) OrderByClientID(clientOrderID string) (TrackedOrder, bool) {
+	t.lock.Lock()
+	key, ok := t.byClientID[clientOrderID]
+	t.lock.Unlock()
+	if !ok {
+		return TrackedOrder{}, false
+	}
+	return t.Order(key.symbol, key.orderID)
+}
+
+// OpenOrders returns copies of the tracked orders that are not terminal.
+func (t *OrderTracker) OpenOrders() []TrackedOrder {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	orders := []TrackedOrder{}
+	for _, order := range t.orders {
+		if !order.Status.IsTerminal() {
+			orders = append(orders, order.copy())
+		}
+	}
+	return orders
+}
+
+// Forget stops tracking an order.
+func (t *OrderTracker) Forget(symbol string, orderID int64) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	key := orderKey{symbol, orderID}
+	if order, ok := t.orders[key]; ok {
+		delete(t.byClientID, order.ClientOrderID)
+		delete(t.orders, key)
+	}
+}
+
+// wait waits until done returns true for the order or the timeout expires.
+func (t *OrderTracker) wait(symbol string, orderID int64, timeout time.Duration, done func(*TrackedOrder) bool) (TrackedOrder, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		t.lock.Lock()
+		order, ok := t.orders[orderKey{symbol, orderID}]
+		changed := t.changed
+		if !ok {
+			t.lock.Unlock()
+			return TrackedOrder{}, ErrOrderNotTracked
+		}
+		if done(order) {
+			result := order.copy()
+			t.lock.Unlock()
+			return result, nil
+		}
+		last := order.copy()
+		t.lock.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return last, ErrWaitTimeout
+		}
+	}
+}
+
+// WaitForTerminal waits until the order is filled, canceled, rejected or
+// expired, returning its final state.
+func (t *OrderTracker) WaitForTerminal(symbol string, orderID int64, timeout time.Duration) (TrackedOrder, error) {
+	return t.wait(symbol, orderID, timeout, func(order *TrackedOrder) bool {
+		return order.Status.IsTerminal()
+	})
+}
+
+// WaitForFill waits until the order is completely filled. An error is
+// returned if the order ends without being filled.
+func (t *OrderTracker) WaitForFill(symbol string, orderID int64, timeout time.Duration) (TrackedOrder, error) {
+	order, err := t.WaitForTerminal(symbol, orderID, timeout)
+	if err != nil {
+		return order, err
+	}
+	if order.Status != OrderStatusFilled {
+		return order, fmt.Errorf("order %d %s", orderID, order.Status)
+	}
+	return order, nil
+}
@@ -0,0 +1,172 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOrderTrackerApply(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"symbol":"ETHBTC","orderId":1,"clientOrderId":"one","price":"0.05","origQty":"2.0","executedQty":"0.0","cummulativeQuoteQty":"0.0","status":"NEW","timeInForce":"GTC","type":"LIMIT","side":"BUY","time":1000,"updateTime":1000,"isWorking":true}]`))
+	}))
+	defer server.Close()
+
+	tracker := NewOrderTracker(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	if err := tracker.Seed("ETHBTC"); err != nil {
+		t.Fatal(err)
+	}
+	if order, ok := tracker.OrderByClientID("one"); !ok || order.Status != OrderStatusNew {
+		t.Fatalf("expected seeded order, got %+v", order)
+	}
+
+	filled := make(chan TrackedOrder)
+	go func() {
+		order, err := tracker.WaitForFill("ETHBTC", 1, 5*time.Second)
+		if err != nil {
+			t.Error(err)
+		}
+		filled <- order
+	}()
+
+	report := StreamExecutionReport{
+		Symbol:                   "ETHBTC",
+		ClientOrderID:            "one",
+		Side:                     OrderSideBuy,
+		OrderType:                "LIMIT",
+		Quantity:                 2,
+		Price:                    0.05,
+		CurrentExecutionType:     ExecutionTypeTrade,
+		CurrentOrderStatus:       OrderStatusPartiallyFilled,
+		OrderID:                  1,
+		LastExecutedQuantity:     1,
+		CumulativeFilledQuantity: 1,
+		LastExecutedPrice:        0.05,
+		CumulativeQuoteQuantity:  0.05,
+		CommissionAmount:         0.001,
+		CommissionAsset:          "BNB",
+		TransactionTimeMillis:    2000,
+		TradeID:                  10,
+	}
+	tracker.Apply(&report)
+	// Delivered twice, should only count once.
+	tracker.Apply(&report)
+
+	report.CurrentOrderStatus = OrderStatusFilled
+	report.LastExecutedPrice = 0.04
+	report.CumulativeFilledQuantity = 2
+	report.CumulativeQuoteQuantity = 0.09
+	report.TransactionTimeMillis = 3000
+	report.TradeID = 11
+	tracker.Apply(&report)
+
+	order := <-filled
+	if order.ExecutedQty != 2 || order.AveragePrice() != 0.045 {
+		t.Fatalf("unexpected fill: %+v", order)
+	}
+	if len(order.Fills) != 2 || order.Commissions["BNB"] != 0.002 {
+		t.Fatalf("unexpected fills or commissions: %+v", order)
+	}
+	if len(tracker.OpenOrders()) != 0 {
+		t.Fatalf("expected no open orders")
+	}
+
+	// A late report doesn't undo the terminal state.
+	report.CurrentOrderStatus = OrderStatusNew
+	report.CurrentExecutionType = OrderStatusNew
+	report.TransactionTimeMillis = 1500
+	tracker.Apply(&report)
+	if order, _ := tracker.Order("ETHBTC", 1); order.Status != OrderStatusFilled {
+		t.Fatalf("expected order to stay filled, got %s", order.Status)
+	}
+}
+
+func TestOrderTrackerReconcile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/openOrders":
+			w.Write([]byte(`[]`))
+		case "/api/v3/order":
+			if r.URL.Query().Get("orderId") != "7" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"symbol":"ETHBTC","orderId":7,"clientOrderId":"seven","price":"0.05","origQty":"1.0","executedQty":"0.5","cummulativeQuoteQty":"0.025","status":"CANCELED","type":"LIMIT","side":"SELL","updateTime":5000}`))
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewOrderTracker(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	if err := tracker.Seed("ETHBTC"); err != nil {
+		t.Fatal(err)
+	}
+	tracker.Track(&PostOrderResponse{
+		Symbol:        "ETHBTC",
+		OrderId:       7,
+		ClientOrderId: "seven",
+		Status:        OrderStatusNew,
+		OrigQty:       1,
+	})
+	if _, err := tracker.WaitForTerminal("ETHBTC", 7, 10*time.Millisecond); err != ErrWaitTimeout {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+
+	if err := tracker.Reconcile(); err != nil {
+		t.Fatal(err)
+	}
+	order, err := tracker.WaitForTerminal("ETHBTC", 7, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != OrderStatusCanceled || order.ExecutedQty != 0.5 {
+		t.Fatalf("unexpected reconciled order: %+v", order)
+	}
+	if _, err := tracker.WaitForFill("ETHBTC", 7, time.Second); err == nil {
+		t.Fatalf("expected error waiting for fill of a canceled order")
+	}
+}
+
+func TestOrderTrackerReconcileRetries(t *testing.T) {
+	var failures int32 = 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1003,"msg":"Too many requests."}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v3/openOrders":
+			w.Write([]byte(`[]`))
+		case "/api/v3/order":
+			w.Write([]byte(`{"symbol":"ETHBTC","orderId":7,"clientOrderId":"seven","price":"0.05","origQty":"1.0","executedQty":"1.0","cummulativeQuoteQty":"0.05","status":"FILLED","type":"LIMIT","side":"SELL","updateTime":5000}`))
+		}
+	}))
+	defer server.Close()
+
+	tracker := NewOrderTracker(NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL)))
+	tracker.retryDelay = time.Millisecond
+	errs := []error{}
+	tracker.OnReconcileError(func(err error) {
+		errs = append(errs, err)
+	})
+	tracker.Track(&PostOrderResponse{
+		Symbol:        "ETHBTC",
+		OrderId:       7,
+		ClientOrderId: "seven",
+		Status:        OrderStatusNew,
+		OrigQty:       1,
+	})
+
+	tracker.reconcileWithRetry(0)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 reconcile errors, got %d: %v", len(errs), errs)
+	}
+	order, err := tracker.WaitForTerminal("ETHBTC", 7, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != OrderStatusFilled {
+		t.Fatalf("unexpected reconciled order: %+v", order)
+	}
+}
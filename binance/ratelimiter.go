@@ -0,0 +1,258 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+// The default limits, used until they are updated from the exchange info.
+var DefaultRateLimits = []RateLimit{
+	{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 1200},
+	{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 50},
+	{RateLimitType: "ORDERS", Interval: "DAY", IntervalNum: 1, Limit: 160000},
+}
+
+type intervalLimit struct {
+	limiter *core.IntervalLimiter
+
+	// The header Binance reports the current usage of this limit in, for
+	// example X-MBX-USED-WEIGHT-1M.
+	header string
+}
+
+// RateLimiter implements core.RateLimiter for the Binance REQUEST_WEIGHT and
+// ORDERS limits. The weight of each request is estimated from the endpoint,
+// then corrected from the X-MBX-USED-WEIGHT and X-MBX-ORDER-COUNT headers of
+// the response.
+type RateLimiter struct {
+	weight []intervalLimit
+	orders []intervalLimit
+
+	// Requests are held until this time after a 429 or 418 response.
+	lock      sync.Mutex
+	holdUntil time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewRateLimiter(limits []RateLimit) *RateLimiter {
+	limiter := &RateLimiter{
+		now:   time.Now,
+		sleep: time.Sleep,
+	}
+	for _, limit := range limits {
+		duration := limit.Duration()
+		if duration == 0 {
+			continue
+		}
+		switch limit.RateLimitType {
+		case "REQUEST_WEIGHT":
+			limiter.weight = append(limiter.weight, intervalLimit{
+				limiter: core.NewIntervalLimiter(limit.Limit, duration),
+				header:  fmt.Sprintf("X-Mbx-Used-Weight-%s", intervalSuffix(limit)),
+			})
+		case "ORDERS":
+			limiter.orders = append(limiter.orders, intervalLimit{
+				limiter: core.NewIntervalLimiter(limit.Limit, duration),
+				header:  fmt.Sprintf("X-Mbx-Order-Count-%s", intervalSuffix(limit)),
+			})
+		}
+	}
+	return limiter
+}
+
+func NewDefaultRateLimiter() *RateLimiter {
+	return NewRateLimiter(DefaultRateLimits)
+}
+
+// NewRateLimiterFromExchangeInfo creates a RateLimiter using the limits
+// currently published by the exchange.
+func NewRateLimiterFromExchangeInfo(client *RestClient) (*RateLimiter, error) {
+	exchangeInfo, err := client.GetExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+	return NewRateLimiter(exchangeInfo.RateLimits), nil
+}
+
+func (l *RateLimiter) Wait(request *http.Request) {
+	for {
+		l.lock.Lock()
+		wait := l.holdUntil.Sub(l.now())
+		l.lock.Unlock()
+		if wait <= 0 {
+			break
+		}
+		l.sleep(wait)
+	}
+	weight := requestWeight(request)
+	for _, limit := range l.weight {
+		limit.limiter.Acquire(weight)
+	}
+	if orders := requestOrderCount(request); orders > 0 {
+		for _, limit := range l.orders {
+			limit.limiter.Acquire(orders)
+		}
+	}
+}
+
+func (l *RateLimiter) Update(request *http.Request, response *http.Response) {
+	for _, limit := range l.weight {
+		if used, ok := headerInt(response, limit.header); ok {
+			limit.limiter.SetUsed(used)
+		} else if limit.limiter.Interval() == time.Minute {
+			// Older responses only include the unsuffixed header for the
+			// minute weight.
+			if used, ok := headerInt(response, "X-Mbx-Used-Weight"); ok {
+				limit.limiter.SetUsed(used)
+			}
+		}
+	}
+	for _, limit := range l.orders {
+		if used, ok := headerInt(response, limit.header); ok {
+			limit.limiter.SetUsed(used)
+		}
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode == http.StatusTeapot {
+		// Hold off all requests until the next window, and for as long as
+		// Retry-After says. Requests sent during a 418 ban extend it.
+		for _, limit := range l.weight {
+			limit.limiter.SetUsed(limit.limiter.Limit())
+		}
+		if retryAfter := parseRetryAfter(response); retryAfter > 0 {
+			l.lock.Lock()
+			if holdUntil := l.now().Add(retryAfter); holdUntil.After(l.holdUntil) {
+				l.holdUntil = holdUntil
+			}
+			l.lock.Unlock()
+		}
+	}
+}
+
+// UsedWeight returns the request weight used in the current window of each
+// REQUEST_WEIGHT interval.
+func (l *RateLimiter) UsedWeight() map[time.Duration]int64 {
+	used := map[time.Duration]int64{}
+	for _, limit := range l.weight {
+		used[limit.limiter.Interval()] = limit.limiter.Used()
+	}
+	return used
+}
+
+func headerInt(response *http.Response, name string) (int64, bool) {
+	if response == nil {
+		return 0, false
+	}
+	value := response.Header.Get(name)
+	if value == "" {
+		return 0, false
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return i, true
+}
+
+// intervalSuffix returns the suffix Binance uses for the interval in usage
+// headers, ie: 1M for 1 MINUTE.
+func intervalSuffix(limit RateLimit) string {
+	num := limit.IntervalNum
+	if num < 1 {
+		num = 1
+	}
+	return fmt.Sprintf("%d%s", num, limit.Interval[0:1])
+}
+
+// requestWeight returns the documented weight of a request.
+func requestWeight(request *http.Request) int64 {
+	query := request.URL.Query()
+	hasSymbol := query.Get("symbol") != ""
+	path := request.URL.Path
+	switch {
+	case path == "/api/v3/depth":
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		switch {
+		case limit > 1000:
+			return 50
+		case limit > 500:
+			return 10
+		case limit > 100:
+			return 5
+		}
+		return 1
+	case path == "/api/v3/ticker/price", path == "/api/v3/ticker/bookTicker":
+		if hasSymbol {
+			return 1
+		}
+		return 2
+	case path == "/api/v3/ticker/24hr":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+	case path == "/api/v3/openOrders" && request.Method == "GET":
+		if hasSymbol {
+			return 3
+		}
+		return 40
+	case path == "/api/v3/myTrades", path == "/api/v3/account",
+		path == "/api/v3/allOrders", path == "/api/v3/allOrderList",
+		strings.HasSuffix(path, "/exchangeInfo"):
+		return 10
+	case path == "/api/v3/historicalTrades":
+		return 5
+	case path == "/api/v3/openOrderList":
+		return 3
+	case (path == "/api/v3/order" || path == "/api/v3/orderList") && request.Method == "GET":
+		return 2
+	}
+	return 1
+}
+
+// requestOrderCount returns the number of orders a request places.
+func requestOrderCount(request *http.Request) int64 {
+	if request.Method != "POST" {
+		return 0
+	}
+	switch request.URL.Path {
+	case "/api/v3/order":
+		return 1
+	case "/api/v3/order/oco":
+		return 2
+	}
+	return 0
+}
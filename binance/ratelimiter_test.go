@@ -0,0 +1,84 @@
+package binance
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequestWeight(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		weight int64
+	}{
+		{"GET", "/api/v3/ticker/price?symbol=ETHBTC", 1},
+		{"GET", "/api/v3/ticker/price", 2},
+		{"GET", "/api/v3/depth?symbol=ETHBTC&limit=1000", 10},
+		{"GET", "/api/v3/openOrders", 40},
+		{"GET", "/api/v3/myTrades?symbol=ETHBTC", 10},
+		{"POST", "/api/v3/order", 1},
+	}
+	for _, test := range tests {
+		u, _ := url.Parse(API_ROOT + test.url)
+		request := &http.Request{Method: test.method, URL: u}
+		if weight := requestWeight(request); weight != test.weight {
+			t.Errorf("%s %s: expected weight %d, got %d", test.method,
+				test.url, test.weight, weight)
+		}
+	}
+}
+
+func TestRateLimiterUpdateFromHeaders(t *testing.T) {
+	limiter := NewDefaultRateLimiter()
+	response := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+	}
+	response.Header.Set("X-MBX-USED-WEIGHT-1M", "1100")
+	limiter.Update(nil, response)
+	if used := limiter.UsedWeight()[time.Minute]; used != 1100 {
+		t.Fatalf("expected used weight of 1100, got %d", used)
+	}
+}
+
+func TestRateLimiterHoldsOffAfterBan(t *testing.T) {
+	limiter := NewDefaultRateLimiter()
+	now := time.Date(2018, 5, 1, 12, 0, 10, 0, time.UTC)
+	limiter.now = func() time.Time {
+		return now
+	}
+	var slept time.Duration
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	response := &http.Response{
+		StatusCode: http.StatusTeapot,
+		Header:     http.Header{},
+	}
+	response.Header.Set("X-MBX-USED-WEIGHT-1M", "300")
+	response.Header.Set("Retry-After", "120")
+	limiter.Update(nil, response)
+
+	// The usage headers must not undo the hold-off.
+	if used := limiter.UsedWeight()[time.Minute]; used != 1200 {
+		t.Fatalf("expected used weight of 1200, got %d", used)
+	}
+
+	// Let the weight window roll over, leaving only the Retry-After wait.
+	response = &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+	}
+	response.Header.Set("X-MBX-USED-WEIGHT-1M", "0")
+	limiter.Update(nil, response)
+
+	u, _ := url.Parse(API_ROOT + "/api/v3/ticker/price?symbol=ETHBTC")
+	limiter.Wait(&http.Request{Method: "GET", URL: u})
+	if slept < 2*time.Minute {
+		t.Fatalf("expected to wait at least 2m, waited %s", slept)
+	}
+}
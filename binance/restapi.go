@@ -30,25 +30,10 @@ import (
 	"fmt"
 	"encoding/json"
 	"bytes"
+	"strconv"
+	"time"
 )
 
-type RestApiError struct {
-	StatusCode int
-	Body       []byte
-}
-
-func NewRestApiErrorFromResponse(r *http.Response) *RestApiError {
-	body, _ := ioutil.ReadAll(r.Body)
-	return &RestApiError{
-		StatusCode: r.StatusCode,
-		Body:       body,
-	}
-}
-
-func (e *RestApiError) Error() string {
-	return string(e.Body)
-}
-
 type UserDataStreamResponse struct {
 	ListenKey string `json:"listenKey"`
 }
@@ -87,6 +72,23 @@ func (c *RestClient) PutUserStreamKeepAlive(listenKey string) error {
 	return nil
 }
 
+// DeleteUserDataStream closes a user data stream.
+func (c *RestClient) DeleteUserDataStream(listenKey string) error {
+	queryString := c.BuildQueryString(map[string]interface{}{
+		"listenKey": listenKey,
+	})
+	path := fmt.Sprintf("/api/v1/userDataStream?%s", queryString)
+	httpResponse, err := c.DoDelete(path)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return NewRestApiErrorFromResponse(httpResponse)
+	}
+	return nil
+}
+
 type OrderSide string
 
 const (
@@ -97,10 +99,45 @@ const (
 type OrderType string
 
 const (
-	OrderTypeLimit  OrderType = "LIMIT"
-	OrderTypeMarket OrderType = "MARKET"
+	OrderTypeLimit           OrderType = "LIMIT"
+	OrderTypeMarket          OrderType = "MARKET"
+	OrderTypeStopLoss        OrderType = "STOP_LOSS"
+	OrderTypeStopLossLimit   OrderType = "STOP_LOSS_LIMIT"
+	OrderTypeTakeProfit      OrderType = "TAKE_PROFIT"
+	OrderTypeTakeProfitLimit OrderType = "TAKE_PROFIT_LIMIT"
+	OrderTypeLimitMaker      OrderType = "LIMIT_MAKER"
 )
 
+// IsLimit returns true if the order type requires a limit price.
+func (t OrderType) IsLimit() bool {
+	switch t {
+	case OrderTypeLimit, OrderTypeStopLossLimit, OrderTypeTakeProfitLimit,
+		OrderTypeLimitMaker:
+		return true
+	}
+	return false
+}
+
+// IsStop returns true if the order type requires a stop price.
+func (t OrderType) IsStop() bool {
+	switch t {
+	case OrderTypeStopLoss, OrderTypeStopLossLimit, OrderTypeTakeProfit,
+		OrderTypeTakeProfitLimit:
+		return true
+	}
+	return false
+}
+
+// requiresTimeInForce returns true if the order type requires a time in
+// force. LIMIT_MAKER orders are always GTC and must not send one.
+func (t OrderType) requiresTimeInForce() bool {
+	switch t {
+	case OrderTypeLimit, OrderTypeStopLossLimit, OrderTypeTakeProfitLimit:
+		return true
+	}
+	return false
+}
+
 type TimeInForce string
 
 const (
@@ -117,6 +154,33 @@ const (
 	OrderStatusCanceled        OrderStatus = "CANCELED"
 	OrderStatusFilled          OrderStatus = "FILLED"
 	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusPendingCancel   OrderStatus = "PENDING_CANCEL"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+
+	// Execution type of an execution report for a fill.
+	ExecutionTypeTrade OrderStatus = "TRADE"
+)
+
+// IsTerminal returns true if an order with this status can no longer
+// change.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusCanceled, OrderStatusFilled, OrderStatusRejected,
+		OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+// Response type for new orders. ACK returns only the order IDs, RESULT adds
+// the order status and executed quantities, and FULL also adds the fills.
+type OrderResponseType string
+
+const (
+	OrderResponseTypeAck    OrderResponseType = "ACK"
+	OrderResponseTypeResult OrderResponseType = "RESULT"
+	OrderResponseTypeFull   OrderResponseType = "FULL"
 )
 
 type OrderParameters struct {
@@ -127,52 +191,244 @@ type OrderParameters struct {
 	Quantity         float64
 	Price            float64
 	NewClientOrderId string
+
+	// Trigger price for STOP_LOSS, STOP_LOSS_LIMIT, TAKE_PROFIT and
+	// TAKE_PROFIT_LIMIT orders.
+	StopPrice float64
+
+	// Visible quantity of an iceberg order. Only valid for GTC limit orders
+	// on symbols that allow icebergs.
+	IcebergQty float64
+
+	// For MARKET orders, the amount of the quote asset to spend or receive
+	// instead of a base asset Quantity.
+	QuoteOrderQty float64
+
+	// The response type to request. If not set Binance defaults to FULL for
+	// MARKET and LIMIT orders, and ACK for other order types.
+	NewOrderRespType OrderResponseType
+
+	// Overrides the recvWindow of the client for this order if set.
+	RecvWindow time.Duration
+}
+
+// A fill (trade) of a new order, only present in FULL responses.
+type OrderFill struct {
+	TradeID         int64   `json:"tradeId"`
+	Price           float64 `json:"price,string"`
+	Quantity        float64 `json:"qty,string"`
+	Commission      float64 `json:"commission,string"`
+	CommissionAsset string  `json:"commissionAsset"`
 }
 
-// TODO: Implement RESULT and FULL response types. Currently only ACK implemented.
+// PostOrderResponse is the response to a new order. Fields other than the
+// IDs and transaction time are only set for RESULT and FULL responses, and
+// Fills only for FULL responses.
 type PostOrderResponse struct {
-	Symbol                string `json:"symbol"`
-	OrderId               int64  `json:"orderId"`
-	ClientOrderId         string `json:"clientOrderId"`
-	TransactionTimeMillis int64  `json:"transactTime"`
+	Symbol                string      `json:"symbol"`
+	OrderId               int64       `json:"orderId"`
+	ClientOrderId         string      `json:"clientOrderId"`
+	TransactionTimeMillis int64       `json:"transactTime"`
+	Price                 float64     `json:"price,string"`
+	OrigQty               float64     `json:"origQty,string"`
+	ExecutedQty           float64     `json:"executedQty,string"`
+	CumulativeQuoteQty    float64     `json:"cummulativeQuoteQty,string"`
+	Status                OrderStatus `json:"status"`
+	TimeInForce           TimeInForce `json:"timeInForce"`
+	Type                  OrderType   `json:"type"`
+	Side                  OrderSide   `json:"side"`
+	StopPrice             float64     `json:"stopPrice,string"`
+	IcebergQty            float64     `json:"icebergQty,string"`
+	Fills                 []OrderFill `json:"fills"`
+}
+
+// AveragePrice returns the average execution price of the order, or 0 if
+// nothing has been executed.
+func (r *PostOrderResponse) AveragePrice() float64 {
+	if r.ExecutedQty == 0 {
+		return 0
+	}
+	return r.CumulativeQuoteQty / r.ExecutedQty
+}
+
+// Commissions returns the total commission paid by the fills of the order
+// for each commission asset.
+func (r *PostOrderResponse) Commissions() map[string]float64 {
+	commissions := map[string]float64{}
+	for _, fill := range r.Fills {
+		commissions[fill.CommissionAsset] += fill.Commission
+	}
+	return commissions
+}
+
+// Validate checks that the parameters required by the order type are
+// present.
+func (o *OrderParameters) Validate() error {
+	if o.Symbol == "" {
+		return fmt.Errorf("symbol required")
+	}
+	if o.Side != OrderSideBuy && o.Side != OrderSideSell {
+		return fmt.Errorf("invalid side: %s", o.Side)
+	}
+	switch o.Type {
+	case OrderTypeLimit, OrderTypeMarket, OrderTypeStopLoss,
+		OrderTypeStopLossLimit, OrderTypeTakeProfit,
+		OrderTypeTakeProfitLimit, OrderTypeLimitMaker:
+	default:
+		return fmt.Errorf("invalid order type: %s", o.Type)
+	}
+	if o.Type == OrderTypeMarket {
+		if o.Quantity <= 0 && o.QuoteOrderQty <= 0 {
+			return fmt.Errorf("%s order requires quantity or quote order quantity", o.Type)
+		}
+		if o.Quantity > 0 && o.QuoteOrderQty > 0 {
+			return fmt.Errorf("%s order requires only one of quantity and quote order quantity", o.Type)
+		}
+	} else {
+		if o.Quantity <= 0 {
+			return fmt.Errorf("%s order requires a quantity", o.Type)
+		}
+		if o.QuoteOrderQty > 0 {
+			return fmt.Errorf("%s order does not support quote order quantity", o.Type)
+		}
+	}
+	if o.Type.IsLimit() && o.Price <= 0 {
+		return fmt.Errorf("%s order requires a price", o.Type)
+	}
+	if o.Type.IsStop() && o.StopPrice <= 0 {
+		return fmt.Errorf("%s order requires a stop price", o.Type)
+	}
+	if o.Type.requiresTimeInForce() && o.TimeInForce == "" {
+		return fmt.Errorf("%s order requires a time in force", o.Type)
+	}
+	if o.IcebergQty > 0 {
+		if !o.Type.requiresTimeInForce() && o.Type != OrderTypeLimitMaker {
+			return fmt.Errorf("%s order does not support iceberg quantity", o.Type)
+		}
+		if o.TimeInForce != "" && o.TimeInForce != TimeInForceGTC {
+			return fmt.Errorf("iceberg orders require time in force %s", TimeInForceGTC)
+		}
+	}
+	return nil
 }
 
-func (c *RestClient) PostOrder(order OrderParameters) (*http.Response, error) {
+// PostOrder places a new order. With an exchange info service the order is
+// normalized and checked against the symbol filters first. The
+// MAX_NUM_ORDERS and MAX_NUM_ALGO_ORDERS filters are not checked as that
+// needs the open orders of the symbol; callers that track them can use
+// ExchangeInfoService.ValidateOpenOrderCount.
+func (c *RestClient) PostOrder(order OrderParameters) (*PostOrderResponse, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	if c.exchangeInfo != nil {
+		if err := c.exchangeInfo.ValidateOrderType(order.Symbol, order.Type,
+			order.IcebergQty > 0); err != nil {
+			return nil, err
+		}
+		if err := c.applyOrderFilters(&order); err != nil {
+			return nil, err
+		}
+	}
+
 	params := map[string]interface{}{}
 	params["symbol"] = order.Symbol
 	params["side"] = order.Side
 	params["type"] = order.Type
-	params["quantity"] = order.Quantity
-
-	switch order.Type {
-	case OrderTypeMarket:
-	default:
-		params["price"] = fmt.Sprintf("%.8f", order.Price)
+	if order.Quantity > 0 {
+		params["quantity"] = formatDecimal(order.Quantity)
+	}
+	if order.QuoteOrderQty > 0 {
+		params["quoteOrderQty"] = formatDecimal(order.QuoteOrderQty)
+	}
+	if order.Type.IsLimit() {
+		params["price"] = formatDecimal(order.Price)
 	}
-	params["newClientOrderId"] = order.NewClientOrderId
-	if order.TimeInForce != "" {
+	if order.Type.IsStop() {
+		params["stopPrice"] = formatDecimal(order.StopPrice)
+	}
+	if order.IcebergQty > 0 {
+		params["icebergQty"] = formatDecimal(order.IcebergQty)
+	}
+	if order.NewClientOrderId != "" {
+		params["newClientOrderId"] = order.NewClientOrderId
+	}
+	if order.TimeInForce != "" && order.Type != OrderTypeLimitMaker {
 		params["timeInForce"] = order.TimeInForce
 	}
+	if order.NewOrderRespType != "" {
+		params["newOrderRespType"] = order.NewOrderRespType
+	}
+	if order.RecvWindow > 0 {
+		params["recvWindow"] = int64(order.RecvWindow / time.Millisecond)
+	}
 
-	response, err := c.Post("/api/v3/order", params)
+	httpResponse, err := c.Post("/api/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
-	if response.StatusCode >= 400 {
-		return response, NewRestApiErrorFromResponse(response)
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode >= 400 {
+		return nil, NewRestApiErrorFromResponse(httpResponse)
 	}
-	return response, nil
+
+	var response PostOrderResponse
+	if _, err := c.decodeBody(httpResponse, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// applyOrderFilters normalizes orders of the same symbol then checks them
+// against the symbol filters, fetching the average price once if any order
+// needs a reference price. Must only be called with an exchange info
+// service.
+func (c *RestClient) applyOrderFilters(orders ...*OrderParameters) error {
+	referencePrice := 0.0
+	for _, order := range orders {
+		if err := c.exchangeInfo.NormalizeOrder(order); err != nil {
+			return err
+		}
+		info, err := c.exchangeInfo.GetSymbol(order.Symbol)
+		if err != nil {
+			return err
+		}
+		if referencePrice == 0 && info.NeedsReferencePrice(*order) {
+			avgPrice, err := c.GetAvgPrice(order.Symbol)
+			if err != nil {
+				return err
+			}
+			referencePrice = avgPrice.Price
+		}
+		if err := info.ValidateOrder(*order, referencePrice); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (c *RestClient) CancelOrder(symbol string, orderId int64) (*CancelOrderResponse, error) {
 	params := map[string]interface{}{}
 	params["symbol"] = symbol
 	params["orderId"] = orderId
+	return c.cancelOrder(params)
+}
+
+// CancelOrderByClientId cancels an order by the client order ID it was
+// placed with.
+func (c *RestClient) CancelOrderByClientId(symbol string, clientId string) (*CancelOrderResponse, error) {
+	params := map[string]interface{}{}
+	params["symbol"] = symbol
+	params["origClientOrderId"] = clientId
+	return c.cancelOrder(params)
+}
 
+func (c *RestClient) cancelOrder(params map[string]interface{}) (*CancelOrderResponse, error) {
 	httpResponse, err := c.Delete("/api/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
+	defer httpResponse.Body.Close()
 
 	if httpResponse.StatusCode != http.StatusOK {
 		return nil, NewRestApiErrorFromResponse(httpResponse)
@@ -190,12 +446,39 @@ func (c *RestClient) CancelOrder(symbol string, orderId int64) (*CancelOrderResp
 	return &cancelOrderResponse, nil
 }
 
+// CancelAllOpenOrders cancels all the open orders of a symbol, including
+// order lists.
+func (c *RestClient) CancelAllOpenOrders(symbol string) ([]CancelOrderResponse, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	httpResponse, err := c.Delete("/api/v3/openOrders", params)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, NewRestApiErrorFromResponse(httpResponse)
+	}
+	var response []CancelOrderResponse
+	if _, err := c.decodeBody(httpResponse, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetExchangeInfo returns the exchange info using an anonymous client with
+// the default settings.
 func GetExchangeInfo() (*ExchangeInfoResponse, error) {
-	client := NewAnonymousClient()
-	response, err := client.GetWithAuth("/api/v1/exchangeInfo", nil)
+	return NewAnonymousClient().GetExchangeInfo()
+}
+
+func (c *RestClient) GetExchangeInfo() (*ExchangeInfoResponse, error) {
+	response, err := c.Get("/api/v1/exchangeInfo", nil)
 	if err != nil {
 		return nil, err
 	}
+	defer response.Body.Close()
 	if response.StatusCode != http.StatusOK {
 		return nil, NewRestApiErrorFromResponse(response)
 	}
@@ -257,6 +540,92 @@ func (c *RestClient) GetOrderByOrderId(symbol string, orderId int64) (QueryOrder
 	return response, nil
 }
 
+// GetOpenOrders returns the open orders of a symbol, or of all symbols if
+// symbol is empty. Requesting all symbols has a much higher request weight.
+func (c *RestClient) GetOpenOrders(symbol string) ([]QueryOrderResponse, error) {
+	params := map[string]interface{}{}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+	var response []QueryOrderResponse
+	if err := c.genericGetWithAuthAndDecode("/api/v3/openOrders", params, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// The maximum number of orders returned by one allOrders call.
+const MaxAllOrdersLimit = 1000
+
+// GetAllOrdersPage returns up to limit orders of a symbol, open or not,
+// starting at fromOrderId if not -1, or between start and end. If both
+// start and end are set they must be less than 24 hours apart.
+func (c *RestClient) GetAllOrdersPage(symbol string, fromOrderId int64, start time.Time, end time.Time, limit int) ([]QueryOrderResponse, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	if fromOrderId > -1 {
+		params["orderId"] = fromOrderId
+	}
+	if !start.IsZero() {
+		params["startTime"] = start.UnixNano() / int64(time.Millisecond)
+	}
+	if !end.IsZero() {
+		params["endTime"] = end.UnixNano() / int64(time.Millisecond)
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	var response []QueryOrderResponse
+	if err := c.genericGetWithAuthAndDecode("/api/v3/allOrders", params, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetAllOrders returns all the orders of a symbol created between start and
+// end, paging through them as needed. A zero start returns orders from the
+// first one, and a zero end up to the last one.
+func (c *RestClient) GetAllOrders(symbol string, start time.Time, end time.Time) ([]QueryOrderResponse, error) {
+	// The first page is found with a single query, from order ID 0 or,
+	// with only a start time, from the first order at or after start. The
+	// rest are fetched by ID.
+	fromOrderId := int64(-1)
+	if start.IsZero() {
+		fromOrderId = 0
+	}
+	orders, err := c.GetAllOrdersPage(symbol, fromOrderId, start, time.Time{}, MaxAllOrdersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	endMillis := int64(-1)
+	if !end.IsZero() {
+		endMillis = end.UnixNano() / int64(time.Millisecond)
+	}
+	inRange := func(order QueryOrderResponse) bool {
+		return endMillis < 0 || order.TimeMillis <= endMillis
+	}
+
+	result := []QueryOrderResponse{}
+	for {
+		for _, order := range orders {
+			if !inRange(order) {
+				return result, nil
+			}
+			result = append(result, order)
+		}
+		if len(orders) < MaxAllOrdersLimit {
+			return result, nil
+		}
+		orders, err = c.GetAllOrdersPage(symbol, orders[len(orders)-1].OrderId+1,
+			time.Time{}, time.Time{}, MaxAllOrdersLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
 func (c *RestClient) GetOrderByClientId(symbol string, clientId string) (QueryOrderResponse, error) {
 	var response QueryOrderResponse
 	params := map[string]interface{}{
@@ -296,6 +665,18 @@ func (c *RestClient) GetPriceTicker(symbol string) (PriceTickerResponse, error)
 	return response, err
 }
 
+// GetAvgPrice returns the average price of a symbol over the period used
+// by the PERCENT_PRICE filter.
+func (c *RestClient) GetAvgPrice(symbol string) (AvgPriceResponse, error) {
+	endpoint := "/api/v3/avgPrice"
+	var response AvgPriceResponse
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	err := c.genericGetAndDecode(endpoint, params, &response)
+	return response, err
+}
+
 func (c *RestClient) GetOrderBookTicker(symbol string) (OrderBookTickerResponse, error) {
 	endpoint := "/api/v3/ticker/bookTicker"
 	params := map[string]interface{}{
@@ -306,6 +687,59 @@ func (c *RestClient) GetOrderBookTicker(symbol string) (OrderBookTickerResponse,
 	return response, err
 }
 
+// Return the best bid and ask for all symbols.
+func (c *RestClient) GetAllOrderBookTickers() ([]OrderBookTickerResponse, error) {
+	endpoint := "/api/v3/ticker/bookTicker"
+	var response []OrderBookTickerResponse
+	err := c.genericGetAndDecode(endpoint, nil, &response)
+	return response, err
+}
+
+// GetDepth returns the order book for a symbol. Valid limits are 5, 10, 20,
+// 50, 100, 500, 1000 and 5000, or 0 for the default of 100.
+func (c *RestClient) GetDepth(symbol string, limit int) (*DepthResponse, error) {
+	endpoint := "/api/v3/depth"
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	var response DepthResponse
+	if err := c.genericGetAndDecode(endpoint, params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// The maximum number of klines returned by one GetKlines call.
+const MaxKlinesLimit = 1000
+
+// GetKlines returns the klines of a symbol for an interval such as 1m, 1h or
+// 1d, oldest first. A zero start or end leaves the range open on that side,
+// and a zero limit uses the server default of 500.
+func (c *RestClient) GetKlines(symbol string, interval string, start time.Time, end time.Time, limit int) ([]Kline, error) {
+	endpoint := "/api/v3/klines"
+	params := map[string]interface{}{
+		"symbol":   symbol,
+		"interval": interval,
+	}
+	if !start.IsZero() {
+		params["startTime"] = start.UnixNano() / int64(time.Millisecond)
+	}
+	if !end.IsZero() {
+		params["endTime"] = end.UnixNano() / int64(time.Millisecond)
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	var response []Kline
+	if err := c.genericGetAndDecode(endpoint, params, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (c *RestClient) GetMytrades(symbol string, limit int64, fromId int64) ([]TradeResponse, error) {
 	endpoint := "/api/v3/myTrades"
 	params := map[string]interface{}{
@@ -351,3 +785,9 @@ func (c *RestClient) genericGetAndDecode(endpoint string, params map[string]inte
 	decoder := json.NewDecoder(httpResponse.Body)
 	return decoder.Decode(response)
 }
+
+// formatDecimal formats a price or quantity without an exponent, as
+// required by the API.
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
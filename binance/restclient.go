@@ -35,6 +35,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"github.com/khayrullo/cryptotrader/core"
 )
 
 const API_ROOT = "https://api.binance.com"
@@ -46,26 +47,125 @@ type restClientAuth struct {
 
 type RestClient struct {
 	auth *restClientAuth
+	core.HTTPClient
+
+	exchangeInfo *ExchangeInfoService
+	timeService  *ServerTimeService
+	timeSync     bool
+	recvWindow   time.Duration
+}
+
+// RestClientOption configures optional settings of a RestClient.
+type RestClientOption func(*RestClient)
+
+// WithBaseURL is core.WithBaseURL, for example to point the client at the
+// Binance testnet. The default is API_ROOT.
+func WithBaseURL(baseURL string) RestClientOption {
+	return withHTTPOption(core.WithBaseURL(baseURL))
+}
+
+// WithHTTPClient is core.WithHTTPClient.
+func WithHTTPClient(httpClient *http.Client) RestClientOption {
+	return withHTTPOption(core.WithHTTPClient(httpClient))
+}
+
+// WithUserAgent is core.WithUserAgent.
+func WithUserAgent(userAgent string) RestClientOption {
+	return withHTTPOption(core.WithUserAgent(userAgent))
+}
+
+// WithTimeout is core.WithTimeout.
+func WithTimeout(timeout time.Duration) RestClientOption {
+	return withHTTPOption(core.WithTimeout(timeout))
 }
 
-func NewAnonymousClient() *RestClient {
-	return &RestClient{
+// WithExchangeInfoService validates orders against the symbol information
+// of the service before they are sent. Prices and quantities are rounded to
+// the tick and step sizes of the symbol, then checked against its filters.
+// The service must already be updated.
+func WithExchangeInfoService(service *ExchangeInfoService) RestClientOption {
+	return func(c *RestClient) {
+		c.exchangeInfo = service
 	}
 }
 
-func NewAuthenticatedClient(key string, secret string) *RestClient {
-	return &RestClient{
-		auth: &restClientAuth{
-			ApiKey:    key,
-			ApiSecret: secret,
-		},
+// WithRateLimiter is core.WithRateLimiter, usually with a RateLimiter from
+// this package.
+func WithRateLimiter(rateLimiter core.RateLimiter) RestClientOption {
+	return withHTTPOption(core.WithRateLimiter(rateLimiter))
+}
+
+func withHTTPOption(opt core.HTTPOption) RestClientOption {
+	return func(c *RestClient) {
+		opt(&c.HTTPClient)
 	}
 }
 
+// WithRecvWindow sets how long after its timestamp a signed request is
+// still valid. The default is DefaultRecvWindow. It can be overridden for a
+// single request by setting recvWindow (in milliseconds) in the request
+// parameters.
+func WithRecvWindow(recvWindow time.Duration) RestClientOption {
+	return func(c *RestClient) {
+		c.recvWindow = recvWindow
+	}
+}
+
+// WithServerTimeService stamps signed requests with the server time as
+// estimated by the service instead of the local clock. The service may be
+// shared between clients.
+func WithServerTimeService(service *ServerTimeService) RestClientOption {
+	return func(c *RestClient) {
+		c.timeService = service
+	}
+}
+
+// WithServerTimeSync is like WithServerTimeService, using a service owned by
+// the client. The service is synced before the first signed request, and is
+// available from the ServerTimeService method for periodic syncing.
+func WithServerTimeSync() RestClientOption {
+	return func(c *RestClient) {
+		c.timeSync = true
+	}
+}
+
+func newRestClient(auth *restClientAuth, opts []RestClientOption) *RestClient {
+	client := &RestClient{
+		auth:       auth,
+		HTTPClient: core.NewHTTPClient(API_ROOT),
+		recvWindow: DefaultRecvWindow,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	if client.timeSync && client.timeService == nil {
+		client.timeService = NewServerTimeService(client)
+	}
+	return client
+}
+
+func NewAnonymousClient(opts ...RestClientOption) *RestClient {
+	return newRestClient(nil, opts)
+}
+
+func NewAuthenticatedClient(key string, secret string, opts ...RestClientOption) *RestClient {
+	return newRestClient(&restClientAuth{
+		ApiKey:    key,
+		ApiSecret: secret,
+	}, opts)
+}
+
+// ServerTimeService returns the time service of the client, or nil if it
+// uses the local clock.
+func (c *RestClient) ServerTimeService() *ServerTimeService {
+	return c.timeService
+}
+
+
 // Perform an unauthenticated GET request.
 func (c *RestClient) Get(endpoint string, params map[string]interface{}) (*http.Response, error) {
 
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
 	queryString := ""
 
 	if params == nil {
@@ -84,37 +184,19 @@ func (c *RestClient) Get(endpoint string, params map[string]interface{}) (*http.
 		return nil, err
 	}
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 // Perform a fully authenticated GET request.
 func (c *RestClient) GetWithAuth(endpoint string, params map[string]interface{}) (*http.Response, error) {
+	return c.doSigned("GET", endpoint, params)
+}
 
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
-	queryString := ""
-
-	if params == nil {
-		params = map[string]interface{}{}
-	}
-
-	if c.auth != nil {
-		params["recvWindow"] = 5000
-		params["timestamp"] = time.Now().UnixNano() / 1000000
-	}
-
-	if params != nil {
-		queryString = c.BuildQueryString(params)
-		if queryString != "" {
-			url = fmt.Sprintf("%s?%s", url, queryString)
-		}
-	}
-
-	if c.auth != nil {
-		mac := hmac.New(sha256.New, []byte(c.auth.ApiSecret))
-		mac.Write([]byte(queryString))
-		signature := hex.EncodeToString(mac.Sum(nil))
-		url = fmt.Sprintf("%s&signature=%s",
-			url, signature)
+// Send a GET request with only the API key and no other authentication.
+func (c *RestClient) GetWithApiKey(endpoint string, params map[string]interface{}) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
+	if queryString := c.BuildQueryString(params); queryString != "" {
+		url = fmt.Sprintf("%s?%s", url, queryString)
 	}
 
 	request, err := http.NewRequest("GET", url, nil)
@@ -126,22 +208,22 @@ func (c *RestClient) GetWithAuth(endpoint string, params map[string]interface{})
 		request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
 	}
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *RestClient) Post(endpoint string, params map[string]interface{}) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
+	return c.doSigned("POST", endpoint, params)
+}
+
+// Send a POST request with only the API key and no other authentication.
+func (c *RestClient) PostWithApiKey(endpoint string, params map[string]interface{}) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
 	queryString := ""
 
 	if params == nil {
 		params = map[string]interface{}{}
 	}
 
-	if c.auth != nil && c.auth.ApiSecret != "" {
-		params["recvWindow"] = 5000
-		params["timestamp"] = time.Now().UnixNano() / 1000000
-	}
-
 	if params != nil {
 		queryString = c.BuildQueryString(params)
 		if queryString != "" {
@@ -149,14 +231,6 @@ func (c *RestClient) Post(endpoint string, params map[string]interface{}) (*http
 		}
 	}
 
-	if c.auth != nil && c.auth.ApiSecret != "" {
-		mac := hmac.New(sha256.New, []byte(c.auth.ApiSecret))
-		mac.Write([]byte(queryString))
-		signature := hex.EncodeToString(mac.Sum(nil))
-		url = fmt.Sprintf("%s&signature=%s",
-			url, signature)
-	}
-
 	request, err := http.NewRequest("POST", url, nil)
 	if err != nil {
 		return nil, err
@@ -166,58 +240,71 @@ func (c *RestClient) Post(endpoint string, params map[string]interface{}) (*http
 		request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
 	}
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
-// Send a POST request with only the API key and no other authentication.
-func (c *RestClient) PostWithApiKey(endpoint string, params map[string]interface{}) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
-	queryString := ""
+func (c *RestClient) Delete(endpoint string, params map[string]interface{}) (*http.Response, error) {
+	return c.doSigned("DELETE", endpoint, params)
+}
 
+// Perform a request signed with the API secret, if the client has one. The
+// request is stamped with the server time when the client has a time
+// service, and is retried once after a re-sync if Binance still rejects the
+// timestamp. A recvWindow already in params is left as is, otherwise the
+// recvWindow of the client is used.
+func (c *RestClient) doSigned(method string, endpoint string, params map[string]interface{}) (*http.Response, error) {
 	if params == nil {
 		params = map[string]interface{}{}
 	}
 
-	if params != nil {
-		queryString = c.BuildQueryString(params)
-		if queryString != "" {
-			url = fmt.Sprintf("%s?%s", url, queryString)
+	if c.auth == nil || c.auth.ApiSecret == "" {
+		return c.doSignedOnce(method, endpoint, params)
+	}
+
+	if c.timeService != nil && c.timeService.LastSync().IsZero() {
+		if err := c.timeService.Sync(); err != nil {
+			return nil, fmt.Errorf("failed to sync server time: %v", err)
 		}
 	}
 
-	request, err := http.NewRequest("POST", url, nil)
-	if err != nil {
-		return nil, err
+	response, err := c.doSignedOnce(method, endpoint, params)
+	if err != nil || c.timeService == nil ||
+		response.StatusCode != http.StatusBadRequest {
+		return response, err
 	}
 
-	if c.auth != nil && c.auth.ApiKey != "" {
-		request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
+	apiError := NewRestApiErrorFromResponse(response)
+	response.Body.Close()
+	response.Body = ioutil.NopCloser(bytes.NewReader(apiError.Body))
+	if apiError.Code != ErrorCodeInvalidTimestamp {
+		return response, nil
 	}
 
-	return http.DefaultClient.Do(request)
+	// The estimate is too far off to be smoothed back into shape.
+	c.timeService.Reset()
+	if err := c.timeService.Sync(); err != nil {
+		return response, nil
+	}
+	return c.doSignedOnce(method, endpoint, params)
 }
 
-func (c *RestClient) Delete(endpoint string, params map[string]interface{}) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
-	queryString := ""
+func (c *RestClient) doSignedOnce(method string, endpoint string, params map[string]interface{}) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
+	signed := c.auth != nil && c.auth.ApiSecret != ""
 
-	if params == nil {
-		params = map[string]interface{}{}
+	if signed {
+		if _, ok := params["recvWindow"]; !ok {
+			params["recvWindow"] = int64(c.recvWindow / time.Millisecond)
+		}
+		params["timestamp"] = c.timestamp()
 	}
 
-	if c.auth != nil && c.auth.ApiSecret != "" {
-		params["recvWindow"] = 5000
-		params["timestamp"] = time.Now().UnixNano() / 1000000
+	queryString := c.BuildQueryString(params)
+	if queryString != "" {
+		url = fmt.Sprintf("%s?%s", url, queryString)
 	}
 
-	if params != nil {
-		queryString = c.BuildQueryString(params)
-		if queryString != "" {
-			url = fmt.Sprintf("%s?%s", url, queryString)
-		}
-	}
-
-	if c.auth != nil && c.auth.ApiSecret != "" {
+	if signed {
 		mac := hmac.New(sha256.New, []byte(c.auth.ApiSecret))
 		mac.Write([]byte(queryString))
 		signature := hex.EncodeToString(mac.Sum(nil))
@@ -225,7 +312,7 @@ func (c *RestClient) Delete(endpoint string, params map[string]interface{}) (*ht
 			url, signature)
 	}
 
-	request, err := http.NewRequest("DELETE", url, nil)
+	request, err := http.NewRequest(method, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -234,18 +321,39 @@ func (c *RestClient) Delete(endpoint string, params map[string]interface{}) (*ht
 		request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
 	}
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
+}
+
+// The current time in milliseconds, adjusted to the server clock if the
+// client has a time service.
+func (c *RestClient) timestamp() int64 {
+	now := time.Now()
+	if c.timeService != nil {
+		now = c.timeService.Now()
+	}
+	return now.UnixNano() / int64(time.Millisecond)
 }
 
 func (c *RestClient) DoPut(path string) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, path)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), path)
 	request, err := http.NewRequest("PUT", url, nil)
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
+}
+
+func (c *RestClient) DoDelete(path string) (*http.Response, error) {
+	url := fmt.Sprintf("%s%s", c.BaseURL(), path)
+	request, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Add("X-MBX-APIKEY", c.auth.ApiKey)
+
+	return c.Do(request)
 }
 
 func (c *RestClient) BuildQueryString(params map[string]interface{}) string {
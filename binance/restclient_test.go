@@ -0,0 +1,219 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestClientOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/ticker/price" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("User-Agent") != "cryptotrader-test" {
+			t.Errorf("unexpected user agent: %s", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(`{"symbol":"ETHBTC","price":"0.07000000"}`))
+	}))
+	defer server.Close()
+
+	client := NewAnonymousClient(
+		WithBaseURL(server.URL+"/"),
+		WithHTTPClient(server.Client()),
+		WithUserAgent("cryptotrader-test"))
+	ticker, err := client.GetPriceTicker("ETHBTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticker.Price != 0.07 {
+		t.Fatalf("expected price 0.07, got %f", ticker.Price)
+	}
+}
+
+func TestPostOrderFullResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("newOrderRespType") != "FULL" {
+			t.Errorf("expected newOrderRespType=FULL, got %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDT","orderId":28,"orderListId":-1,"clientOrderId":"6gCrw2kRUAF9CvJDGP16IP","transactTime":1507725176595,"price":"0.00000000","origQty":"10.00000000","executedQty":"10.00000000","cummulativeQuoteQty":"10.00000000","status":"FILLED","timeInForce":"GTC","type":"MARKET","side":"SELL","fills":[{"price":"4000.00000000","qty":"1.00000000","commission":"4.00000000","commissionAsset":"USDT","tradeId":56},{"price":"3999.00000000","qty":"5.00000000","commission":"19.99500000","commissionAsset":"USDT","tradeId":57}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	response, err := client.PostOrder(OrderParameters{
+		Symbol:           "BTCUSDT",
+		Side:             OrderSideSell,
+		Type:             OrderTypeMarket,
+		Quantity:         10,
+		NewOrderRespType: OrderResponseTypeFull,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Status != OrderStatusFilled {
+		t.Errorf("unexpected status: %s", response.Status)
+	}
+	if response.ExecutedQty != 10 {
+		t.Errorf("unexpected executed quantity: %f", response.ExecutedQty)
+	}
+	if len(response.Fills) != 2 || response.Fills[1].TradeID != 57 {
+		t.Fatalf("unexpected fills: %+v", response.Fills)
+	}
+	if commission := response.Commissions()["USDT"]; commission != 23.995 {
+		t.Errorf("unexpected commission: %f", commission)
+	}
+}
+
+func TestOrderParametersValidate(t *testing.T) {
+	valid := []OrderParameters{
+		{Symbol: "ETHBTC", Side: OrderSideBuy, Type: OrderTypeMarket, QuoteOrderQty: 0.1},
+		{Symbol: "ETHBTC", Side: OrderSideSell, Type: OrderTypeStopLossLimit, Quantity: 1,
+			Price: 0.05, StopPrice: 0.051, TimeInForce: TimeInForceGTC},
+		{Symbol: "ETHBTC", Side: OrderSideBuy, Type: OrderTypeLimitMaker, Quantity: 1,
+			Price: 0.05, IcebergQty: 0.1},
+	}
+	for _, order := range valid {
+		if err := order.Validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", order.Type, err)
+		}
+	}
+
+	invalid := []OrderParameters{
+		{Symbol: "ETHBTC", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1, QuoteOrderQty: 0.1},
+		{Symbol: "ETHBTC", Side: OrderSideSell, Type: OrderTypeTakeProfit, Quantity: 1},
+		{Symbol: "ETHBTC", Side: OrderSideBuy, Type: OrderTypeLimit, Quantity: 1, Price: 0.05},
+		{Symbol: "ETHBTC", Side: OrderSideBuy, Type: OrderTypeLimit, Quantity: 1, Price: 0.05,
+			TimeInForce: TimeInForceIOC, IcebergQty: 0.1},
+	}
+	for _, order := range invalid {
+		if err := order.Validate(); err == nil {
+			t.Errorf("%s: expected error for %+v", order.Type, order)
+		}
+	}
+}
+
+func TestGetAllOrdersPages(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		if query.Get("endTime") != "" {
+			t.Errorf("unexpected end time: %s", r.URL.RawQuery)
+		}
+		fromID, _ := strconv.Atoi(query.Get("orderId"))
+		if startTime := query.Get("startTime"); startTime != "" {
+			if query.Get("orderId") != "" {
+				t.Errorf("unexpected order ID with start time: %s", r.URL.RawQuery)
+			}
+			start, _ := strconv.Atoi(startTime)
+			fromID = (start + 999) / 1000
+		}
+		orders := []string{}
+		for id := fromID; id < 1500 && len(orders) < MaxAllOrdersLimit; id++ {
+			orders = append(orders, fmt.Sprintf(`{"symbol":"ETHBTC","orderId":%d,"status":"FILLED","time":%d}`, id, id*1000))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(orders, ","))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	orders, err := client.GetAllOrders("ETHBTC", time.Time{}, time.Unix(1200, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1201 || orders[1200].OrderId != 1200 {
+		t.Fatalf("expected orders 0 to 1200, got %d", len(orders))
+	}
+
+	// Orders start in the distant past, but the first one is still found
+	// with one request.
+	requests = 0
+	orders, err = client.GetAllOrders("ETHBTC", time.Unix(300, 0), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1200 || orders[0].OrderId != 300 || orders[1199].OrderId != 1499 {
+		t.Fatalf("expected orders 300 to 1499, got %d", len(orders))
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestCancelAllOpenOrders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/api/v3/openOrders" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`[{"symbol":"BTCUSDT","origClientOrderId":"E6APeyTJvkMvLMYMqu1KQ4","orderId":11,"orderListId":-1,"clientOrderId":"pXLV6Hz6mprAcVYpVMTGgx","price":"0.089853","origQty":"0.178622","executedQty":"0.000000","cummulativeQuoteQty":"0.000000","status":"CANCELED","timeInForce":"GTC","type":"LIMIT","side":"BUY"}]`))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	responses, err := client.CancelAllOpenOrders("BTCUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 1 || responses[0].Status != OrderStatusCanceled || responses[0].OrderID != 11 {
+		t.Fatalf("unexpected response: %+v", responses)
+	}
+}
+
+func TestPostOrderFilters(t *testing.T) {
+	var orders int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/avgPrice":
+			w.Write([]byte(`{"mins":5,"price":"0.07000000"}`))
+		default:
+			orders++
+			w.Write([]byte(`{"symbol":"ETHBTC","orderId":1}`))
+		}
+	}))
+	defer server.Close()
+
+	info := testSymbolInfo()
+	info.OrderTypes = []OrderType{OrderTypeLimit, OrderTypeMarket}
+	service := NewExchangeInfoService()
+	service.Symbols["ETHBTC"] = info
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL),
+		WithExchangeInfoService(service))
+
+	tests := []struct {
+		order  OrderParameters
+		filter string
+	}{
+		// Rounded down to 0 by the step size.
+		{OrderParameters{Symbol: "ETHBTC", Side: OrderSideBuy,
+			Type: OrderTypeLimit, TimeInForce: TimeInForceGTC,
+			Quantity: 0.0004, Price: 0.07}, FilterLotSize},
+		// More than 5 times the average price.
+		{OrderParameters{Symbol: "ETHBTC", Side: OrderSideBuy,
+			Type: OrderTypeLimit, TimeInForce: TimeInForceGTC,
+			Quantity: 1, Price: 0.5}, FilterPercentPrice},
+	}
+	for _, test := range tests {
+		_, err := client.PostOrder(test.order)
+		filterError, ok := err.(*FilterError)
+		if !ok || filterError.Filter != test.filter {
+			t.Errorf("expected %s filter error, got %v", test.filter, err)
+		}
+	}
+	if orders != 0 {
+		t.Fatalf("expected no orders to be sent, got %d", orders)
+	}
+
+	if _, err := client.PostOrder(OrderParameters{Symbol: "ETHBTC",
+		Side: OrderSideBuy, Type: OrderTypeLimit, TimeInForce: TimeInForceGTC,
+		Quantity: 1, Price: 0.07}); err != nil {
+		t.Fatal(err)
+	}
+	if orders != 1 {
+		t.Fatalf("expected 1 order to be sent, got %d", orders)
+	}
+}
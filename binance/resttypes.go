@@ -24,38 +24,81 @@
 
 package binance
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
 type SymbolFilterResponse struct {
-	FilterType  string  `json:"filterType"`
-	MinPrice    float64 `json:"minPrice,string"`
-	MaxPrice    float64 `json:"maxPrice,string"`
-	TickSize    float64 `json:"tickSize,string"`
-	MinQty      float64 `json:"minQty,string"`
-	MaxQty      float64 `json:"maxQty,string"`
-	StepSize    float64 `json:"stepSize,string"`
-	MinNotional float64 `json:"minNotional,string"`
+	FilterType       string  `json:"filterType"`
+	MinPrice         float64 `json:"minPrice,string"`
+	MaxPrice         float64 `json:"maxPrice,string"`
+	TickSize         float64 `json:"tickSize,string"`
+	MinQty           float64 `json:"minQty,string"`
+	MaxQty           float64 `json:"maxQty,string"`
+	StepSize         float64 `json:"stepSize,string"`
+	MinNotional      float64 `json:"minNotional,string"`
+	MaxNotional      float64 `json:"maxNotional,string"`
+	ApplyToMarket    bool    `json:"applyToMarket"`
+	ApplyMinToMarket bool    `json:"applyMinToMarket"`
+	MultiplierUp     float64 `json:"multiplierUp,string"`
+	MultiplierDown   float64 `json:"multiplierDown,string"`
+	AvgPriceMins     int64   `json:"avgPriceMins"`
+	MaxNumOrders     int64   `json:"maxNumOrders"`
+	MaxNumAlgoOrders int64   `json:"maxNumAlgoOrders"`
+	Limit            int64   `json:"limit"`
 }
 
 type SymbolInfoResponse struct {
 	Symbol              string                 `json:"symbol"`
 	Status              string                 `json:"status"`
 	BaseAsset           string                 `json:"baseAsset"`
-	BaseAssetPrecision  int64                  `json:"baseAssetPrecision`
+	BaseAssetPrecision  int64                  `json:"baseAssetPrecision"`
 	QuoteAsset          string                 `json:"quoteAsset"`
 	QuoteAssetPrecision int64                  `json:"quoteAssetPrecision"`
 	OrderTypes          []string               `json:"orderTypes"`
 	IcebergAllowed      bool                   `json:"icebergAllowed"`
+	OcoAllowed          bool                   `json:"ocoAllowed"`
 	Filters             []SymbolFilterResponse `json:"filters"`
 }
 
-type ExchangeInfoResponse struct {
-	Timezone         string `json:"timezone"`
-	ServerTimeMillis int64  `json:"serverTime"`
-	RateLimits []struct {
-		RateLimitType     string `json:"rateLimitType"`
-		RateLimitInterval string `json"rateLimitInterval"`
-		Limit             int64  `json"limit"`
+type RateLimit struct {
+	// REQUEST_WEIGHT, ORDERS or RAW_REQUESTS.
+	RateLimitType string `json:"rateLimitType"`
+
+	// SECOND, MINUTE, HOUR or DAY.
+	Interval    string `json:"interval"`
+	IntervalNum int64  `json:"intervalNum"`
+	Limit       int64  `json:"limit"`
+}
+
+// Duration returns the length of the rate limit interval.
+func (r RateLimit) Duration() time.Duration {
+	num := r.IntervalNum
+	if num < 1 {
+		num = 1
+	}
+	switch r.Interval {
+	case "SECOND":
+		return time.Duration(num) * time.Second
+	case "MINUTE":
+		return time.Duration(num) * time.Minute
+	case "HOUR":
+		return time.Duration(num) * time.Hour
+	case "DAY":
+		return time.Duration(num) * 24 * time.Hour
 	}
-	Symbols []SymbolInfoResponse `json:"symbols"`
+	return 0
+}
+
+type ExchangeInfoResponse struct {
+	Timezone         string               `json:"timezone"`
+	ServerTimeMillis int64                `json:"serverTime"`
+	RateLimits       []RateLimit          `json:"rateLimits"`
+	Symbols          []SymbolInfoResponse `json:"symbols"`
 
 	RawResponse []byte `json:"-"`
 }
@@ -65,6 +108,21 @@ type CancelOrderResponse struct {
 	OrigClientOrderID string `json:"origClientOrderId"`
 	OrderID           int64  `json:"orderId"`
 	ClientOrderID     string `json:"clientOrderId"`
+
+	// The state of the order after the cancel.
+	Price              float64     `json:"price,string"`
+	OrigQty            float64     `json:"origQty,string"`
+	ExecutedQty        float64     `json:"executedQty,string"`
+	CumulativeQuoteQty float64     `json:"cummulativeQuoteQty,string"`
+	Status             OrderStatus `json:"status"`
+	TimeInForce        TimeInForce `json:"timeInForce"`
+	Type               OrderType   `json:"type"`
+	Side               OrderSide   `json:"side"`
+
+	// Set for orders that are part of an order list, in which case the
+	// response for cancel-all is for the whole list.
+	OrderListID       int64  `json:"orderListId"`
+	ListClientOrderID string `json:"listClientOrderId"`
 }
 
 type AccountInfoBalance struct {
@@ -91,7 +149,7 @@ type QueryOrderResponse struct {
 	ClientOrderId string      `json:"clientOrderId"`
 	Price         float64     `json:"price,string"`
 	OrigQty       float64     `json:"origQty,string"`
-	ExecutedQty   float64     `json:"executeQty,string"`
+	ExecutedQty   float64     `json:"executedQty,string"`
 	Status        OrderStatus `json:"status"`
 	TimeInForce   TimeInForce `json:"timeInForce"`
 	Type          OrderType   `json:"type"`
@@ -100,6 +158,9 @@ type QueryOrderResponse struct {
 	IcebergQty    float64     `json:"icebergQty,string"`
 	TimeMillis    int64       `json:"time"`
 	IsWorking     bool        `json:"isWorking"`
+
+	CumulativeQuoteQty float64 `json:"cummulativeQuoteQty,string"`
+	UpdateTimeMillis   int64   `json:"updateTime"`
 }
 
 type PriceTickerResponse struct {
@@ -107,6 +168,11 @@ type PriceTickerResponse struct {
 	Price  float64 `json:"price,string"`
 }
 
+type AvgPriceResponse struct {
+	Mins  int64   `json:"mins"`
+	Price float64 `json:"price,string"`
+}
+
 type OrderBookTickerResponse struct {
 	Symbol   string  `json:"symbol"`
 	BidPrice float64 `json:"bidPrice,string"`
@@ -117,10 +183,12 @@ type OrderBookTickerResponse struct {
 
 // GET /api/v3/myTrades
 type TradeResponse struct {
+	Symbol          string  `json:"symbol"`
 	ID              int64   `json:"id"`
 	OrderID         int64   `json:"orderId"`
 	Price           float64 `json:"price,string"`
 	Quantity        float64 `json:"qty,string"`
+	QuoteQuantity   float64 `json:"quoteQty,string"`
 	Commission      float64 `json:"commission,string"`
 	CommissionAsset string  `json:"commissionAsset"`
 	TimeMillis      int64   `json:"time"`
@@ -128,3 +196,103 @@ type TradeResponse struct {
 	IsMaker         bool    `json:"isMaker"`
 	IsBestMatch     bool    `json:"isBestMatch"`
 }
+
+func (t TradeResponse) Time() time.Time {
+	return millisToTime(t.TimeMillis)
+}
+
+// DepthEntry is a single price level of an order book. Binance encodes these
+// as a ["price", "quantity"] array.
+type DepthEntry struct {
+	Price    float64
+	Quantity float64
+}
+
+func (e *DepthEntry) UnmarshalJSON(b []byte) error {
+	var raw []string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 2 {
+		return fmt.Errorf("invalid depth entry: %s", string(b))
+	}
+	var err error
+	if e.Price, err = strconv.ParseFloat(raw[0], 64); err != nil {
+		return err
+	}
+	if e.Quantity, err = strconv.ParseFloat(raw[1], 64); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GET /api/v3/depth
+type DepthResponse struct {
+	LastUpdateID int64        `json:"lastUpdateId"`
+	Bids         []DepthEntry `json:"bids"`
+	Asks         []DepthEntry `json:"asks"`
+}
+
+// A kline (candlestick) from GET /api/v3/klines. Binance encodes these as an
+// array of values.
+type Kline struct {
+	OpenTimeMillis      int64
+	Open                float64
+	High                float64
+	Low                 float64
+	Close               float64
+	Volume              float64
+	CloseTimeMillis     int64
+	QuoteVolume         float64
+	NumberOfTrades      int64
+	TakerBuyBaseVolume  float64
+	TakerBuyQuoteVolume float64
+}
+
+func (k *Kline) UnmarshalJSON(b []byte) error {
+	var raw []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+	if len(raw) < 11 {
+		return fmt.Errorf("invalid kline: %s", string(b))
+	}
+
+	ints := []*int64{&k.OpenTimeMillis, &k.CloseTimeMillis, &k.NumberOfTrades}
+	for i, index := range []int{0, 6, 8} {
+		number, ok := raw[index].(json.Number)
+		if !ok {
+			return fmt.Errorf("invalid kline: %s", string(b))
+		}
+		value, err := number.Int64()
+		if err != nil {
+			return err
+		}
+		*ints[i] = value
+	}
+
+	floats := []*float64{&k.Open, &k.High, &k.Low, &k.Close, &k.Volume,
+		&k.QuoteVolume, &k.TakerBuyBaseVolume, &k.TakerBuyQuoteVolume}
+	for i, index := range []int{1, 2, 3, 4, 5, 7, 9, 10} {
+		value, ok := raw[index].(string)
+		if !ok {
+			return fmt.Errorf("invalid kline: %s", string(b))
+		}
+		var err error
+		if *floats[i], err = strconv.ParseFloat(value, 64); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (k *Kline) OpenTime() time.Time {
+	return time.Unix(0, k.OpenTimeMillis*int64(time.Millisecond))
+}
+
+func (k *Kline) CloseTime() time.Time {
+	return time.Unix(0, k.CloseTimeMillis*int64(time.Millisecond))
+}
@@ -29,16 +29,85 @@ import (
 	"strings"
 	"fmt"
 	"net/http"
+	"encoding/json"
+	"bytes"
+	"sync"
+	"time"
+	"errors"
 )
 
 const WS_STREAM_URL = "wss://stream.binance.com:9443"
 
+// The time to wait for the response to a SUBSCRIBE, UNSUBSCRIBE or
+// LIST_SUBSCRIPTIONS request.
+const DefaultStreamMethodTimeout = 10 * time.Second
+
+var ErrStreamMethodTimeout = errors.New("timeout waiting for stream method response")
+
 type StreamClient struct {
 	Conn *websocket.Conn
+
+	url           string
+	dialer        *websocket.Dialer
+	methodTimeout time.Duration
+
+	writeLock sync.Mutex
+
+	lock    sync.Mutex
+	nextID  int64
+	pending map[int64]chan streamMethodResponse
+}
+
+// An error returned by the server in response to a stream method request.
+type StreamMethodError struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+}
+
+func (e *StreamMethodError) Error() string {
+	return fmt.Sprintf("%d: %s", e.Code, e.Msg)
+}
+
+type streamMethodRequest struct {
+	Method string      `json:"method"`
+	Params interface{} `json:"params,omitempty"`
+	ID     int64       `json:"id"`
+}
+
+type streamMethodResponse struct {
+	ID     *int64             `json:"id"`
+	Result json.RawMessage    `json:"result"`
+	Error  *StreamMethodError `json:"error"`
+}
+
+// StreamClientOption configures optional settings of a StreamClient.
+type StreamClientOption func(*StreamClient)
+
+// WithStreamURL sets the websocket root URL. The default is WS_STREAM_URL.
+func WithStreamURL(url string) StreamClientOption {
+	return func(c *StreamClient) {
+		c.url = strings.TrimRight(url, "/")
+	}
+}
+
+// WithStreamMethodTimeout sets the time to wait for the response to a
+// subscription request. The default is DefaultStreamMethodTimeout.
+func WithStreamMethodTimeout(timeout time.Duration) StreamClientOption {
+	return func(c *StreamClient) {
+		c.methodTimeout = timeout
+	}
+}
+
+// WithDialer sets the websocket.Dialer used to connect. The default is
+// websocket.DefaultDialer.
+func WithDialer(dialer *websocket.Dialer) StreamClientOption {
+	return func(c *StreamClient) {
+		c.dialer = dialer
+	}
 }
 
-func OpenSingleStream(stream string) (*StreamClient, error) {
-	client := NewStreamClient()
+func OpenSingleStream(stream string, opts ...StreamClientOption) (*StreamClient, error) {
+	client := NewStreamClient(opts...)
 	err := client.ConnectSingle(stream)
 	if err != nil {
 		return nil, err
@@ -46,20 +115,33 @@ func OpenSingleStream(stream string) (*StreamClient, error) {
 	return client, nil
 }
 
-func NewStreamClient() *StreamClient {
-	client := &StreamClient{}
+func NewStreamClient(opts ...StreamClientOption) *StreamClient {
+	client := &StreamClient{
+		url:           WS_STREAM_URL,
+		dialer:        websocket.DefaultDialer,
+		methodTimeout: DefaultStreamMethodTimeout,
+		pending:       map[int64]chan streamMethodResponse{},
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
 	return client
 }
 
+// Connect to the combined stream endpoint. Streams may also be added later
+// with Subscribe.
 func (c *StreamClient) Connect(streams ... string) (err error) {
-	path := fmt.Sprintf("stream?streams=%s", strings.Join(streams, "/"))
-	c.Conn, err = openStream(path)
+	path := "stream"
+	if len(streams) > 0 {
+		path = fmt.Sprintf("stream?streams=%s", strings.Join(streams, "/"))
+	}
+	c.Conn, err = c.openStream(path)
 	return err
 }
 
 func (c *StreamClient) ConnectSingle(stream string) (err error) {
 	path := fmt.Sprintf("ws/%s", stream)
-	c.Conn, err = openStream(path)
+	c.Conn, err = c.openStream(path)
 	return err
 }
 
@@ -67,20 +149,122 @@ func (c *StreamClient) Close() {
 	c.Conn.Close()
 }
 
+// Next reads the next stream message. Responses to Subscribe, Unsubscribe
+// and ListSubscriptions are passed to the waiting caller and not returned,
+// so a goroutine must be reading for those calls to complete.
 func (c *StreamClient) Next() (messageType int, body []byte, err error) {
-	return c.Conn.ReadMessage()
+	for {
+		messageType, body, err = c.Conn.ReadMessage()
+		if err != nil {
+			return messageType, body, err
+		}
+		if !c.handleMethodResponse(body) {
+			return messageType, body, nil
+		}
+	}
 }
 
 // Next reads the next message into a generic map.
 func (c *StreamClient) NextJSON() (interface{}, error) {
+	_, body, err := c.Next()
+	if err != nil {
+		return nil, err
+	}
 	var message interface{}
-	err := c.Conn.ReadJSON(&message)
-	return message, err
+	if err := json.Unmarshal(body, &message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// Subscribe adds streams to the connection.
+func (c *StreamClient) Subscribe(streams ...string) error {
+	return c.call("SUBSCRIBE", streams, nil)
+}
+
+// Unsubscribe removes streams from the connection.
+func (c *StreamClient) Unsubscribe(streams ...string) error {
+	return c.call("UNSUBSCRIBE", streams, nil)
+}
+
+// ListSubscriptions returns the streams of the connection, including those
+// given to Connect.
+func (c *StreamClient) ListSubscriptions() ([]string, error) {
+	var streams []string
+	if err := c.call("LIST_SUBSCRIPTIONS", nil, &streams); err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+// call sends a method request and waits for the response with the same ID.
+func (c *StreamClient) call(method string, params interface{}, result interface{}) error {
+	c.lock.Lock()
+	c.nextID++
+	id := c.nextID
+	responseChannel := make(chan streamMethodResponse, 1)
+	c.pending[id] = responseChannel
+	c.lock.Unlock()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.pending, id)
+		c.lock.Unlock()
+	}()
+
+	c.writeLock.Lock()
+	err := c.Conn.WriteJSON(streamMethodRequest{
+		Method: method,
+		Params: params,
+		ID:     id,
+	})
+	c.writeLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case response := <-responseChannel:
+		if response.Error != nil {
+			return response.Error
+		}
+		if result != nil {
+			return json.Unmarshal(response.Result, result)
+		}
+		return nil
+	case <-time.After(c.methodTimeout):
+		return ErrStreamMethodTimeout
+	}
+}
+
+// handleMethodResponse passes body to the caller waiting for it if it is
+// a method response.
+func (c *StreamClient) handleMethodResponse(body []byte) bool {
+	// Stream payloads don't have an "id" field, so most are skipped without
+	// decoding.
+	if !bytes.Contains(body, []byte(`"id"`)) {
+		return false
+	}
+	var response streamMethodResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return false
+	}
+	if response.ID == nil || (response.Result == nil && response.Error == nil) {
+		return false
+	}
+
+	c.lock.Lock()
+	responseChannel, ok := c.pending[*response.ID]
+	c.lock.Unlock()
+	if ok {
+		responseChannel <- response
+	}
+	return true
 }
 
-func openStream(path string) (*websocket.Conn, error) {
-	url := fmt.Sprintf("%s/%s", WS_STREAM_URL, path)
-	ws, httpResponse, err := websocket.DefaultDialer.Dial(url, nil)
+func (c *StreamClient) openStream(path string) (*websocket.Conn, error) {
+	url := fmt.Sprintf("%s/%s", c.url, path)
+	ws, httpResponse, err := c.dialer.Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,105 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// A websocket server implementing the subscription methods.
+func newSubscriptionServer() *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		streams := []string{}
+		for {
+			var request struct {
+				Method string   `json:"method"`
+				Params []string `json:"params"`
+				ID     int64    `json:"id"`
+			}
+			if err := conn.ReadJSON(&request); err != nil {
+				return
+			}
+			// A stream message, to be delivered ahead of the response.
+			conn.WriteMessage(websocket.TextMessage,
+				[]byte(`{"stream":"ethbtc@trade","data":{"e":"trade","t":1}}`))
+			response := map[string]interface{}{"id": request.ID}
+			switch request.Method {
+			case "SUBSCRIBE":
+				if strings.HasPrefix(request.Params[0], "invalid") {
+					response["error"] = map[string]interface{}{
+						"code": 2, "msg": "Invalid request",
+					}
+					break
+				}
+				streams = append(streams, request.Params...)
+				response["result"] = nil
+			case "LIST_SUBSCRIPTIONS":
+				response["result"] = streams
+			}
+			conn.WriteJSON(response)
+		}
+	}))
+}
+
+func TestStreamClientSubscribe(t *testing.T) {
+	server := newSubscriptionServer()
+	defer server.Close()
+
+	client := NewStreamClient(WithStreamURL("ws" + strings.TrimPrefix(server.URL, "http")))
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	messages := make(chan []byte, 16)
+	go func() {
+		for {
+			_, body, err := client.Next()
+			if err != nil {
+				close(messages)
+				return
+			}
+			messages <- body
+		}
+	}()
+
+	if err := client.Subscribe("ethbtc@trade", "bnbbtc@trade"); err != nil {
+		t.Fatal(err)
+	}
+	err := client.Subscribe("invalid@stream")
+	if methodError, ok := err.(*StreamMethodError); !ok || methodError.Code != 2 {
+		t.Fatalf("expected a method error, got %v", err)
+	}
+	streams, err := client.ListSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(streams, ",") != "ethbtc@trade,bnbbtc@trade" {
+		t.Fatalf("unexpected subscriptions: %v", streams)
+	}
+
+	for i := 0; i < 3; i++ {
+		var message CombinedStreamMessage
+		if err := json.Unmarshal(<-messages, &message); err != nil {
+			t.Fatal(err)
+		}
+		if message.Stream != "ethbtc@trade" {
+			t.Fatalf("unexpected message: %+v", message)
+		}
+	}
+	select {
+	case body := <-messages:
+		t.Fatalf("unexpected message: %s", body)
+	default:
+	}
+}
@@ -25,9 +25,10 @@
 package binance
 
 import (
-	"strings"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -88,6 +89,178 @@ func (t *StreamAggTrade) Timestamp() time.Time {
 	return time.Unix(0, t.TradeTimeMillis*int64(time.Millisecond))
 }
 
+// Stream name: <symbol>@trade.
+type StreamTrade struct {
+	EventType       string  `json:"e"`
+	EventTimeMillis int64   `json:"E"`
+	Symbol          string  `json:"s"`
+	TradeID         int64   `json:"t"`
+	Price           float64 `json:"p,string"`
+	Quantity        float64 `json:"q,string"`
+	BuyerOrderID    int64   `json:"b"`
+	SellerOrderID   int64   `json:"a"`
+	TradeTimeMillis int64   `json:"T"`
+	BuyerMaker      bool    `json:"m"`
+	Ignored         bool    `json:"M"`
+}
+
+func (t *StreamTrade) Timestamp() time.Time {
+	return time.Unix(0, t.TradeTimeMillis*int64(time.Millisecond))
+}
+
+// Stream name: <symbol>@kline_<interval>.
+type StreamKline struct {
+	EventType       string          `json:"e"`
+	EventTimeMillis int64           `json:"E"`
+	Symbol          string          `json:"s"`
+	Kline           StreamKlineData `json:"k"`
+}
+
+type StreamKlineData struct {
+	OpenTimeMillis      int64   `json:"t"`
+	CloseTimeMillis     int64   `json:"T"`
+	Symbol              string  `json:"s"`
+	Interval            string  `json:"i"`
+	FirstTradeID        int64   `json:"f"`
+	LastTradeID         int64   `json:"L"`
+	Open                float64 `json:"o,string"`
+	Close               float64 `json:"c,string"`
+	High                float64 `json:"h,string"`
+	Low                 float64 `json:"l,string"`
+	Volume              float64 `json:"v,string"`
+	NumberOfTrades      int64   `json:"n"`
+	Closed              bool    `json:"x"`
+	QuoteVolume         float64 `json:"q,string"`
+	TakerBuyBaseVolume  float64 `json:"V,string"`
+	TakerBuyQuoteVolume float64 `json:"Q,string"`
+}
+
+func (k *StreamKlineData) OpenTime() time.Time {
+	return time.Unix(0, k.OpenTimeMillis*int64(time.Millisecond))
+}
+
+func (k *StreamKlineData) CloseTime() time.Time {
+	return time.Unix(0, k.CloseTimeMillis*int64(time.Millisecond))
+}
+
+// Stream name: <symbol>@miniTicker.
+type StreamMiniTicker struct {
+	EventType        string  `json:"e"`
+	EventTimeMillis  int64   `json:"E"`
+	Symbol           string  `json:"s"`
+	Close            float64 `json:"c,string"`
+	Open             float64 `json:"o,string"`
+	High             float64 `json:"h,string"`
+	Low              float64 `json:"l,string"`
+	TotalBaseVolume  float64 `json:"v,string"`
+	TotalQuoteVolume float64 `json:"q,string"`
+}
+
+func (t *StreamMiniTicker) Timestamp() time.Time {
+	return time.Unix(0, t.EventTimeMillis*int64(time.Millisecond))
+}
+
+// Stream name: !miniTicker@arr.
+type StreamMiniTickerAll []StreamMiniTicker
+
+// Stream name: <symbol>@bookTicker or !bookTicker.
+type StreamBookTicker struct {
+	UpdateID    int64   `json:"u"`
+	Symbol      string  `json:"s"`
+	Bid         float64 `json:"b,string"`
+	BidQuantity float64 `json:"B,string"`
+	Ask         float64 `json:"a,string"`
+	AskQuantity float64 `json:"A,string"`
+}
+
+// Stream name: <symbol>@depth<levels> or <symbol>@depth<levels>@100ms,
+// where levels is 5, 10 or 20.
+type StreamPartialDepth struct {
+	LastUpdateID int64        `json:"lastUpdateId"`
+	Bids         []DepthEntry `json:"bids"`
+	Asks         []DepthEntry `json:"asks"`
+}
+
+// Stream name: <symbol>@depth or <symbol>@depth@100ms.
+type StreamDepthUpdate struct {
+	EventType       string       `json:"e"`
+	EventTimeMillis int64        `json:"E"`
+	Symbol          string       `json:"s"`
+	FirstUpdateID   int64        `json:"U"`
+	FinalUpdateID   int64        `json:"u"`
+	Bids            []DepthEntry `json:"b"`
+	Asks            []DepthEntry `json:"a"`
+}
+
+func (d *StreamDepthUpdate) Timestamp() time.Time {
+	return time.Unix(0, d.EventTimeMillis*int64(time.Millisecond))
+}
+
+// The kind of data carried by a stream, derived from the stream name.
+type StreamType string
+
+const (
+	StreamTypeUnknown       StreamType = ""
+	StreamTypeTicker        StreamType = "ticker"
+	StreamTypeTickerAll     StreamType = "!ticker@arr"
+	StreamTypeMiniTicker    StreamType = "miniTicker"
+	StreamTypeMiniTickerAll StreamType = "!miniTicker@arr"
+	StreamTypeAggTrade      StreamType = "aggTrade"
+	StreamTypeTrade         StreamType = "trade"
+	StreamTypeKline         StreamType = "kline"
+	StreamTypeBookTicker    StreamType = "bookTicker"
+	StreamTypePartialDepth  StreamType = "partialDepth"
+	StreamTypeDepthUpdate   StreamType = "depth"
+)
+
+// ParseStreamName returns the type of a stream and, for per symbol streams,
+// the symbol in lower case. The update speed suffix (@100ms) is ignored.
+func ParseStreamName(stream string) (StreamType, string) {
+	parts := strings.Split(stream, "@")
+	if len(parts) < 2 {
+		if stream == "!bookTicker" {
+			return StreamTypeBookTicker, ""
+		}
+		return StreamTypeUnknown, ""
+	}
+	symbol, kind := parts[0], parts[1]
+
+	switch symbol {
+	case "!ticker":
+		if kind == "arr" {
+			return StreamTypeTickerAll, ""
+		}
+		return StreamTypeUnknown, ""
+	case "!miniTicker":
+		if kind == "arr" {
+			return StreamTypeMiniTickerAll, ""
+		}
+		return StreamTypeUnknown, ""
+	}
+
+	switch {
+	case kind == "ticker":
+		return StreamTypeTicker, symbol
+	case kind == "miniTicker":
+		return StreamTypeMiniTicker, symbol
+	case kind == "aggTrade":
+		return StreamTypeAggTrade, symbol
+	case kind == "trade":
+		return StreamTypeTrade, symbol
+	case kind == "bookTicker":
+		return StreamTypeBookTicker, symbol
+	case strings.HasPrefix(kind, "kline_"):
+		return StreamTypeKline, symbol
+	case kind == "depth":
+		return StreamTypeDepthUpdate, symbol
+	case strings.HasPrefix(kind, "depth"):
+		if _, err := strconv.Atoi(kind[len("depth"):]); err == nil {
+			return StreamTypePartialDepth, symbol
+		}
+	}
+	return StreamTypeUnknown, ""
+}
+
 type CombinedStream24TickerAll struct {
 	Stream  string            `json:"stream"`
 	Tickers Stream24TickerAll `json:"data"`
@@ -108,12 +281,39 @@ type CombinedStreamUnknown struct {
 type CombinedStreamMessage struct {
 	Stream string
 
+	// The type of the stream, from its name.
+	Type StreamType
+
+	// Data for <symbol>@ticker messages.
+	Ticker *Stream24Ticker
+
 	// Data for !ticker@arr messages.
 	Tickers []Stream24Ticker
 
+	// Data for <symbol>@miniTicker messages.
+	MiniTicker *StreamMiniTicker
+
+	// Data for !miniTicker@arr messages.
+	MiniTickers []StreamMiniTicker
+
 	// Data for <symbol>@aggTrade messages.
 	AggTrade *StreamAggTrade
 
+	// Data for <symbol>@trade messages.
+	Trade *StreamTrade
+
+	// Data for <symbol>@kline_<interval> messages.
+	Kline *StreamKline
+
+	// Data for <symbol>@bookTicker and !bookTicker messages.
+	BookTicker *StreamBookTicker
+
+	// Data for <symbol>@depth<levels> messages.
+	PartialDepth *StreamPartialDepth
+
+	// Data for <symbol>@depth messages.
+	DepthUpdate *StreamDepthUpdate
+
 	// For a stream that is unknown, decode the data into an interface{}.
 	UnknownData interface{}
 
@@ -121,38 +321,77 @@ type CombinedStreamMessage struct {
 }
 
 func (r *CombinedStreamMessage) UnmarshalJSON(b []byte) error {
+	var envelope struct {
+		Stream string          `json:"stream"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &envelope); err != nil {
+		return err
+	}
+	if envelope.Stream == "" || envelope.Data == nil {
+		return fmt.Errorf("not part of a multi-stream")
+	}
 	r.Bytes = b
-	prefix := string(b[0:40])
-	if strings.HasPrefix(prefix, `{"stream":"!ticker@arr"`) {
-		var message CombinedStream24TickerAll
-		if err := json.Unmarshal(b, &message); err != nil {
-			return err
-		}
-		r.Stream = message.Stream
-		r.Tickers = message.Tickers
-		return nil
-	} else if strings.Index(prefix, "@aggTrade") > -1 {
-		var message CombinedStreamAggTrade
-		if err := json.Unmarshal(b, &message); err != nil {
-			return err
-		}
-		r.Stream = message.Stream
-		r.AggTrade = &message.AggTrade
-		return nil
-	} else if strings.HasPrefix(prefix, `{"stream":"`) {
-		var message CombinedStreamUnknown
-		if err := json.Unmarshal(b, &message); err != nil {
-			return err
-		}
-		r.Stream = message.Stream
-		r.UnknownData = message.Data
-		return nil
+	return r.decodeData(envelope.Stream, envelope.Data)
+}
+
+func (r *CombinedStreamMessage) decodeData(stream string, data []byte) error {
+	r.Stream = stream
+	r.Type, _ = ParseStreamName(stream)
+
+	var target interface{}
+	switch r.Type {
+	case StreamTypeTicker:
+		r.Ticker = &Stream24Ticker{}
+		target = r.Ticker
+	case StreamTypeTickerAll:
+		target = &r.Tickers
+	case StreamTypeMiniTicker:
+		r.MiniTicker = &StreamMiniTicker{}
+		target = r.MiniTicker
+	case StreamTypeMiniTickerAll:
+		target = &r.MiniTickers
+	case StreamTypeAggTrade:
+		r.AggTrade = &StreamAggTrade{}
+		target = r.AggTrade
+	case StreamTypeTrade:
+		r.Trade = &StreamTrade{}
+		target = r.Trade
+	case StreamTypeKline:
+		r.Kline = &StreamKline{}
+		target = r.Kline
+	case StreamTypeBookTicker:
+		r.BookTicker = &StreamBookTicker{}
+		target = r.BookTicker
+	case StreamTypePartialDepth:
+		r.PartialDepth = &StreamPartialDepth{}
+		target = r.PartialDepth
+	case StreamTypeDepthUpdate:
+		r.DepthUpdate = &StreamDepthUpdate{}
+		target = r.DepthUpdate
+	default:
+		target = &r.UnknownData
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to decode %s message: %v", stream, err)
 	}
-	return fmt.Errorf("not part of a multi-stream")
+	return nil
 }
 
+// DecodeRawStreamMessage decodes a message from the combined stream
+// endpoint.
 func DecodeRawStreamMessage(b []byte) (CombinedStreamMessage, error) {
 	var message CombinedStreamMessage
 	err := json.Unmarshal(b, &message)
 	return message, err
 }
+
+// DecodeSingleStreamMessage decodes a message from the single stream
+// endpoint, where the name of the stream is not part of the message.
+func DecodeSingleStreamMessage(stream string, b []byte) (CombinedStreamMessage, error) {
+	message := CombinedStreamMessage{
+		Bytes: b,
+	}
+	err := message.decodeData(stream, b)
+	return message, err
+}
@@ -0,0 +1,82 @@
+package binance
+
+import (
+	"testing"
+)
+
+func TestParseStreamName(t *testing.T) {
+	tests := []struct {
+		stream     string
+		streamType StreamType
+		symbol     string
+	}{
+		{"ethbtc@aggTrade", StreamTypeAggTrade, "ethbtc"},
+		{"ethbtc@trade", StreamTypeTrade, "ethbtc"},
+		{"ethbtc@kline_1m", StreamTypeKline, "ethbtc"},
+		{"ethbtc@miniTicker", StreamTypeMiniTicker, "ethbtc"},
+		{"!miniTicker@arr", StreamTypeMiniTickerAll, ""},
+		{"!ticker@arr", StreamTypeTickerAll, ""},
+		{"ethbtc@ticker", StreamTypeTicker, "ethbtc"},
+		{"ethbtc@bookTicker", StreamTypeBookTicker, "ethbtc"},
+		{"!bookTicker", StreamTypeBookTicker, ""},
+		{"ethbtc@depth5", StreamTypePartialDepth, "ethbtc"},
+		{"ethbtc@depth20@100ms", StreamTypePartialDepth, "ethbtc"},
+		{"ethbtc@depth", StreamTypeDepthUpdate, "ethbtc"},
+		{"ethbtc@depth@100ms", StreamTypeDepthUpdate, "ethbtc"},
+		{"ethbtc@depthX", StreamTypeUnknown, ""},
+		{"listenkey", StreamTypeUnknown, ""},
+	}
+	for _, test := range tests {
+		streamType, symbol := ParseStreamName(test.stream)
+		if streamType != test.streamType || symbol != test.symbol {
+			t.Errorf("%s: expected %q %q, got %q %q", test.stream,
+				test.streamType, test.symbol, streamType, symbol)
+		}
+	}
+}
+
+func TestDecodeRawStreamMessage(t *testing.T) {
+	message, err := DecodeRawStreamMessage([]byte(`{"stream":"bnbbtc@kline_1m","data":{"e":"kline","E":123456789,"s":"BNBBTC","k":{"t":123400000,"T":123460000,"s":"BNBBTC","i":"1m","f":100,"L":200,"o":"0.0010","c":"0.0020","h":"0.0025","l":"0.0015","v":"1000","n":100,"x":false,"q":"1.0000","V":"500","Q":"0.500","B":"123456"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Kline == nil || message.Kline.Kline.Interval != "1m" ||
+		message.Kline.Kline.High != 0.0025 || message.Kline.Kline.TakerBuyBaseVolume != 500 {
+		t.Fatalf("unexpected kline: %+v", message.Kline)
+	}
+
+	message, err = DecodeRawStreamMessage([]byte(`{"stream":"bnbbtc@depth@100ms","data":{"e":"depthUpdate","E":123456789,"s":"BNBBTC","U":157,"u":160,"b":[["0.0024","10"]],"a":[["0.0026","100"],["0.0027","0"]]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := message.DepthUpdate
+	if update == nil || update.FirstUpdateID != 157 || update.FinalUpdateID != 160 ||
+		len(update.Asks) != 2 || update.Bids[0].Price != 0.0024 {
+		t.Fatalf("unexpected depth update: %+v", update)
+	}
+
+	message, err = DecodeRawStreamMessage([]byte(`{"stream":"!miniTicker@arr","data":[{"e":"24hrMiniTicker","E":123456789,"s":"BNBBTC","c":"0.0025","o":"0.0010","h":"0.0025","l":"0.0010","v":"10000","q":"18"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(message.MiniTickers) != 1 || message.MiniTickers[0].Close != 0.0025 {
+		t.Fatalf("unexpected mini tickers: %+v", message.MiniTickers)
+	}
+
+	// Short messages used to panic.
+	if _, err := DecodeRawStreamMessage([]byte(`{}`)); err == nil {
+		t.Fatalf("expected error for a message without a stream")
+	}
+}
+
+func TestDecodeSingleStreamMessage(t *testing.T) {
+	message, err := DecodeSingleStreamMessage("bnbusdt@bookTicker",
+		[]byte(`{"u":400900217,"s":"BNBUSDT","b":"25.35190000","B":"31.21000000","a":"25.36520000","A":"40.66000000"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.BookTicker == nil || message.BookTicker.Ask != 25.3652 ||
+		message.BookTicker.BidQuantity != 31.21 {
+		t.Fatalf("unexpected book ticker: %+v", message.BookTicker)
+	}
+}
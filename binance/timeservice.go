@@ -0,0 +1,170 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"sync"
+	"time"
+)
+
+// The weight given to a new offset sample when smoothing. Samples are noisy
+// as they include a share of the network round trip.
+const DefaultTimeSmoothing = 0.3
+
+// DefaultRecvWindow is the number of milliseconds after the request timestamp
+// that Binance will still accept a signed request.
+const DefaultRecvWindow = 5000 * time.Millisecond
+
+type ServerTimeResponse struct {
+	ServerTime int64 `json:"serverTime"`
+}
+
+// Get the server time (GET /api/v3/time).
+func (c *RestClient) GetServerTime() (time.Time, error) {
+	// Unsigned, as signing needs the server time.
+	httpResponse, err := c.Get("/api/v3/time", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != 200 {
+		return time.Time{}, NewRestApiErrorFromResponse(httpResponse)
+	}
+	var response ServerTimeResponse
+	if _, err := c.decodeBody(httpResponse, &response); err != nil {
+		return time.Time{}, err
+	}
+	return millisToTime(response.ServerTime), nil
+}
+
+// ServerTimeService tracks the offset between the local clock and the
+// Binance server clock so signed requests can be stamped with the server's
+// idea of the time.
+type ServerTimeService struct {
+	client    *RestClient
+	smoothing float64
+
+	lock     sync.RWMutex
+	offset   time.Duration
+	synced   bool
+	lastSync time.Time
+
+	// Overridable for testing.
+	now func() time.Time
+}
+
+// NewServerTimeService creates a time service that queries the server time
+// with the given client. The offset is zero until the first Sync.
+func NewServerTimeService(client *RestClient) *ServerTimeService {
+	return &ServerTimeService{
+		client:    client,
+		smoothing: DefaultTimeSmoothing,
+		now:       time.Now,
+	}
+}
+
+// SetSmoothing sets the weight, between 0 and 1, given to each new offset
+// sample. A weight of 1 disables smoothing.
+func (s *ServerTimeService) SetSmoothing(smoothing float64) {
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 1
+	}
+	s.lock.Lock()
+	s.smoothing = smoothing
+	s.lock.Unlock()
+}
+
+// Sync queries the server time and folds the measured offset into the
+// current estimate. The first sample is used as is.
+func (s *ServerTimeService) Sync() error {
+	before := s.now()
+	serverTime, err := s.client.GetServerTime()
+	if err != nil {
+		return err
+	}
+	after := s.now()
+
+	// Assume the server read its clock half way through the round trip.
+	local := before.Add(after.Sub(before) / 2)
+	s.addSample(serverTime.Sub(local), after)
+	return nil
+}
+
+// Reset discards the current estimate so the next sample replaces it instead
+// of being smoothed into it. Used when the clocks are known to be out of sync.
+func (s *ServerTimeService) Reset() {
+	s.lock.Lock()
+	s.synced = false
+	s.lock.Unlock()
+}
+
+func (s *ServerTimeService) addSample(sample time.Duration, at time.Time) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.synced {
+		s.offset = sample
+		s.synced = true
+	} else {
+		s.offset += time.Duration(s.smoothing * float64(sample-s.offset))
+	}
+	s.lastSync = at
+}
+
+// Offset returns the estimated server time minus local time.
+func (s *ServerTimeService) Offset() time.Duration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.offset
+}
+
+// LastSync returns the local time of the last successful Sync, or the zero
+// time if never synced.
+func (s *ServerTimeService) LastSync() time.Time {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.lastSync
+}
+
+// Now returns the estimated current server time.
+func (s *ServerTimeService) Now() time.Time {
+	return s.now().Add(s.Offset())
+}
+
+// Run re-syncs every interval until done is closed. Errors are passed to
+// onError if not nil; the previous estimate is kept on error.
+func (s *ServerTimeService) Run(interval time.Duration, done <-chan struct{}, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := s.Sync(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestServerTimeServiceSmoothing(t *testing.T) {
+	service := NewServerTimeService(nil)
+	service.SetSmoothing(0.5)
+	service.addSample(1000*time.Millisecond, time.Now())
+	if service.Offset() != 1000*time.Millisecond {
+		t.Fatalf("expected first sample to be used as is, got %v", service.Offset())
+	}
+	service.addSample(2000*time.Millisecond, time.Now())
+	if service.Offset() != 1500*time.Millisecond {
+		t.Fatalf("expected smoothed offset of 1.5s, got %v", service.Offset())
+	}
+	service.Reset()
+	service.addSample(-500*time.Millisecond, time.Now())
+	if service.Offset() != -500*time.Millisecond {
+		t.Fatalf("expected sample after reset to be used as is, got %v", service.Offset())
+	}
+}
+
+func TestSignedRequestResyncsOnInvalidTimestamp(t *testing.T) {
+	// The server clock runs an hour ahead of the local clock.
+	serverOffset := time.Hour
+	timeRequests := 0
+	orderRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverNow := time.Now().Add(serverOffset).UnixNano() / int64(time.Millisecond)
+		switch r.URL.Path {
+		case "/api/v3/time":
+			timeRequests++
+			fmt.Fprintf(w, `{"serverTime":%d}`, serverNow)
+		case "/api/v3/openOrders":
+			orderRequests++
+			if r.URL.Query().Get("recvWindow") != "10000" {
+				t.Errorf("expected recvWindow of 10000, got %s", r.URL.RawQuery)
+			}
+			timestamp, _ := strconv.ParseInt(r.URL.Query().Get("timestamp"), 10, 64)
+			if timestamp < serverNow-10000 || timestamp > serverNow+1000 {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL),
+		WithServerTimeSync(), WithRecvWindow(10*time.Second))
+	response, err := client.GetWithAuth("/api/v3/openOrders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", response.StatusCode)
+	}
+	if timeRequests != 1 || orderRequests != 1 {
+		t.Fatalf("expected one sync and one request, got %d and %d",
+			timeRequests, orderRequests)
+	}
+
+	// The server clock jumps; the next request is rejected, triggering a
+	// re-sync and retry.
+	serverOffset = -time.Hour
+	response, err = client.GetWithAuth("/api/v3/openOrders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status after re-sync: %d", response.StatusCode)
+	}
+	if timeRequests != 2 || orderRequests != 3 {
+		t.Fatalf("expected a re-sync and a retry, got %d and %d",
+			timeRequests, orderRequests)
+	}
+}
@@ -0,0 +1,214 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+const MaxMyTradesLimit = 1000
+
+// GetMyTradesPage returns up to limit of the account's trades on a symbol,
+// starting at fromID if not -1, or between start and end. Binance doesn't
+// accept fromID together with a time range, and start and end must be less
+// than 24 hours apart.
+func (c *RestClient) GetMyTradesPage(symbol string, fromID int64, start time.Time, end time.Time, limit int) ([]TradeResponse, error) {
+	params := map[string]interface{}{
+		"symbol": symbol,
+	}
+	if fromID > -1 {
+		params["fromId"] = fromID
+	}
+	if !start.IsZero() {
+		params["startTime"] = start.UnixNano() / int64(time.Millisecond)
+	}
+	if !end.IsZero() {
+		params["endTime"] = end.UnixNano() / int64(time.Millisecond)
+	}
+	if limit > 0 {
+		params["limit"] = limit
+	}
+	var response []TradeResponse
+	if err := c.genericGetWithAuthAndDecode("/api/v3/myTrades", params, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// TradedSymbols returns the symbols that may have been traded by the
+// account: those with a base or quote asset that has a non-zero balance or
+// is one of transferred, such as the coins of its deposit and withdrawal
+// history. The account lists every asset, with a zero balance if never
+// held, so the balances alone can't tell which were traded.
+func TradedSymbols(account *AccountInfoResponse, transferred []string, info *ExchangeInfoResponse) []SymbolInfoResponse {
+	assets := map[string]bool{}
+	for _, balance := range account.Balances {
+		if balance.Free > 0 || balance.Locked > 0 {
+			assets[balance.Asset] = true
+		}
+	}
+	for _, asset := range transferred {
+		assets[asset] = true
+	}
+	symbols := []SymbolInfoResponse{}
+	for _, symbol := range info.Symbols {
+		if assets[symbol.BaseAsset] || assets[symbol.QuoteAsset] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// TradeCheckpoint records the ID of the last trade exported per symbol.
+type TradeCheckpoint map[string]int64
+
+// LoadTradeCheckpoint reads a checkpoint file, returning an empty checkpoint
+// if it doesn't exist.
+func LoadTradeCheckpoint(filename string) (TradeCheckpoint, error) {
+	checkpoint := TradeCheckpoint{}
+	buf, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &checkpoint); err != nil {
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint to a temporary file then renames it over
+// filename, so an interrupted save doesn't lose the previous checkpoint.
+func (c TradeCheckpoint) Save(filename string) error {
+	buf, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filename + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// TradeExport pages through the account's trades on a list of symbols by
+// trade ID. When a checkpoint file is set, the last exported trade ID of each
+// symbol is saved after every page, and a following export resumes after it.
+type TradeExport struct {
+	Client  *RestClient
+	Symbols []string
+
+	// Trades before Start are skipped. Without a checkpoint the first page
+	// is found from Start, but as Binance only returns a day of trades
+	// from a start time, a symbol without trades in that day is paged
+	// through from the first trade.
+	Start time.Time
+
+	CheckpointFilename string
+
+	// Called with each page of trades, sorted by ID, before the checkpoint
+	// is saved. Returning an error stops the export.
+	OnTrades func(symbol string, trades []TradeResponse) error
+}
+
+// Run exports the trades of each symbol in turn, returning the number of
+// trades passed to OnTrades.
+func (e *TradeExport) Run() (int, error) {
+	checkpoint := TradeCheckpoint{}
+	if e.CheckpointFilename != "" {
+		var err error
+		checkpoint, err = LoadTradeCheckpoint(e.CheckpointFilename)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	startMillis := int64(0)
+	if !e.Start.IsZero() {
+		startMillis = e.Start.UnixNano() / int64(time.Millisecond)
+	}
+
+	count := 0
+	for _, symbol := range e.Symbols {
+		fromID := int64(0)
+		if last, ok := checkpoint[symbol]; ok {
+			fromID = last + 1
+		} else if !e.Start.IsZero() {
+			trades, err := e.Client.GetMyTradesPage(symbol, -1, e.Start,
+				time.Time{}, MaxMyTradesLimit)
+			if err != nil {
+				return count, err
+			}
+			for i, trade := range trades {
+				if i == 0 || trade.ID < fromID {
+					fromID = trade.ID
+				}
+			}
+		}
+		for {
+			trades, err := e.Client.GetMyTradesPage(symbol, fromID,
+				time.Time{}, time.Time{}, MaxMyTradesLimit)
+			if err != nil {
+				return count, err
+			}
+			if len(trades) == 0 {
+				break
+			}
+			sort.Slice(trades, func(i, j int) bool {
+				return trades[i].ID < trades[j].ID
+			})
+
+			selected := []TradeResponse{}
+			for _, trade := range trades {
+				if trade.TimeMillis >= startMillis {
+					selected = append(selected, trade)
+				}
+			}
+			if len(selected) > 0 && e.OnTrades != nil {
+				if err := e.OnTrades(symbol, selected); err != nil {
+					return count, err
+				}
+			}
+			count += len(selected)
+
+			fromID = trades[len(trades)-1].ID + 1
+			checkpoint[symbol] = fromID - 1
+			if e.CheckpointFilename != "" {
+				if err := checkpoint.Save(e.CheckpointFilename); err != nil {
+					return count, err
+				}
+			}
+			if len(trades) < MaxMyTradesLimit {
+				break
+			}
+		}
+	}
+	return count, nil
+}
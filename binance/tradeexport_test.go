@@ -0,0 +1,143 @@
+package binance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tradeServer serves count trades with IDs 0 to count-1 for any symbol, one
+// second apart.
+func tradeServer(t *testing.T, count int64, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		*requests = append(*requests, query.Get("symbol")+":"+query.Get("fromId"))
+		fromID, _ := strconv.ParseInt(query.Get("fromId"), 10, 64)
+		limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+		toID := count
+		if startTime := query.Get("startTime"); startTime != "" {
+			// A day of trades from the start time.
+			start, _ := strconv.ParseInt(startTime, 10, 64)
+			fromID = (start - 1500000000000 + 999) / 1000
+			if fromID+86400 < toID {
+				toID = fromID + 86400
+			}
+		}
+		trades := []string{}
+		for id := fromID; id < toID && id < fromID+limit; id++ {
+			trades = append(trades, fmt.Sprintf(
+				`{"symbol":"%s","id":%d,"orderId":1,"price":"2","qty":"3","quoteQty":"6","commission":"0.1","commissionAsset":"BNB","time":%d,"isBuyer":true}`,
+				query.Get("symbol"), id, 1500000000000+id*1000))
+		}
+		w.Write([]byte("[" + strings.Join(trades, ",") + "]"))
+	}))
+}
+
+func TestTradeExportResume(t *testing.T) {
+	requests := []string{}
+	server := tradeServer(t, MaxMyTradesLimit+10, &requests)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tradeexport")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "checkpoint.json")
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	exported := map[string]int{}
+	export := TradeExport{
+		Client:             client,
+		Symbols:            []string{"ETHBTC", "BNBBTC"},
+		CheckpointFilename: filename,
+		OnTrades: func(symbol string, trades []TradeResponse) error {
+			if trades[0].Symbol != symbol || trades[0].QuoteQuantity != 6 {
+				t.Errorf("unexpected trade: %+v", trades[0])
+			}
+			exported[symbol] += len(trades)
+			return nil
+		},
+	}
+	count, err := export.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2*(MaxMyTradesLimit+10) || exported["BNBBTC"] != MaxMyTradesLimit+10 {
+		t.Fatalf("unexpected count %d: %v", count, exported)
+	}
+	expected := []string{"ETHBTC:0", "ETHBTC:1000", "BNBBTC:0", "BNBBTC:1000"}
+	if strings.Join(requests, " ") != strings.Join(expected, " ") {
+		t.Fatalf("unexpected requests: %v", requests)
+	}
+
+	checkpoint, err := LoadTradeCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint["ETHBTC"] != MaxMyTradesLimit+9 {
+		t.Fatalf("unexpected checkpoint: %v", checkpoint)
+	}
+
+	// Running again only asks for trades after the checkpoint.
+	requests = requests[:0]
+	count, err = export.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 || strings.Join(requests, " ") != "ETHBTC:1010 BNBBTC:1010" {
+		t.Fatalf("unexpected resume: %d %v", count, requests)
+	}
+}
+
+func TestTradeExportStart(t *testing.T) {
+	requests := []string{}
+	server := tradeServer(t, 10, &requests)
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	export := TradeExport{
+		Client:  client,
+		Symbols: []string{"ETHBTC"},
+		Start:   time.Unix(1500000005, 0),
+	}
+	count, err := export.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Fatalf("expected 5 trades, got %d", count)
+	}
+	if strings.Join(requests, " ") != "ETHBTC: ETHBTC:5" {
+		t.Fatalf("expected the first page from the start time, got %v", requests)
+	}
+}
+
+func TestTradedSymbols(t *testing.T) {
+	account := &AccountInfoResponse{Balances: []AccountInfoBalance{
+		{Asset: "BTC", Free: 1},
+		{Asset: "LTC", Locked: 0.5},
+		{Asset: "XRP"},
+		{Asset: "ADA"},
+	}}
+	info := &ExchangeInfoResponse{Symbols: []SymbolInfoResponse{
+		{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC"},
+		{Symbol: "LTCUSDT", BaseAsset: "LTC", QuoteAsset: "USDT"},
+		{Symbol: "XRPUSDT", BaseAsset: "XRP", QuoteAsset: "USDT"},
+		{Symbol: "BNBUSDT", BaseAsset: "BNB", QuoteAsset: "USDT"},
+		{Symbol: "ADAUSDT", BaseAsset: "ADA", QuoteAsset: "USDT"},
+	}}
+	// XRP has a zero balance but was withdrawn, ADA was never held.
+	symbols := TradedSymbols(account, []string{"XRP"}, info)
+	if len(symbols) != 3 || symbols[0].Symbol != "ETHBTC" ||
+		symbols[1].Symbol != "LTCUSDT" || symbols[2].Symbol != "XRPUSDT" {
+		t.Fatalf("unexpected symbols: %+v", symbols)
+	}
+}
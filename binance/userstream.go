@@ -24,6 +24,11 @@
 
 package binance
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type StreamAccountInfoBalance struct {
 	Asset  string  `json:"a"`
 	Free   float64 `json:"f,string"`
@@ -70,20 +75,112 @@ type StreamExecutionReport struct {
 	TradeID                  int64       `json:"t"`
 	IsWorking                bool        `json:"w"`
 	IsMaker                  bool        `json:"m"`
+	CumulativeQuoteQuantity  float64     `json:"Z,string"`
+	LastQuoteQuantity        float64     `json:"Y,string"`
+	QuoteOrderQuantity       float64     `json:"Q,string"`
+	WorkingTimeMillis        int64       `json:"W"`
 
 	// Ignore values that we have to include here due to the case insensitivity
 	// of the Go JSON unmarshaller.
 	Ignore0 int64       `json:"O,-"`
 	Ignore1 interface{} `json:"I,-"`
+	Ignore2 bool        `json:"M"`
+}
+
+// Stream event: outboundAccountPosition, sent with the balances that changed
+// after an account update.
+type StreamOutboundAccountPosition struct {
+	EventType             string                     `json:"e"`
+	EventTimeMillis       int64                      `json:"E"`
+	LastAccountUpdateTime int64                      `json:"u"`
+	Balances              []StreamAccountInfoBalance `json:"B"`
+}
+
+// Stream event: balanceUpdate, sent on deposits, withdrawals and transfers.
+type StreamBalanceUpdate struct {
+	EventType       string  `json:"e"`
+	EventTimeMillis int64   `json:"E"`
+	Asset           string  `json:"a"`
+	Delta           float64 `json:"d,string"`
+	ClearTimeMillis int64   `json:"T"`
+}
+
+// Stream event: listenKeyExpired. No more events will be sent for the
+// listen key.
+type StreamListenKeyExpired struct {
+	EventType       string `json:"e"`
+	EventTimeMillis int64  `json:"E"`
+}
+
+// A decoded user data stream message. Only the field matching EventType is
+// set.
+type UserStreamMessage struct {
+	EventType string
+
+	ExecutionReport  *StreamExecutionReport
+	AccountInfo      *StreamOutboundAccountInfo
+	AccountPosition  *StreamOutboundAccountPosition
+	BalanceUpdate    *StreamBalanceUpdate
+	ListenKeyExpired *StreamListenKeyExpired
+
+	Bytes []byte
+}
+
+// DecodeUserStreamMessage decodes a user data stream message by its event
+// type. Unknown event types are not an error; only EventType and Bytes are
+// set.
+func DecodeUserStreamMessage(b []byte) (UserStreamMessage, error) {
+	message := UserStreamMessage{
+		Bytes: b,
+	}
+	var event struct {
+		EventType string `json:"e"`
+
+		// Or "E" would be matched to "e".
+		EventTimeMillis int64 `json:"E"`
+	}
+	if err := json.Unmarshal(b, &event); err != nil {
+		return message, err
+	}
+	message.EventType = event.EventType
+
+	var target interface{}
+	switch event.EventType {
+	case "executionReport":
+		message.ExecutionReport = &StreamExecutionReport{}
+		target = message.ExecutionReport
+	case "outboundAccountInfo":
+		message.AccountInfo = &StreamOutboundAccountInfo{}
+		target = message.AccountInfo
+	case "outboundAccountPosition":
+		message.AccountPosition = &StreamOutboundAccountPosition{}
+		target = message.AccountPosition
+	case "balanceUpdate":
+		message.BalanceUpdate = &StreamBalanceUpdate{}
+		target = message.BalanceUpdate
+	case "listenKeyExpired":
+		message.ListenKeyExpired = &StreamListenKeyExpired{}
+		target = message.ListenKeyExpired
+	default:
+		return message, nil
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return message, fmt.Errorf("failed to decode %s event: %v",
+			event.EventType, err)
+	}
+	return message, nil
 }
 
-func OpenUserStream(restClient *RestClient) (*StreamClient, error) {
+// OpenUserStream connects to the user data stream with a new listen key. The
+// key is not kept alive, so the stream ends after 60 minutes; use UserStream
+// for a long running stream.
+func OpenUserStream(restClient *RestClient, opts ...StreamClientOption) (*StreamClient, error) {
 	listenKey, err := restClient.GetUserDataStream()
 	if err != nil {
 		return nil, err
 	}
 
-	streamClient := NewStreamClient()
+	streamClient := NewStreamClient(opts...)
 	if err := streamClient.ConnectSingle(listenKey); err != nil {
 		return nil, err
 	}
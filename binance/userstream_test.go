@@ -13,3 +13,28 @@ func TestDecodeRawOrderUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestDecodeUserStreamMessage(t *testing.T) {
+	message, err := DecodeUserStreamMessage([]byte(`{"e":"outboundAccountPosition","E":1564034571105,"u":1564034571073,"B":[{"a":"ETH","f":"10000.000000","l":"0.000000"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	position := message.AccountPosition
+	if position == nil || len(position.Balances) != 1 || position.Balances[0].Free != 10000 {
+		t.Fatalf("unexpected account position: %+v", position)
+	}
+
+	message, err = DecodeUserStreamMessage([]byte(`{"e":"executionReport","E":1,"s":"ETHBTC","q":"2.0","Q":"0.5","m":true,"M":false,"w":false,"W":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := message.ExecutionReport
+	if report.Quantity != 2 || report.QuoteOrderQuantity != 0.5 || !report.IsMaker {
+		t.Fatalf("unexpected execution report: %+v", report)
+	}
+
+	message, err = DecodeUserStreamMessage([]byte(`{"e":"somethingNew","E":1}`))
+	if err != nil || message.EventType != "somethingNew" {
+		t.Fatalf("unexpected result for unknown event: %+v, %v", message, err)
+	}
+}
@@ -0,0 +1,233 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Listen keys expire after 60 minutes without a keepalive.
+const DefaultUserStreamKeepAliveInterval = 30 * time.Minute
+
+// UserStream is a user data stream that keeps its listen key alive, gets a
+// new key when the old one expires, and reconnects when the connection is
+// lost. Events are passed to the callbacks registered with the On* methods,
+// which are called from the Run goroutine.
+type UserStream struct {
+	client            *RestClient
+	streamOpts        []ManagedStreamOption
+	keepAliveInterval time.Duration
+	retryDelay        time.Duration
+
+	lock              sync.Mutex
+	listenKey         string
+	onExecutionReport []func(*StreamExecutionReport)
+	onAccountInfo     []func(*StreamOutboundAccountInfo)
+	onAccountPosition []func(*StreamOutboundAccountPosition)
+	onBalanceUpdate   []func(*StreamBalanceUpdate)
+	onConnection      []func(StreamEventType, error)
+
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewUserStream creates a user stream. The client only needs an API key.
+func NewUserStream(client *RestClient, opts ...ManagedStreamOption) *UserStream {
+	return &UserStream{
+		client:            client,
+		streamOpts:        opts,
+		keepAliveInterval: DefaultUserStreamKeepAliveInterval,
+		retryDelay:        DefaultMinReconnectDelay,
+		closed:            make(chan struct{}),
+	}
+}
+
+// SetKeepAliveInterval sets how often the listen key is kept alive.
+func (s *UserStream) SetKeepAliveInterval(interval time.Duration) {
+	s.keepAliveInterval = interval
+}
+
+// OnExecutionReport registers a callback for order updates.
+func (s *UserStream) OnExecutionReport(callback func(*StreamExecutionReport)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onExecutionReport = append(s.onExecutionReport, callback)
+}
+
+// OnAccountInfo registers a callback for outboundAccountInfo events.
+func (s *UserStream) OnAccountInfo(callback func(*StreamOutboundAccountInfo)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onAccountInfo = append(s.onAccountInfo, callback)
+}
+
+// OnAccountPosition registers a callback for outboundAccountPosition events.
+func (s *UserStream) OnAccountPosition(callback func(*StreamOutboundAccountPosition)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onAccountPosition = append(s.onAccountPosition, callback)
+}
+
+// OnBalanceUpdate registers a callback for balanceUpdate events.
+func (s *UserStream) OnBalanceUpdate(callback func(*StreamBalanceUpdate)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onBalanceUpdate = append(s.onBalanceUpdate, callback)
+}
+
+// OnConnectionState registers a callback for StreamEventConnected and
+// StreamEventDisconnected events. Events may have been missed between a
+// disconnect and the next connect, so state kept from the stream should be
+// refreshed from the REST API on connect.
+func (s *UserStream) OnConnectionState(callback func(StreamEventType, error)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onConnection = append(s.onConnection, callback)
+}
+
+// ListenKey returns the listen key in use, if any.
+func (s *UserStream) ListenKey() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.listenKey
+}
+
+// Close stops Run and closes the listen key.
+func (s *UserStream) Close() {
+	s.once.Do(func() {
+		close(s.closed)
+	})
+}
+
+var errListenKeyExpired = errors.New("listen key expired")
+
+// Run opens the stream and dispatches events until Close is called.
+func (s *UserStream) Run() {
+	for {
+		listenKey, err := s.client.GetUserDataStream()
+		if err != nil {
+			s.notifyConnection(StreamEventDisconnected, err)
+			select {
+			case <-s.closed:
+				return
+			case <-time.After(s.retryDelay):
+				continue
+			}
+		}
+		s.lock.Lock()
+		s.listenKey = listenKey
+		s.lock.Unlock()
+
+		err = s.runListenKey(listenKey)
+		if err == nil {
+			// Closed.
+			s.client.DeleteUserDataStream(listenKey)
+			return
+		}
+		s.notifyConnection(StreamEventDisconnected, err)
+	}
+}
+
+// runListenKey runs the stream for a listen key until the key expires,
+// returning the reason, or until the stream is closed, returning nil.
+func (s *UserStream) runListenKey(listenKey string) error {
+	stream := NewManagedSingleStream(listenKey, s.streamOpts...)
+	defer stream.Close()
+	events := make(chan ManagedStreamEvent)
+	go stream.Run(events)
+
+	keepAlive := time.NewTicker(s.keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-s.closed:
+			return nil
+		case <-keepAlive.C:
+			if err := s.client.PutUserStreamKeepAlive(listenKey); err != nil {
+				var apiError *RestApiError
+				if errors.As(err, &apiError) &&
+					apiError.Code == ErrorCodeInvalidListenKey {
+					return err
+				}
+				// Otherwise try again next time; the key is valid
+				// for a while yet.
+			}
+		case event := <-events:
+			switch event.Type {
+			case StreamEventConnected, StreamEventDisconnected:
+				s.notifyConnection(event.Type, event.Err)
+			case StreamEventMessage:
+				message, err := DecodeUserStreamMessage(event.Message)
+				if err != nil {
+					continue
+				}
+				if message.ListenKeyExpired != nil {
+					return errListenKeyExpired
+				}
+				s.dispatch(&message)
+			}
+		}
+	}
+}
+
+func (s *UserStream) dispatch(message *UserStreamMessage) {
+	s.lock.Lock()
+	onExecutionReport := s.onExecutionReport
+	onAccountInfo := s.onAccountInfo
+	onAccountPosition := s.onAccountPosition
+	onBalanceUpdate := s.onBalanceUpdate
+	s.lock.Unlock()
+
+	switch {
+	case message.ExecutionReport != nil:
+		for _, callback := range onExecutionReport {
+			callback(message.ExecutionReport)
+		}
+	case message.AccountInfo != nil:
+		for _, callback := range onAccountInfo {
+			callback(message.AccountInfo)
+		}
+	case message.AccountPosition != nil:
+		for _, callback := range onAccountPosition {
+			callback(message.AccountPosition)
+		}
+	case message.BalanceUpdate != nil:
+		for _, callback := range onBalanceUpdate {
+			callback(message.BalanceUpdate)
+		}
+	}
+}
+
+func (s *UserStream) notifyConnection(eventType StreamEventType, err error) {
+	s.lock.Lock()
+	callbacks := s.onConnection
+	s.lock.Unlock()
+	for _, callback := range callbacks {
+		callback(eventType, err)
+	}
+}
@@ -0,0 +1,103 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestUserStreamRenewsListenKey(t *testing.T) {
+	var keys, keepAlives int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/userDataStream" && r.Method == "POST":
+			n := atomic.AddInt32(&keys, 1)
+			fmt.Fprintf(w, `{"listenKey":"key%d"}`, n)
+		case r.URL.Path == "/api/v1/userDataStream" && r.Method == "PUT":
+			atomic.AddInt32(&keepAlives, 1)
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/api/v1/userDataStream" && r.Method == "DELETE":
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/ws/key1":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"executionReport","E":1,"s":"ETHBTC","c":"abc","S":"BUY","o":"LIMIT","q":"1.0","p":"0.1","x":"NEW","X":"NEW","i":1,"l":"0","z":"0","L":"0","n":"0","m":false,"M":true,"Z":"0","Q":"0","Y":"0","W":1}`))
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"listenKeyExpired","E":2}`))
+			conn.ReadMessage()
+		case r.URL.Path == "/ws/key2":
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"balanceUpdate","E":3,"a":"BTC","d":"100.00000000","T":3}`))
+			conn.ReadMessage()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "", WithBaseURL(server.URL))
+	stream := NewUserStream(client, WithStreamClientOptions(
+		WithStreamURL("ws"+strings.TrimPrefix(server.URL, "http"))))
+	stream.SetKeepAliveInterval(10 * time.Millisecond)
+
+	reports := make(chan *StreamExecutionReport, 1)
+	updates := make(chan *StreamBalanceUpdate, 1)
+	stream.OnExecutionReport(func(report *StreamExecutionReport) {
+		reports <- report
+	})
+	stream.OnBalanceUpdate(func(update *StreamBalanceUpdate) {
+		updates <- update
+	})
+
+	done := make(chan struct{})
+	go func() {
+		stream.Run()
+		close(done)
+	}()
+
+	select {
+	case report := <-reports:
+		if report.ClientOrderID != "abc" || report.IsMaker {
+			t.Fatalf("unexpected execution report: %+v", report)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for execution report")
+	}
+
+	select {
+	case update := <-updates:
+		if update.Asset != "BTC" || update.Delta != 100 {
+			t.Fatalf("unexpected balance update: %+v", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for balance update")
+	}
+	if stream.ListenKey() != "key2" {
+		t.Fatalf("expected listen key to be renewed, got %s", stream.ListenKey())
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&keepAlives) == 0 {
+		t.Fatalf("expected keepalive requests")
+	}
+
+	stream.Close()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for Run to return")
+	}
+}
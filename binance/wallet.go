@@ -0,0 +1,259 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"time"
+)
+
+// Deposit and withdrawal history can only be queried 90 days at a time.
+const walletHistoryMaxWindow = 90 * 24 * time.Hour
+
+// The maximum number of records returned by one history call.
+const MaxWalletHistoryLimit = 1000
+
+type DepositStatus int
+
+const (
+	DepositStatusPending            DepositStatus = 0
+	DepositStatusSuccess            DepositStatus = 1
+	DepositStatusCreditedNoWithdraw DepositStatus = 6
+	DepositStatusWrongDeposit       DepositStatus = 7
+	DepositStatusWaitingUser        DepositStatus = 8
+)
+
+func (s DepositStatus) String() string {
+	switch s {
+	case DepositStatusPending:
+		return "pending"
+	case DepositStatusSuccess:
+		return "success"
+	case DepositStatusCreditedNoWithdraw:
+		return "credited"
+	case DepositStatusWrongDeposit:
+		return "wrong-deposit"
+	case DepositStatusWaitingUser:
+		return "waiting-user"
+	}
+	return fmt.Sprintf("%d", int(s))
+}
+
+type WithdrawStatus int
+
+const (
+	WithdrawStatusEmailSent        WithdrawStatus = 0
+	WithdrawStatusCancelled        WithdrawStatus = 1
+	WithdrawStatusAwaitingApproval WithdrawStatus = 2
+	WithdrawStatusRejected         WithdrawStatus = 3
+	WithdrawStatusProcessing       WithdrawStatus = 4
+	WithdrawStatusFailure          WithdrawStatus = 5
+	WithdrawStatusCompleted        WithdrawStatus = 6
+)
+
+func (s WithdrawStatus) String() string {
+	switch s {
+	case WithdrawStatusEmailSent:
+		return "email-sent"
+	case WithdrawStatusCancelled:
+		return "cancelled"
+	case WithdrawStatusAwaitingApproval:
+		return "awaiting-approval"
+	case WithdrawStatusRejected:
+		return "rejected"
+	case WithdrawStatusProcessing:
+		return "processing"
+	case WithdrawStatusFailure:
+		return "failure"
+	case WithdrawStatusCompleted:
+		return "completed"
+	}
+	return fmt.Sprintf("%d", int(s))
+}
+
+// GET /sapi/v1/capital/deposit/hisrec
+type DepositResponse struct {
+	ID               string        `json:"id"`
+	Amount           float64       `json:"amount,string"`
+	Coin             string        `json:"coin"`
+	Network          string        `json:"network"`
+	Status           DepositStatus `json:"status"`
+	Address          string        `json:"address"`
+	AddressTag       string        `json:"addressTag"`
+	TxID             string        `json:"txId"`
+	InsertTimeMillis int64         `json:"insertTime"`
+	TransferType     int64         `json:"transferType"`
+	ConfirmTimes     string        `json:"confirmTimes"`
+}
+
+func (d *DepositResponse) Timestamp() time.Time {
+	return millisToTime(d.InsertTimeMillis)
+}
+
+// GET /sapi/v1/capital/withdraw/history
+type WithdrawResponse struct {
+	ID              string         `json:"id"`
+	Amount          float64        `json:"amount,string"`
+	TransactionFee  float64        `json:"transactionFee,string"`
+	Coin            string         `json:"coin"`
+	Network         string         `json:"network"`
+	Status          WithdrawStatus `json:"status"`
+	Address         string         `json:"address"`
+	AddressTag      string         `json:"addressTag"`
+	TxID            string         `json:"txId"`
+	ApplyTime       string         `json:"applyTime"`
+	CompleteTime    string         `json:"completeTime"`
+	TransferType    int64          `json:"transferType"`
+	WithdrawOrderID string         `json:"withdrawOrderId"`
+	Info            string         `json:"info"`
+}
+
+// Timestamp returns the time the withdrawal was applied for. Binance sends
+// it as a UTC date and time.
+func (w *WithdrawResponse) Timestamp() (time.Time, error) {
+	return time.Parse("2006-01-02 15:04:05", w.ApplyTime)
+}
+
+// GET /sapi/v1/capital/deposit/address
+type DepositAddressResponse struct {
+	Address string `json:"address"`
+	Coin    string `json:"coin"`
+	Tag     string `json:"tag"`
+	URL     string `json:"url"`
+}
+
+// GET /sapi/v1/asset/assetDetail
+type AssetDetail struct {
+	MinWithdrawAmount float64 `json:"minWithdrawAmount,string"`
+	DepositStatus     bool    `json:"depositStatus"`
+	WithdrawFee       float64 `json:"withdrawFee"`
+	WithdrawStatus    bool    `json:"withdrawStatus"`
+	DepositTip        string  `json:"depositTip"`
+}
+
+func walletHistoryParams(coin string, start time.Time, end time.Time, offset int) map[string]interface{} {
+	params := map[string]interface{}{
+		"startTime": start.UnixNano() / int64(time.Millisecond),
+		"endTime":   end.UnixNano() / int64(time.Millisecond),
+		"limit":     MaxWalletHistoryLimit,
+	}
+	if coin != "" {
+		params["coin"] = coin
+	}
+	if offset > 0 {
+		params["offset"] = offset
+	}
+	return params
+}
+
+// forEachWalletWindow calls fetch for each window of up to 90 days between
+// start and end, oldest first.
+func forEachWalletWindow(start time.Time, end time.Time, fetch func(start time.Time, end time.Time) error) error {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(walletHistoryMaxWindow) {
+		windowEnd := windowStart.Add(walletHistoryMaxWindow - time.Millisecond)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+		if err := fetch(windowStart, windowEnd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDepositHistory returns the deposits of a coin, or of all coins if coin
+// is empty, made between start and end. A zero end is now. The range is
+// queried 90 days at a time.
+func (c *RestClient) GetDepositHistory(coin string, start time.Time, end time.Time) ([]DepositResponse, error) {
+	deposits := []DepositResponse{}
+	err := forEachWalletWindow(start, end, func(start time.Time, end time.Time) error {
+		for offset := 0; ; offset += MaxWalletHistoryLimit {
+			var page []DepositResponse
+			if err := c.genericGetWithAuthAndDecode("/sapi/v1/capital/deposit/hisrec",
+				walletHistoryParams(coin, start, end, offset), &page); err != nil {
+				return err
+			}
+			deposits = append(deposits, page...)
+			if len(page) < MaxWalletHistoryLimit {
+				return nil
+			}
+		}
+	})
+	return deposits, err
+}
+
+// GetWithdrawHistory returns the withdrawals of a coin, or of all coins if
+// coin is empty, made between start and end. A zero end is now. The range
+// is queried 90 days at a time.
+func (c *RestClient) GetWithdrawHistory(coin string, start time.Time, end time.Time) ([]WithdrawResponse, error) {
+	withdrawals := []WithdrawResponse{}
+	err := forEachWalletWindow(start, end, func(start time.Time, end time.Time) error {
+		for offset := 0; ; offset += MaxWalletHistoryLimit {
+			var page []WithdrawResponse
+			if err := c.genericGetWithAuthAndDecode("/sapi/v1/capital/withdraw/history",
+				walletHistoryParams(coin, start, end, offset), &page); err != nil {
+				return err
+			}
+			withdrawals = append(withdrawals, page...)
+			if len(page) < MaxWalletHistoryLimit {
+				return nil
+			}
+		}
+	})
+	return withdrawals, err
+}
+
+// GetDepositAddress returns the deposit address of a coin. If network is
+// empty the default network of the coin is used.
+func (c *RestClient) GetDepositAddress(coin string, network string) (*DepositAddressResponse, error) {
+	params := map[string]interface{}{
+		"coin": coin,
+	}
+	if network != "" {
+		params["network"] = network
+	}
+	var response DepositAddressResponse
+	if err := c.genericGetWithAuthAndDecode("/sapi/v1/capital/deposit/address", params, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetAssetDetail returns the withdrawal fees and limits of an asset, or of
+// all assets if asset is empty, keyed by asset.
+func (c *RestClient) GetAssetDetail(asset string) (map[string]AssetDetail, error) {
+	params := map[string]interface{}{}
+	if asset != "" {
+		params["asset"] = asset
+	}
+	var response map[string]AssetDetail
+	if err := c.genericGetWithAuthAndDecode("/sapi/v1/asset/assetDetail", params, &response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
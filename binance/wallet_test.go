@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGetDepositHistoryWindows(t *testing.T) {
+	windows := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		start, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+		end, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+		if time.Duration(end-start)*time.Millisecond >= walletHistoryMaxWindow {
+			t.Errorf("window longer than 90 days: %s", r.URL.RawQuery)
+		}
+		windows++
+		if windows == 2 {
+			w.Write([]byte(`[{"id":"1","amount":"0.5","coin":"BTC","network":"BTC","status":1,"address":"addr","txId":"tx","insertTime":1599621997000,"confirmTimes":"2/2"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	deposits, err := client.GetDepositHistory("BTC", start, start.AddDate(0, 0, 200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if windows != 3 {
+		t.Fatalf("expected 3 windows, got %d", windows)
+	}
+	if len(deposits) != 1 || deposits[0].Amount != 0.5 || deposits[0].Status != DepositStatusSuccess {
+		t.Fatalf("unexpected deposits: %+v", deposits)
+	}
+}
+
+func TestGetWithdrawHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"id":"b6ae22b3","amount":"8.91000000","transactionFee":"0.004","coin":"USDT","status":6,"address":"0x94df","txId":"0xb5ef","applyTime":"2019-10-12 11:12:02","network":"ETH","transferType":0}]`))
+	}))
+	defer server.Close()
+
+	client := NewAuthenticatedClient("key", "secret", WithBaseURL(server.URL))
+	end := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	withdrawals, err := client.GetWithdrawHistory("", end.AddDate(0, 0, -30), end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withdrawals) != 1 || withdrawals[0].TransactionFee != 0.004 ||
+		withdrawals[0].Status != WithdrawStatusCompleted {
+		t.Fatalf("unexpected withdrawals: %+v", withdrawals)
+	}
+	timestamp, err := withdrawals[0].Timestamp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !timestamp.Equal(time.Date(2019, 10, 12, 11, 12, 2, 0, time.UTC)) {
+		t.Fatalf("unexpected timestamp: %v", timestamp)
+	}
+}
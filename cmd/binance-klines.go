@@ -0,0 +1,60 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/binance"
+	"github.com/spf13/cobra"
+)
+
+var binanceKlinesCmd = &cobra.Command{
+	Use:   "klines <symbol> <interval>",
+	Short: "Download klines to a CSV file",
+	Long: `Download the klines (candlesticks) of a symbol for an interval such as 1m, 1h
+or 1d to a CSV file.
+
+If the file already exists the download resumes after the last kline in the
+file, so an interrupted download can be continued by running the same
+command again.
+
+Dates are given as 2006-01-02 or as an RFC3339 timestamp.
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.KlinesCommand(args[0], args[1])
+	},
+}
+
+func init() {
+	binanceCmd.AddCommand(binanceKlinesCmd)
+
+	flags := binanceKlinesCmd.Flags()
+	flags.StringVar(&binance.KlinesFlags.Start, "start", "",
+		"Start date (default: first kline)")
+	flags.StringVar(&binance.KlinesFlags.End, "end", "",
+		"End date (default: now)")
+	flags.StringVarP(&binance.KlinesFlags.Output, "output", "o", "",
+		"Output filename (default: <SYMBOL>-<interval>.csv)")
+}
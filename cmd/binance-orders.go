@@ -0,0 +1,91 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/binance"
+	"github.com/spf13/cobra"
+)
+
+var binanceOrdersCmd = &cobra.Command{
+	Use:   "orders",
+	Short: "List and cancel orders",
+}
+
+var binanceOrdersListCmd = &cobra.Command{
+	Use:   "list [symbol]",
+	Short: "List open orders",
+	Long: `List the open orders of a symbol, or of all symbols if none is given.
+
+With --history all the orders of the symbol are listed, optionally limited
+to those created between --start and --end.
+
+Available output formats:
+  - default
+  - json
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.OrdersListCommand(args)
+	},
+}
+
+var binanceOrdersCancelCmd = &cobra.Command{
+	Use:   "cancel <symbol> <order-id>",
+	Short: "Cancel an order",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.OrdersCancelCommand(args[0], args[1])
+	},
+}
+
+var binanceOrdersCancelAllCmd = &cobra.Command{
+	Use:   "cancel-all <symbol>",
+	Short: "Cancel all open orders of a symbol",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.OrdersCancelAllCommand(args[0])
+	},
+}
+
+func init() {
+	binanceCmd.AddCommand(binanceOrdersCmd)
+	binanceOrdersCmd.AddCommand(binanceOrdersListCmd)
+	binanceOrdersCmd.AddCommand(binanceOrdersCancelCmd)
+	binanceOrdersCmd.AddCommand(binanceOrdersCancelAllCmd)
+
+	flags := binanceOrdersListCmd.Flags()
+	flags.StringVar(&binance.OrdersFlags.Format, "format", "",
+		"Display format (default, json)")
+	flags.BoolVar(&binance.OrdersFlags.History, "history", false,
+		"List all orders of the symbol, not only open orders")
+	flags.StringVar(&binance.OrdersFlags.Start, "start", "",
+		"With --history, list orders created after this date")
+	flags.StringVar(&binance.OrdersFlags.End, "end", "",
+		"With --history, list orders created before this date")
+
+	binanceOrdersCancelCmd.Flags().BoolVar(&binance.OrdersFlags.ClientID,
+		"client-id", false, "The order ID is a client order ID")
+}
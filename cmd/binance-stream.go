@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/khayrullo/cryptotrader/binance"
 	"github.com/spf13/cobra"
@@ -14,30 +13,30 @@ var binanceStreamCmd = &cobra.Command{
 	Use:   "stream <stream0> <stream1> ...",
 	Short: "Print one or more streams",
 	Long: `Connects to the Binance websocket and prints the output of one or more stream
-names provided on the command line.
+names provided on the command line. The connection is re-established if it is
+lost.
 `,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		client := binance.NewStreamClient()
+		var stream *binance.ManagedStream
 		if binanceStreamSingle {
-			if err := client.ConnectSingle(args[0]); err != nil {
-				log.Fatal("error: ", err)
-			}
+			stream = binance.NewManagedSingleStream(args[0])
 		} else {
-			if err := client.Connect(args...); err != nil {
-				log.Fatal("error: ", err)
-			}
+			stream = binance.NewManagedStream(args)
 		}
-		log.Println("Connected!")
-		for {
-			msg, err := client.NextJSON()
-			if err != nil {
-				log.Fatal("error: ", err)
-			}
-			txt, err := json.Marshal(msg)
-			if err != nil {
-				log.Fatal("error: ", err)
+
+		events := make(chan binance.ManagedStreamEvent)
+		go stream.Run(events)
+
+		for event := range events {
+			switch event.Type {
+			case binance.StreamEventConnected:
+				log.Println("Connected!")
+			case binance.StreamEventDisconnected:
+				log.Printf("Disconnected, will reconnect: %v", event.Err)
+			case binance.StreamEventMessage:
+				fmt.Printf("%s\n", event.Message)
 			}
-			fmt.Printf("%s\n", txt)
 		}
 	},
 }
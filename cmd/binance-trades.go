@@ -0,0 +1,77 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/binance"
+	"github.com/spf13/cobra"
+)
+
+var binanceTradesCmd = &cobra.Command{
+	Use:   "trades [symbol...]",
+	Short: "Export trade history",
+	Long: `Export the trades of one or more symbols.
+
+With no symbols, the trades of every symbol with a base or quote asset
+that has a non-zero balance or has ever been deposited or withdrawn are
+exported. A pair where neither asset is still held or was ever
+transferred is not found this way, so list its symbol explicitly.
+Trades are listed one symbol at a time, oldest first.
+
+Fees are often paid in BNB rather than the quote asset. The fee asset is
+included in the json and default formats; the tab and csv formats keep the
+same columns as the other exchanges' trade exports.
+
+Example: Export all trades to a CSV file, resuming after an interruption:
+
+    cryptotrader binance trades --format csv \
+        --checkpoint trades.checkpoint >> trades.csv
+
+With --checkpoint the ID of the last trade exported for each symbol is
+saved to the file after each page, and a following run only exports newer
+trades.
+
+Available output formats:
+  - tab
+  - csv
+  - json
+  - default
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.TradesCommand(args)
+	},
+}
+
+func init() {
+	binanceCmd.AddCommand(binanceTradesCmd)
+
+	flags := binanceTradesCmd.Flags()
+	flags.StringVar(&binance.TradesFlags.Format, "format", "",
+		"Display format (tab, csv, json, default)")
+	flags.StringVar(&binance.TradesFlags.Start, "start", "",
+		"Only export trades after this date")
+	flags.StringVar(&binance.TradesFlags.Checkpoint, "checkpoint", "",
+		"Checkpoint file to resume from and update")
+}
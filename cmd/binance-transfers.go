@@ -0,0 +1,67 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/binance"
+	"github.com/spf13/cobra"
+)
+
+var binanceTransfersCmd = &cobra.Command{
+	Use:   "transfers [coin...]",
+	Short: "Print deposits and withdrawals",
+	Long: `Print deposits and withdrawals for one or more coins, oldest first.
+
+Example: List BTC and LTC transfers:
+
+    cryptotrader binance transfers BTC LTC
+
+Example: List transfers for all coins:
+
+    cryptotrader binance transfers
+
+Binance only returns 90 days of history per request, so listing transfers
+from the default start date takes a few dozen requests.
+
+Available output formats:
+  - tab
+  - csv
+  - json
+  - default
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		binance.TransfersCommand(args)
+	},
+}
+
+func init() {
+	binanceCmd.AddCommand(binanceTransfersCmd)
+
+	flags := binanceTransfersCmd.Flags()
+	flags.StringVar(&binance.TransfersFlags.Format, "format", "",
+		"Display format (tab, csv, json, default)")
+	flags.StringVar(&binance.TransfersFlags.Start, "start", "",
+		"List transfers after this date (default: 2017-07-01)")
+}
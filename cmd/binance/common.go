@@ -0,0 +1,55 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/khayrullo/cryptotrader/binance"
+	"github.com/spf13/viper"
+	"log"
+)
+
+// getClient returns a client authenticated with the configured API key and
+// secret, exiting if they are not set.
+func getClient() *binance.RestClient {
+	apiKey := viper.GetString("binance.api.key")
+	apiSecret := viper.GetString("binance.api.secret")
+	if apiKey == "" || apiSecret == "" {
+		log.Fatal("error: this command requires an api key and secret")
+	}
+
+	return binance.NewAuthenticatedClient(apiKey, apiSecret,
+		binance.WithServerTimeSync())
+}
+
+// printJSON prints v as a single line of JSON.
+func printJSON(v interface{}) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		log.Fatal("error: ", err)
+	}
+	fmt.Println(string(buf))
+}
@@ -0,0 +1,86 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"github.com/khayrullo/cryptotrader/binance"
+	"log"
+	"strings"
+	"time"
+)
+
+var KlinesFlags struct {
+	Start  string
+	End    string
+	Output string
+}
+
+// parseDate parses a date (2006-01-02) or a full RFC3339 timestamp. An empty
+// string is the zero time.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func KlinesCommand(symbol string, interval string) {
+	start, err := parseDate(KlinesFlags.Start)
+	if err != nil {
+		log.Fatal("error: invalid start: ", err)
+	}
+	end, err := parseDate(KlinesFlags.End)
+	if err != nil {
+		log.Fatal("error: invalid end: ", err)
+	}
+
+	symbol = strings.ToUpper(symbol)
+	output := KlinesFlags.Output
+	if output == "" {
+		output = fmt.Sprintf("%s-%s.csv", symbol, interval)
+	}
+
+	backfill := binance.KlineBackfill{
+		Client:   binance.NewAnonymousClient(),
+		Symbol:   symbol,
+		Interval: interval,
+		Start:    start,
+		End:      end,
+		Filename: output,
+		Progress: func(count int, last binance.Kline) {
+			log.Printf("%s: %d klines, up to %s", output, count,
+				last.OpenTime().UTC().Format("2006-01-02 15:04"))
+		},
+	}
+	count, err := backfill.Run()
+	if err != nil {
+		log.Fatal("error: ", err)
+	}
+	log.Printf("%s: done, %d klines written", output, count)
+}
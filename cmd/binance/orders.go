@@ -0,0 +1,128 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"github.com/khayrullo/cryptotrader/binance"
+	"github.com/khayrullo/cryptotrader/util"
+	"log"
+	"strconv"
+	"strings"
+)
+
+var OrdersFlags struct {
+	Format   string
+	History  bool
+	Start    string
+	End      string
+	ClientID bool
+}
+
+func OrdersListCommand(args []string) {
+	client := getClient()
+
+	symbol := ""
+	if len(args) > 0 {
+		symbol = strings.ToUpper(args[0])
+	}
+
+	var orders []binance.QueryOrderResponse
+	var err error
+	if OrdersFlags.History {
+		if symbol == "" {
+			log.Fatal("error: --history requires a symbol")
+		}
+		start, err := parseDate(OrdersFlags.Start)
+		if err != nil {
+			log.Fatal("error: invalid start: ", err)
+		}
+		end, err := parseDate(OrdersFlags.End)
+		if err != nil {
+			log.Fatal("error: invalid end: ", err)
+		}
+		orders, err = client.GetAllOrders(symbol, start, end)
+		if err != nil {
+			log.Fatal("error: ", err)
+		}
+	} else {
+		orders, err = client.GetOpenOrders(symbol)
+		if err != nil {
+			log.Fatal("error: ", err)
+		}
+	}
+
+	for _, order := range orders {
+		switch OrdersFlags.Format {
+		case "json":
+			printJSON(order)
+		default:
+			fmt.Printf("%s  %-8s %-10d %-4s %-17s %-16s price: %s  qty: %s  executed: %s  client: %s\n",
+				util.MillisToTime(order.TimeMillis).Format("2006-01-02 15:04:05"),
+				order.Symbol,
+				order.OrderId,
+				order.Side,
+				order.Type,
+				order.Status,
+				strconv.FormatFloat(order.Price, 'f', -1, 64),
+				strconv.FormatFloat(order.OrigQty, 'f', -1, 64),
+				strconv.FormatFloat(order.ExecutedQty, 'f', -1, 64),
+				order.ClientOrderId)
+		}
+	}
+}
+
+func OrdersCancelCommand(symbol string, id string) {
+	client := getClient()
+	symbol = strings.ToUpper(symbol)
+
+	var response *binance.CancelOrderResponse
+	var err error
+	if OrdersFlags.ClientID {
+		response, err = client.CancelOrderByClientId(symbol, id)
+	} else {
+		orderID, parseErr := strconv.ParseInt(id, 10, 64)
+		if parseErr != nil {
+			log.Fatalf("error: invalid order ID: %s", id)
+		}
+		response, err = client.CancelOrder(symbol, orderID)
+	}
+	if err != nil {
+		log.Fatal("error: ", err)
+	}
+	printJSON(response)
+}
+
+func OrdersCancelAllCommand(symbol string) {
+	client := getClient()
+	responses, err := client.CancelAllOpenOrders(strings.ToUpper(symbol))
+	if err != nil {
+		log.Fatal("error: ", err)
+	}
+	for _, response := range responses {
+		printJSON(response)
+	}
+	log.Printf("Canceled %d orders", len(responses))
+}
@@ -0,0 +1,202 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"github.com/khayrullo/cryptotrader/binance"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+var TradesFlags struct {
+	Format     string
+	Start      string
+	Checkpoint string
+}
+
+type trade struct {
+	Timestamp time.Time `json:"timestamp"`
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	Type      string    `json:"type"`
+	Pair      string    `json:"pair"`
+	Price     float64   `json:"price"`
+	Cost      float64   `json:"cost"`
+	Fee       float64   `json:"fee"`
+	FeeAsset  string    `json:"fee_asset"`
+	Volume    float64   `json:"volume"`
+}
+
+func TradesCommand(args []string) {
+	client := getClient()
+
+	start, err := parseDate(TradesFlags.Start)
+	if err != nil {
+		log.Fatal("error: invalid start: ", err)
+	}
+
+	info, err := client.GetExchangeInfo()
+	if err != nil {
+		log.Fatal("error: failed to get exchange info: ", err)
+	}
+	pairs := map[string]string{}
+	for _, symbol := range info.Symbols {
+		pairs[symbol.Symbol] = fmt.Sprintf("%s/%s", symbol.BaseAsset,
+			symbol.QuoteAsset)
+	}
+
+	symbols := []string{}
+	if len(args) > 0 {
+		for _, arg := range args {
+			symbol := strings.ToUpper(arg)
+			if _, ok := pairs[symbol]; !ok {
+				log.Fatal("error: unknown symbol: ", symbol)
+			}
+			symbols = append(symbols, symbol)
+		}
+	} else {
+		account, err := client.GetAccount()
+		if err != nil {
+			log.Fatal("error: failed to get account: ", err)
+		}
+		transferred, err := transferredCoins(client)
+		if err != nil {
+			log.Fatal("error: ", err)
+		}
+		for _, symbol := range binance.TradedSymbols(account, transferred, info) {
+			symbols = append(symbols, symbol.Symbol)
+		}
+	}
+
+	// When resuming the header has already been written.
+	count := 0
+	if TradesFlags.Checkpoint != "" {
+		if _, err := os.Stat(TradesFlags.Checkpoint); err == nil {
+			count = 1
+		}
+	}
+
+	export := binance.TradeExport{
+		Client:             client,
+		Symbols:            symbols,
+		Start:              start,
+		CheckpointFilename: TradesFlags.Checkpoint,
+		OnTrades: func(symbol string, trades []binance.TradeResponse) error {
+			for _, response := range trades {
+				t := trade{
+					Timestamp: response.Time(),
+					ID:        response.ID,
+					OrderID:   response.OrderID,
+					Type:      "Sell",
+					Pair:      pairs[symbol],
+					Price:     response.Price,
+					Cost:      response.QuoteQuantity,
+					Fee:       response.Commission,
+					FeeAsset:  response.CommissionAsset,
+					Volume:    response.Quantity,
+				}
+				if response.IsBuyer {
+					t.Type = "Buy"
+				}
+
+				switch TradesFlags.Format {
+				case "json":
+					printJSON(t)
+				case "csv":
+					renderTradeDelim(count, t, ",")
+				case "tab":
+					renderTradeDelim(count, t, "\t")
+				default:
+					fmt.Printf("Timestamp: %s; "+
+						"Action: %-4s; "+
+						"Pair: %s; "+
+						"Amount: %.8f; "+
+						"Price: %.8f; "+
+						"Cost: %.8f; "+
+						"Fee: %.8f %s\n",
+						t.Timestamp.Format("2006-01-02 15:04:05"),
+						t.Type,
+						t.Pair,
+						t.Volume,
+						t.Price,
+						t.Cost,
+						t.Fee, t.FeeAsset)
+				}
+				count++
+			}
+			return nil
+		},
+	}
+
+	if _, err := export.Run(); err != nil {
+		log.Fatal("error: ", err)
+	}
+}
+
+// transferredCoins returns the coins ever deposited or withdrawn.
+func transferredCoins(client *binance.RestClient) ([]string, error) {
+	deposits, err := client.GetDepositHistory("", binanceLaunch, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deposits: %w", err)
+	}
+	withdrawals, err := client.GetWithdrawHistory("", binanceLaunch, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawals: %w", err)
+	}
+	coins := []string{}
+	for _, deposit := range deposits {
+		coins = append(coins, deposit.Coin)
+	}
+	for _, withdrawal := range withdrawals {
+		coins = append(coins, withdrawal.Coin)
+	}
+	return coins, nil
+}
+
+func renderTradeDelim(i int, trade trade, delim string) {
+	if i == 0 {
+		header := []string{
+			"timestamp",
+			"type",
+			"pair",
+			"cost",
+			"fee",
+			"volume",
+		}
+		fmt.Printf("%s\n", strings.Join(header, delim))
+	}
+	parts := []string{
+		trade.Timestamp.Format("2006-01-02 15:04:05"),
+		trade.Type,
+		trade.Pair,
+		fmt.Sprintf("%.8f", trade.Cost),
+		fmt.Sprintf("%.8f", trade.Fee),
+		fmt.Sprintf("%.8f", trade.Volume),
+	}
+	fmt.Printf("%s\n", strings.Join(parts, delim))
+}
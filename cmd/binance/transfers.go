@@ -0,0 +1,166 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package binance
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+var TransfersFlags struct {
+	Format string
+	Start  string
+}
+
+// Binance opened in July 2017, there is no history before.
+var binanceLaunch = time.Date(2017, 7, 1, 0, 0, 0, 0, time.UTC)
+
+type transfer struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Coin      string    `json:"coin"`
+	Amount    float64   `json:"amount"`
+	Fee       float64   `json:"fee"`
+	Status    string    `json:"status"`
+	Network   string    `json:"network"`
+	TxID      string    `json:"txid"`
+}
+
+func TransfersCommand(args []string) {
+	client := getClient()
+
+	start := binanceLaunch
+	if TransfersFlags.Start != "" {
+		var err error
+		start, err = parseDate(TransfersFlags.Start)
+		if err != nil {
+			log.Fatal("error: invalid start: ", err)
+		}
+	}
+
+	coins := args
+	if len(coins) == 0 {
+		// All coins.
+		coins = []string{""}
+	}
+
+	transfers := []transfer{}
+	for _, coin := range coins {
+		coin = strings.ToUpper(coin)
+		deposits, err := client.GetDepositHistory(coin, start, time.Time{})
+		if err != nil {
+			log.Fatal("error: failed to get deposits: ", err)
+		}
+		for _, deposit := range deposits {
+			transfers = append(transfers, transfer{
+				Timestamp: deposit.Timestamp(),
+				Type:      "deposit",
+				Coin:      deposit.Coin,
+				Amount:    deposit.Amount,
+				Status:    deposit.Status.String(),
+				Network:   deposit.Network,
+				TxID:      deposit.TxID,
+			})
+		}
+
+		withdrawals, err := client.GetWithdrawHistory(coin, start, time.Time{})
+		if err != nil {
+			log.Fatal("error: failed to get withdrawals: ", err)
+		}
+		for _, withdrawal := range withdrawals {
+			timestamp, err := withdrawal.Timestamp()
+			if err != nil {
+				log.Fatalf("error: failed to parse timestamp: %s", withdrawal.ApplyTime)
+			}
+			transfers = append(transfers, transfer{
+				Timestamp: timestamp,
+				Type:      "withdrawal",
+				Coin:      withdrawal.Coin,
+				Amount:    withdrawal.Amount,
+				Fee:       withdrawal.TransactionFee,
+				Status:    withdrawal.Status.String(),
+				Network:   withdrawal.Network,
+				TxID:      withdrawal.TxID,
+			})
+		}
+	}
+
+	sort.SliceStable(transfers, func(i, j int) bool {
+		return transfers[i].Timestamp.Before(transfers[j].Timestamp)
+	})
+
+	for i, transfer := range transfers {
+		switch TransfersFlags.Format {
+		case "json":
+			printJSON(transfer)
+		case "csv":
+			renderTransferDelim(i, transfer, ",")
+		case "tab":
+			renderTransferDelim(i, transfer, "\t")
+		default:
+			fmt.Printf("Timestamp: %s, "+
+				"Coin: %s, Type: %s, "+
+				"Status: %s, "+
+				"Amount: %.8f, Fee: %.8f\n",
+				transfer.Timestamp.Format("2006-01-02 15:04:05"),
+				transfer.Coin,
+				transfer.Type,
+				transfer.Status,
+				transfer.Amount,
+				transfer.Fee,
+			)
+		}
+	}
+}
+
+func renderTransferDelim(i int, transfer transfer, delim string) {
+	if i == 0 {
+		header := []string{
+			"timestamp",
+			"type",
+			"coin",
+			"amount",
+			"fee",
+			"status",
+			"network",
+			"txid",
+		}
+		fmt.Printf("%s\n", strings.Join(header, delim))
+	}
+	parts := []string{
+		transfer.Timestamp.Format("2006-01-02 15:04:05"),
+		transfer.Type,
+		transfer.Coin,
+		fmt.Sprintf("%.8f", transfer.Amount),
+		fmt.Sprintf("%.8f", transfer.Fee),
+		transfer.Status,
+		transfer.Network,
+		transfer.TxID,
+	}
+	fmt.Printf("%s\n", strings.Join(parts, delim))
+}
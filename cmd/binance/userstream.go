@@ -26,11 +26,21 @@ package binance
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/khayrullo/cryptotrader/binance"
 	"github.com/spf13/viper"
 	"log"
 )
 
+func printEvent(event interface{}) {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("error: failed to encode event: %v", err)
+		return
+	}
+	fmt.Println(string(buf))
+}
+
 func BinanceUserStreamCommand() {
 	apiKey := viper.GetString("binance.api.key")
 
@@ -40,23 +50,25 @@ func BinanceUserStreamCommand() {
 
 	restClient := binance.NewAuthenticatedClient(apiKey, "")
 
-	streamClient, err := binance.OpenUserStream(restClient)
-	if err != nil {
-		log.Fatal("error: failed to open user stream: %v", err)
-	}
-
-	for {
-		_, body, err := streamClient.Next()
+	stream := binance.NewUserStream(restClient)
+	stream.OnConnectionState(func(state binance.StreamEventType, err error) {
 		if err != nil {
-			log.Fatal("error: failed to read next message: %v", err)
+			log.Printf("User stream %s: %v", state, err)
+		} else {
+			log.Printf("User stream %s", state)
 		}
-
-		var rawOrderUpdate binance.StreamExecutionReport
-		err = json.Unmarshal(body, &rawOrderUpdate)
-		if err == nil {
-			x := binance.StreamExecutionReport(rawOrderUpdate)
-			log.Printf("%+v\n", rawOrderUpdate)
-			log.Printf("%+v\n", x)
-		}
-	}
+	})
+	stream.OnExecutionReport(func(report *binance.StreamExecutionReport) {
+		printEvent(report)
+	})
+	stream.OnAccountInfo(func(info *binance.StreamOutboundAccountInfo) {
+		printEvent(info)
+	})
+	stream.OnAccountPosition(func(position *binance.StreamOutboundAccountPosition) {
+		printEvent(position)
+	})
+	stream.OnBalanceUpdate(func(update *binance.StreamBalanceUpdate) {
+		printEvent(update)
+	})
+	stream.Run()
 }
@@ -41,7 +41,7 @@ func Main(args []string) {
 	client := gdax.NewFeedClient()
 	for {
 		if err := client.Connect(); err != nil {
-			log.Printf("error: failed to connect: ", err)
+			log.Printf("error: failed to connect: %v", err)
 			time.Sleep(1 * time.Second)
 		} else {
 			break
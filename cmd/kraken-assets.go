@@ -0,0 +1,61 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/kraken"
+	"github.com/spf13/cobra"
+)
+
+var krakenAssetsCmd = &cobra.Command{
+	Use:   "assets [pair...]",
+	Short: "List pairs and their precision",
+	Long: `List the pairs, or the given pairs, with their Kraken names and
+precision.
+
+Pairs can be given by their Kraken name (XXBTZUSD), alternate name
+(XBTUSD), websocket name (XBT/USD) or normalized name (BTC/USD).
+
+The asset and pair metadata is cached for a day in the user cache
+directory. Use --refresh to reload it now.
+
+Available output formats:
+  - json
+  - default
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		kraken.AssetsCommand(args)
+	},
+}
+
+func init() {
+	krakenCmd.AddCommand(krakenAssetsCmd)
+
+	flags := krakenAssetsCmd.Flags()
+	flags.BoolVar(&kraken.AssetsFlags.Refresh, "refresh", false,
+		"Reload the asset and pair metadata from Kraken")
+	flags.StringVar(&kraken.AssetsFlags.Format, "format", "",
+		"Display format (json, default)")
+}
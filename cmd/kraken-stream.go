@@ -0,0 +1,66 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/khayrullo/cryptotrader/cmd/kraken"
+	"github.com/spf13/cobra"
+)
+
+var krakenStreamCmd = &cobra.Command{
+	Use:   "stream <channel> [pair...]",
+	Short: "Stream websocket messages",
+	Long: `Stream websocket messages of a channel, reconnecting as needed.
+
+Public channels take one or more pairs by websocket name, ie: XBT/USD:
+  - ticker
+  - ohlc (--interval)
+  - trade
+  - spread
+  - book (--depth)
+
+Private channels take no pairs and require an api key and secret:
+  - ownTrades
+  - openOrders
+
+Example:
+
+    cryptotrader kraken stream book --depth 25 XBT/USD ETH/USD
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kraken.StreamCommand(args[0], args[1:])
+	},
+}
+
+func init() {
+	krakenCmd.AddCommand(krakenStreamCmd)
+
+	flags := krakenStreamCmd.Flags()
+	flags.IntVar(&kraken.StreamFlags.Interval, "interval", 1,
+		"OHLC interval in minutes")
+	flags.IntVar(&kraken.StreamFlags.Depth, "depth", 10,
+		"Book depth (10, 25, 100, 500 or 1000)")
+}
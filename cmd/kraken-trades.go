@@ -31,7 +31,10 @@ import (
 
 var krakenTradesCmd = &cobra.Command{
 	Use: "trades",
-	Long: `Print trades.
+	Long: `Print trades, oldest first.
+
+Use --start and --end to only print trades in a date range, given as a date
+(2006-01-02) or an RFC3339 timestamp.
 
 Available output formats:
   - tab
@@ -49,4 +52,6 @@ func init() {
 
 	krakenTradesCmd.Flags().Bool("reverse", false, "Display in reverse order.")
 	krakenTradesCmd.Flags().String("format", "", "Output format (ie: csv, tab, ...)")
+	krakenTradesCmd.Flags().String("start", "", "Only print trades after this date.")
+	krakenTradesCmd.Flags().String("end", "", "Only print trades before this date.")
 }
@@ -0,0 +1,115 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/khayrullo/cryptotrader/kraken"
+	"github.com/spf13/viper"
+	"log"
+	"strings"
+	"time"
+)
+
+// How long the cached asset registry is used before being refreshed.
+const assetCacheMaxAge = 24 * time.Hour
+
+var AssetsFlags struct {
+	Refresh bool
+	Format  string
+}
+
+// loadAssetRegistry loads the asset registry and sets it as the default so
+// asset and pair names are normalized with it. On failure names are guessed.
+func loadAssetRegistry(client *kraken.Client) *kraken.AssetRegistry {
+	registry := newAssetRegistry(client)
+	if err := registry.Load(assetCacheMaxAge); err != nil {
+		log.Println("warning: failed to load kraken assets: ", err)
+		return registry
+	}
+	kraken.SetDefaultAssetRegistry(registry)
+	return registry
+}
+
+func newAssetRegistry(client *kraken.Client) *kraken.AssetRegistry {
+	filename, err := kraken.DefaultAssetCacheFilename()
+	if err != nil {
+		log.Println("warning: no cache directory for kraken assets: ", err)
+	}
+	return kraken.NewAssetRegistry(client, filename)
+}
+
+func AssetsCommand(args []string) {
+	client := kraken.NewClient(viper.GetString("kraken.api.key"),
+		viper.GetString("kraken.api.secret"))
+
+	var registry *kraken.AssetRegistry
+	if AssetsFlags.Refresh {
+		registry = newAssetRegistry(client)
+		if err := registry.Refresh(); err != nil {
+			log.Fatal("error: ", err)
+		}
+		kraken.SetDefaultAssetRegistry(registry)
+	} else {
+		registry = loadAssetRegistry(client)
+	}
+
+	pairs := registry.Pairs()
+	if len(args) > 0 {
+		pairs = []kraken.PairInfo{}
+		for _, arg := range args {
+			pair, ok := registry.Pair(strings.ToUpper(arg))
+			if !ok {
+				log.Fatal("error: unknown pair: ", arg)
+			}
+			pairs = append(pairs, pair)
+		}
+	}
+
+	switch AssetsFlags.Format {
+	case "json":
+		for _, pair := range pairs {
+			printJSON(pair)
+		}
+	case "":
+		for _, pair := range pairs {
+			fmt.Printf("%-12s %-12s %-12s %-10s "+
+				"PairDecimals: %d; LotDecimals: %d; OrderMin: %g\n",
+				pair.Symbol(), pair.Name, pair.WSName, pair.AltName,
+				pair.PairDecimals, pair.LotDecimals, pair.OrderMin)
+		}
+	default:
+		log.Fatal("error: unknown format: ", AssetsFlags.Format)
+	}
+}
+
+func printJSON(v interface{}) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		log.Fatal("error: ", err)
+	}
+	fmt.Println(string(buf))
+}
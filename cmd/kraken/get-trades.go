@@ -28,11 +28,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/khayrullo/cryptotrader/kraken"
-	"github.com/khayrullo/cryptotrader/util"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"log"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -47,7 +45,14 @@ type Trade struct {
 	Fee2      float64
 	Volume    string
 
-	raw interface{}
+	raw rawTrade
+}
+
+// rawTrade is a trade as returned by Kraken, with the trade ID that Kraken
+// sends as its key.
+type rawTrade struct {
+	ID string `json:"id"`
+	kraken.RawTrade
 }
 
 func KrakenGetTrades(opts *pflag.FlagSet, args []string) {
@@ -56,76 +61,49 @@ func KrakenGetTrades(opts *pflag.FlagSet, args []string) {
 
 	client := kraken.NewClient(viper.GetString("kraken.api.key"),
 		viper.GetString("kraken.api.secret"))
+	loadAssetRegistry(client)
 
-	params := map[string]interface{}{}
-
-	//params["start"] = 1515980360
-	//params["end"] = 1517276414
-	//params["ofs"] = 2
-
-	response, err := client.Post("/0/private/TradesHistory", params)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	body := map[string]interface{}{}
-
-	decoder := json.NewDecoder(response.Body)
-	decoder.UseNumber()
-	if err := decoder.Decode(&body); err != nil {
-		log.Fatal("error: failed to decode response: ", err)
+	options := kraken.GetTradesOptions{}
+	var err error
+	start, _ := opts.GetString("start")
+	if options.Start, err = parseDate(start); err != nil {
+		log.Fatal("error: invalid start: ", err)
 	}
-
-	// Check for errors.
-	errors, ok := body["error"].([]interface{})
-	if ok {
-		if len(errors) > 0 {
-			log.Fatal("error: ", errors)
-		}
+	end, _ := opts.GetString("end")
+	if options.End, err = parseDate(end); err != nil {
+		log.Fatal("error: invalid end: ", err)
 	}
 
-	rawTrades, ok := body["result"].(map[string]interface{})["trades"]
-	if !ok {
-		log.Fatal("error: failed to find trades in response")
+	response, err := kraken.NewTradesService(client).Trades(options)
+	if err != nil {
+		log.Fatal("error: ", err)
 	}
 
 	trades := []Trade{}
 
-	for key, trade := range rawTrades.(map[string]interface{}) {
-		trade := trade.(map[string]interface{})
-
-		timestamp, err := util.JsonNumberToTime(trade["time"].(json.Number))
-		if err != nil {
-			log.Fatal("error: failed to parse timestamp: %v", trade["time"])
-		}
-
-		trade["id"] = key
-		trade["timestamp"] = timestamp
-
-		ffee, _ := strconv.ParseFloat(trade["fee"].(string), 64)
-		sfee := fmt.Sprintf("%.4f", ffee)
-		ffee, _ = strconv.ParseFloat(sfee, 64)
+	for _, trade := range response {
+		sfee := fmt.Sprintf("%.4f", trade.Fee)
+		ffee, _ := strconv.ParseFloat(sfee, 64)
 
 		xtrade := Trade{
-			Timestamp: timestamp,
-			Pair:      kraken.GetNormalizePairName(trade["pair"].(string)),
-			Type:      strings.Title(trade["type"].(string)),
-			Cost:      trade["cost"].(string),
-			Fee:       trade["fee"].(string),
+			Timestamp: trade.Timestamp,
+			Pair:      kraken.GetNormalizePairName(trade.Pair),
+			Type:      strings.Title(string(trade.Side)),
+			Cost:      trade.Raw.Cost,
+			Fee:       trade.Raw.Fee,
 			Fee2:      ffee,
-			Volume:    trade["vol"].(string),
-			raw:       trade,
+			Volume:    trade.Raw.Volume,
+			raw:       rawTrade{ID: trade.TradeID, RawTrade: trade.Raw},
 		}
 
 		trades = append(trades, xtrade)
 	}
 
-	sort.Slice(trades, func(i, j int) bool {
-		if reverse, _ := opts.GetBool("reverse"); reverse {
-			i, j = j, i
+	if reverse, _ := opts.GetBool("reverse"); reverse {
+		for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+			trades[i], trades[j] = trades[j], trades[i]
 		}
-		return trades[i].Timestamp.Before(trades[j].Timestamp)
-	})
+	}
 
 	for i, trade := range trades {
 		switch format {
@@ -171,3 +149,15 @@ func KrakenGetTrades(opts *pflag.FlagSet, args []string) {
 	}
 
 }
+
+// parseDate parses a date (2006-01-02) or a full RFC3339 timestamp. An empty
+// string is the zero time.
+func parseDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
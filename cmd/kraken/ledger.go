@@ -46,6 +46,7 @@ func KrakenLedgerCmd() {
 	client := kraken.NewClient(
 		viper.GetString("kraken.api.key"),
 		viper.GetString("kraken.api.secret"))
+	loadAssetRegistry(client)
 
 	count := KrakenLedgerFlags.Count
 	if count > 0 && KrakenLedgerFlags.Merged {
@@ -0,0 +1,94 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"fmt"
+	"github.com/khayrullo/cryptotrader/kraken"
+	"github.com/spf13/viper"
+	"log"
+)
+
+var StreamFlags struct {
+	Interval int
+	Depth    int
+}
+
+func StreamCommand(channel string, pairs []string) {
+	var subscription kraken.Subscription
+	switch channel {
+	case kraken.ChannelTicker:
+		subscription = kraken.TickerSubscription()
+	case kraken.ChannelOHLC:
+		subscription = kraken.OHLCSubscription(StreamFlags.Interval)
+	case kraken.ChannelTrade:
+		subscription = kraken.TradeSubscription()
+	case kraken.ChannelSpread:
+		subscription = kraken.SpreadSubscription()
+	case kraken.ChannelBook:
+		subscription = kraken.BookSubscription(StreamFlags.Depth)
+	case kraken.ChannelOwnTrades:
+		subscription = kraken.OwnTradesSubscription()
+	case kraken.ChannelOpenOrders:
+		subscription = kraken.OpenOrdersSubscription()
+	default:
+		log.Fatal("error: unknown channel: ", channel)
+	}
+
+	var ws *kraken.ManagedWebSocket
+	if subscription.IsPrivate() {
+		apiKey := viper.GetString("kraken.api.key")
+		apiSecret := viper.GetString("kraken.api.secret")
+		if apiKey == "" || apiSecret == "" {
+			log.Fatal("error: private channels require an api key and secret")
+		}
+		ws = kraken.NewManagedPrivateWebSocket(kraken.NewClient(apiKey, apiSecret))
+	} else {
+		if len(pairs) == 0 {
+			log.Fatal("error: no pairs provided")
+		}
+		ws = kraken.NewManagedWebSocket()
+	}
+
+	if err := ws.Subscribe(subscription, pairs...); err != nil {
+		log.Fatal("error: ", err)
+	}
+
+	events := make(chan kraken.WebSocketEvent)
+	go ws.Run(events)
+
+	for event := range events {
+		switch event.Type {
+		case kraken.WebSocketEventConnected:
+			log.Println("Connected!")
+		case kraken.WebSocketEventDisconnected:
+			log.Printf("Disconnected, will reconnect: %v", event.Err)
+		case kraken.WebSocketEventError:
+			log.Printf("error: %v", event.Err)
+		case kraken.WebSocketEventMessage:
+			fmt.Printf("%s\n", event.Message.Raw)
+		}
+	}
+}
@@ -48,6 +48,7 @@ func Ticker(args []string) {
 
 	client := kraken.NewClient(viper.GetString("kraken.api.key"),
 		viper.GetString("kraken.api.secret"))
+	loadAssetRegistry(client)
 
 	for {
 		ticker, err := client.Ticker(args...)
@@ -138,7 +138,7 @@ func renderDefault(trade *kucoin.Trade) {
 func renderRaw(trade *kucoin.Trade) {
 	buf, err := json.Marshal(trade)
 	if err != nil {
-		log.Fatal("error: failed to render trade: %v", err)
+		log.Fatalf("error: failed to render trade: %v", err)
 	}
 	fmt.Printf("%s\n", buf)
 }
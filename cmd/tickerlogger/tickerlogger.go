@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/khayrullo/cryptotrader/binance"
+	"github.com/khayrullo/cryptotrader/core"
 	"github.com/khayrullo/cryptotrader/kraken"
 	"log"
 	"strings"
@@ -30,16 +31,6 @@ var Flags struct {
 	OnTheMinute bool
 }
 
-var Binance struct {
-	Client  *binance.RestClient
-	Symbols []string
-}
-
-var Kraken struct {
-	Client  *kraken.Client
-	Symbols []string
-}
-
 type NormalizedTicker struct {
 	Timestamp time.Time
 	Exchange  string
@@ -47,7 +38,16 @@ type NormalizedTicker struct {
 	Price     float64
 }
 
+type exchangeSymbols struct {
+	Exchange core.Exchange
+	Symbols  []string
+
+	// Normalize, if set, maps a native symbol to the name it is logged as.
+	Normalize func(symbol string) string
+}
+
 func TickerLoggerCommand(args []string) {
+	exchanges := map[string]*exchangeSymbols{}
 
 	for _, arg := range args {
 		parts := strings.SplitN(arg, ":", 2)
@@ -55,25 +55,26 @@ func TickerLoggerCommand(args []string) {
 			log.Fatalf("error: invalid symbols %s (format: exchange:symbols)",
 				arg)
 		}
-		exchange := parts[0]
+		name := strings.ToUpper(parts[0])
 		symbols := parts[1]
-		switch strings.ToUpper(exchange) {
-		case "KRAKEN":
-			if Kraken.Client == nil {
-				Kraken.Client = kraken.NewClient("", "")
-			}
-			for _, symbol := range strings.Split(symbols, ",") {
-				Kraken.Symbols = append(Kraken.Symbols, strings.ToUpper(symbol))
-			}
-		case "BINANCE":
-			if Binance.Client == nil {
-				Binance.Client = binance.NewAnonymousClient()
-			}
-			for _, symbol := range strings.Split(symbols, ",") {
-				Binance.Symbols = append(Binance.Symbols, strings.ToUpper(symbol))
+		if _, ok := exchanges[name]; !ok {
+			switch name {
+			case "KRAKEN":
+				exchanges[name] = &exchangeSymbols{
+					Exchange:  kraken.NewExchange(kraken.NewClient("", "")),
+					Normalize: kraken.GetNormalizePairName,
+				}
+			case "BINANCE":
+				exchanges[name] = &exchangeSymbols{
+					Exchange: binance.NewExchange(binance.NewAnonymousClient()),
+				}
+			default:
+				log.Fatalf("error: exchange not supported: %s", parts[0])
 			}
-		default:
-			log.Fatalf("error: exchange not supported: %s", exchange)
+		}
+		for _, symbol := range strings.Split(symbols, ",") {
+			exchanges[name].Symbols = append(exchanges[name].Symbols,
+				strings.ToUpper(symbol))
 		}
 	}
 
@@ -89,72 +90,39 @@ func TickerLoggerCommand(args []string) {
 		}
 	}()
 
-	// Kraken loop.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			sleep()
-			now := time.Now()
-			tickers, err := Kraken.Client.Ticker(Kraken.Symbols...)
-			if err != nil {
-				log.Println("kraken error: ", err)
-			} else {
-				for _, tick := range tickers {
-					normalizedTicker := NormalizedTicker{
-						Timestamp: now,
-						Exchange:  "Kraken",
-						Symbol:    tick.Pair,
-						Price:     tick.Last,
-					}
-					logChannel <- normalizedTicker
+	// One loop per exchange.
+	for _, exchange := range exchanges {
+		wg.Add(1)
+		go func(exchange *exchangeSymbols) {
+			defer wg.Done()
+			for {
+				sleep()
+				now := time.Now()
+				tickers, err := exchange.Exchange.Tickers(exchange.Symbols...)
+				if err != nil {
+					log.Printf("%s error: %v",
+						strings.ToLower(exchange.Exchange.Name()), err)
+					continue
 				}
-			}
-		}
-	}()
-
-	// Binance loop.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if len(Binance.Symbols) < 1 {
-			return
-		}
-		for {
-			sleep()
-			now := time.Now()
-			tickers, err := Binance.Client.GetAllPriceTicker()
-			if err != nil {
-				log.Println("binance error: ", err)
-			} else {
 				for _, tick := range tickers {
-					if !symbolMatch(tick.Symbol, Binance.Symbols) {
-						continue
+					symbol := tick.Symbol
+					if exchange.Normalize != nil {
+						symbol = exchange.Normalize(symbol)
 					}
-					normalizedTicker := NormalizedTicker{
+					logChannel <- NormalizedTicker{
 						Timestamp: now,
-						Exchange:  "Binance",
-						Symbol:    tick.Symbol,
-						Price:     tick.Price,
+						Exchange:  exchange.Exchange.Name(),
+						Symbol:    symbol,
+						Price:     tick.Last,
 					}
-					logChannel <- normalizedTicker
 				}
 			}
-		}
-	}()
+		}(exchange)
+	}
 
 	wg.Wait()
 }
 
-func symbolMatch(symbol string, symbols []string) bool {
-	for _, s := range symbols {
-		if symbol == s {
-			return true
-		}
-	}
-	return false
-}
-
 func sleep() {
 	if Flags.Interval > 0 {
 		time.Sleep(time.Duration(Flags.Interval) * time.Second)
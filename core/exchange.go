@@ -0,0 +1,168 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package core
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotSupported is returned by an Exchange implementation when the
+// underlying exchange, or our client for it, does not support the operation.
+var ErrNotSupported = errors.New("operation not supported by exchange")
+
+type OrderSide string
+
+const (
+	OrderSideBuy  OrderSide = "BUY"
+	OrderSideSell OrderSide = "SELL"
+)
+
+type OrderType string
+
+const (
+	OrderTypeLimit  OrderType = "LIMIT"
+	OrderTypeMarket OrderType = "MARKET"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+	OrderStatusUnknown         OrderStatus = "UNKNOWN"
+)
+
+// IsTerminal returns true if the order will not receive any further updates.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected,
+		OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+type Ticker struct {
+	Exchange  string
+	Symbol    string
+	Timestamp time.Time
+	Bid       float64
+	Ask       float64
+	Last      float64
+}
+
+type OrderBookEntry struct {
+	Price    float64
+	Quantity float64
+}
+
+type OrderBook struct {
+	Exchange  string
+	Symbol    string
+	Timestamp time.Time
+
+	// Bids are sorted from highest to lowest price, asks from lowest to
+	// highest.
+	Bids []OrderBookEntry
+	Asks []OrderBookEntry
+}
+
+type Balance struct {
+	Asset  string
+	Free   float64
+	Locked float64
+}
+
+func (b Balance) Total() float64 {
+	return b.Free + b.Locked
+}
+
+type OrderRequest struct {
+	Symbol        string
+	Side          OrderSide
+	Type          OrderType
+	Quantity      float64
+	Price         float64
+	ClientOrderID string
+}
+
+type Order struct {
+	Exchange      string
+	Symbol        string
+	OrderID       string
+	ClientOrderID string
+	Side          OrderSide
+	Type          OrderType
+	Status        OrderStatus
+	Price         float64
+	Quantity      float64
+	ExecutedQty   float64
+	Timestamp     time.Time
+}
+
+type Trade struct {
+	Exchange  string
+	Symbol    string
+	TradeID   string
+	OrderID   string
+	Side      OrderSide
+	Price     float64
+	Quantity  float64
+	Fee       float64
+	FeeAsset  string
+	Timestamp time.Time
+}
+
+// Exchange is the normalized interface implemented by an adapter in each
+// exchange package. Symbols are passed and returned in the native format of
+// the exchange, for example "ETHBTC" for Binance and "XETHXXBT" for Kraken.
+//
+// Operations an exchange can't perform return ErrNotSupported.
+type Exchange interface {
+	// Name returns the display name of the exchange, ie: "Binance".
+	Name() string
+
+	// Tickers returns the current ticker for each of the requested symbols.
+	Tickers(symbols ...string) ([]Ticker, error)
+
+	// OrderBook returns the order book for a symbol limited to depth
+	// levels on each side. A depth of 0 uses the exchange default.
+	OrderBook(symbol string, depth int) (*OrderBook, error)
+
+	// Balances returns the non-zero balances of the account.
+	Balances() ([]Balance, error)
+
+	PlaceOrder(order OrderRequest) (*Order, error)
+	CancelOrder(symbol string, orderID string) error
+	QueryOrder(symbol string, orderID string) (*Order, error)
+
+	// Trades returns the trade history of the account for a symbol.
+	Trades(symbol string) ([]Trade, error)
+}
@@ -0,0 +1,145 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package core
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPClient sends the requests of an exchange REST client with the
+// settings they all share: the API root, the http.Client, a User-Agent, a
+// timeout and an optional RateLimiter. Exchange clients embed it and wrap
+// the HTTPOption functions in their own options.
+type HTTPClient struct {
+	baseURL     string
+	client      *http.Client
+	userAgent   string
+	timeout     time.Duration
+	rateLimiter RateLimiter
+
+	// The client as set by WithHTTPClient, before the timeout is applied.
+	sharedClient *http.Client
+}
+
+// HTTPOption configures an HTTPClient.
+type HTTPOption func(*HTTPClient)
+
+// WithBaseURL sets the API root, for example to point the client at a
+// testnet or a local test server.
+func WithBaseURL(baseURL string) HTTPOption {
+	return func(c *HTTPClient) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests. The default is
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) HTTPOption {
+	return func(c *HTTPClient) {
+		c.sharedClient = httpClient
+		c.applyTimeout()
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request.
+func WithUserAgent(userAgent string) HTTPOption {
+	return func(c *HTTPClient) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout sets the time limit for each request, including reading the
+// response body.
+func WithTimeout(timeout time.Duration) HTTPOption {
+	return func(c *HTTPClient) {
+		c.timeout = timeout
+		c.applyTimeout()
+	}
+}
+
+// WithRateLimiter paces requests with the provided limiter. The limiter may
+// be shared by all the clients of an exchange using the same IP address or
+// API key.
+func WithRateLimiter(rateLimiter RateLimiter) HTTPOption {
+	return func(c *HTTPClient) {
+		c.rateLimiter = rateLimiter
+	}
+}
+
+// NewHTTPClient returns an HTTPClient for the API root baseURL, which
+// WithBaseURL may override.
+func NewHTTPClient(baseURL string, opts ...HTTPOption) HTTPClient {
+	c := HTTPClient{
+		baseURL:      baseURL,
+		client:       http.DefaultClient,
+		sharedClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// applyTimeout sets the client used to send requests. With a timeout the
+// http.Client is copied so the timeout doesn't leak into a client that may
+// be shared.
+func (c *HTTPClient) applyTimeout() {
+	c.client = c.sharedClient
+	if c.timeout > 0 {
+		client := *c.sharedClient
+		client.Timeout = c.timeout
+		c.client = &client
+	}
+}
+
+// BaseURL returns the API root the client sends requests to.
+func (c *HTTPClient) BaseURL() string {
+	return c.baseURL
+}
+
+// RateLimiter returns the limiter requests are paced with, or nil.
+func (c *HTTPClient) RateLimiter() RateLimiter {
+	return c.rateLimiter
+}
+
+// Do sends a request, waiting on the rate limiter first if there is one,
+// then updating it with the response.
+func (c *HTTPClient) Do(request *http.Request) (*http.Response, error) {
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.rateLimiter == nil {
+		return c.client.Do(request)
+	}
+	c.rateLimiter.Wait(request)
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	c.rateLimiter.Update(request, response)
+	return response, nil
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type countingLimiter struct {
+	waits   int
+	updates int
+}
+
+func (l *countingLimiter) Wait(request *http.Request) {
+	l.waits++
+}
+
+func (l *countingLimiter) Update(request *http.Request, response *http.Response) {
+	l.updates++
+}
+
+func TestHTTPClientDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "test-agent" {
+			t.Errorf("unexpected user agent: %s", r.Header.Get("User-Agent"))
+		}
+	}))
+	defer server.Close()
+
+	shared := &http.Client{}
+	limiter := &countingLimiter{}
+	// The timeout is set before the client it applies to.
+	client := NewHTTPClient("https://example.com", WithBaseURL(server.URL+"/"),
+		WithTimeout(time.Second), WithHTTPClient(shared),
+		WithUserAgent("test-agent"), WithRateLimiter(limiter))
+	if client.BaseURL() != server.URL {
+		t.Fatalf("unexpected base URL: %s", client.BaseURL())
+	}
+	if client.client == shared || client.client.Timeout != time.Second {
+		t.Fatalf("expected a copy of the client with the timeout")
+	}
+	if shared.Timeout != 0 {
+		t.Fatalf("timeout leaked into the shared client")
+	}
+
+	request, _ := http.NewRequest("GET", client.BaseURL(), nil)
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if limiter.waits != 1 || limiter.updates != 1 {
+		t.Fatalf("expected 1 wait and update, got %d and %d", limiter.waits,
+			limiter.updates)
+	}
+}
@@ -0,0 +1,138 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package core
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces the requests of a REST client. Wait is called before
+// each request is sent, and Update with the response so the limiter can
+// sync with any usage reported by the exchange.
+type RateLimiter interface {
+	Wait(request *http.Request)
+	Update(request *http.Request, response *http.Response)
+}
+
+// IntervalLimiter limits the total weight used in fixed windows of an
+// interval, aligned to the interval like the Binance limits are, ie: a
+// one minute window starts on the minute.
+type IntervalLimiter struct {
+	mu          sync.Mutex
+	limit       int64
+	interval    time.Duration
+	windowStart time.Time
+	used        int64
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewIntervalLimiter(limit int64, interval time.Duration) *IntervalLimiter {
+	return &IntervalLimiter{
+		limit:    limit,
+		interval: interval,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+func (l *IntervalLimiter) Limit() int64 {
+	return l.limit
+}
+
+func (l *IntervalLimiter) Interval() time.Duration {
+	return l.interval
+}
+
+// Used returns the weight used in the current window.
+func (l *IntervalLimiter) Used() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	return l.used
+}
+
+// SetUsed replaces the used weight of the current window, for example with
+// the usage reported in a response header.
+func (l *IntervalLimiter) SetUsed(used int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	l.used = used
+}
+
+// Acquire blocks until weight can be used without exceeding the limit of
+// the current window, then uses it. A weight larger than the limit is
+// allowed at the start of a window so it can't block forever.
+func (l *IntervalLimiter) Acquire(weight int64) {
+	for {
+		wait := l.reserve(weight)
+		if wait <= 0 {
+			return
+		}
+		l.sleep(wait)
+	}
+}
+
+// reserve uses the weight and returns 0 if it fits within the current
+// window, otherwise it returns the time until the next window.
+func (l *IntervalLimiter) reserve(weight int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.roll()
+	if l.used == 0 || l.used+weight <= l.limit {
+		l.used += weight
+		return 0
+	}
+	return l.windowStart.Add(l.interval).Sub(l.now())
+}
+
+func (l *IntervalLimiter) roll() {
+	windowStart := l.now().Truncate(l.interval)
+	if windowStart.After(l.windowStart) {
+		l.windowStart = windowStart
+		l.used = 0
+	}
+}
+
+// NewRequestLimiter returns a RateLimiter that allows limit requests per
+// interval, for exchanges that have a simple request count limit.
+func NewRequestLimiter(limit int64, interval time.Duration) RateLimiter {
+	return &requestLimiter{NewIntervalLimiter(limit, interval)}
+}
+
+type requestLimiter struct {
+	limiter *IntervalLimiter
+}
+
+func (l *requestLimiter) Wait(request *http.Request) {
+	l.limiter.Acquire(1)
+}
+
+func (l *requestLimiter) Update(request *http.Request, response *http.Response) {
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIntervalLimiter(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 10, 0, time.UTC)
+	slept := time.Duration(0)
+
+	limiter := NewIntervalLimiter(10, time.Minute)
+	limiter.now = func() time.Time {
+		return now
+	}
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	limiter.Acquire(6)
+	limiter.Acquire(4)
+	if slept != 0 {
+		t.Fatalf("expected no wait, waited %v", slept)
+	}
+
+	// The window is full so this waits for the next minute.
+	limiter.Acquire(1)
+	if slept != 50*time.Second {
+		t.Fatalf("expected to wait 50s, waited %v", slept)
+	}
+	if limiter.Used() != 1 {
+		t.Fatalf("expected 1 used, got %d", limiter.Used())
+	}
+
+	// Usage reported by the exchange replaces our own count.
+	limiter.SetUsed(10)
+	limiter.Acquire(1)
+	if slept != 110*time.Second {
+		t.Fatalf("expected to wait another 60s, waited %v", slept)
+	}
+}
@@ -26,24 +26,74 @@ package gdax
 
 import (
 	"net/http"
+	"time"
 	"fmt"
 	"io/ioutil"
 	"encoding/json"
 	"bytes"
+	"github.com/khayrullo/cryptotrader/core"
 )
 
 const API_ROOT = "https://api.gdax.com"
 
 type ApiClient struct {
+	core.HTTPClient
 }
 
-func NewApiClient() *ApiClient {
-	return &ApiClient{}
+// ClientOption configures optional settings of a ApiClient.
+type ClientOption func(*ApiClient)
+
+// WithBaseURL is core.WithBaseURL. The default is API_ROOT.
+func WithBaseURL(baseURL string) ClientOption {
+	return withHTTPOption(core.WithBaseURL(baseURL))
+}
+
+// WithHTTPClient is core.WithHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return withHTTPOption(core.WithHTTPClient(httpClient))
+}
+
+// WithUserAgent is core.WithUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return withHTTPOption(core.WithUserAgent(userAgent))
+}
+
+// WithTimeout is core.WithTimeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return withHTTPOption(core.WithTimeout(timeout))
+}
+
+// WithRateLimiter is core.WithRateLimiter.
+func WithRateLimiter(rateLimiter core.RateLimiter) ClientOption {
+	return withHTTPOption(core.WithRateLimiter(rateLimiter))
+}
+
+func withHTTPOption(opt core.HTTPOption) ClientOption {
+	return func(c *ApiClient) {
+		opt(&c.HTTPClient)
+	}
+}
+
+func (c *ApiClient) applyOptions(opts []ClientOption) {
+	c.HTTPClient = core.NewHTTPClient(API_ROOT)
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+func NewApiClient(opts ...ClientOption) *ApiClient {
+	client := &ApiClient{}
+	client.applyOptions(opts)
+	return client
 }
 
 func (c *ApiClient) Get(endpoint string) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
-	return http.Get(url)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(request)
 }
 
 type Product struct {
@@ -83,3 +133,48 @@ func parseResponse(response *http.Response, v interface{}) (string, error) {
 	err = decoder.Decode(v)
 	return string(raw), err
 }
+
+type ProductTicker struct {
+	Price float64   `json:"price,string"`
+	Bid   float64   `json:"bid,string"`
+	Ask   float64   `json:"ask,string"`
+	Time  time.Time `json:"time"`
+}
+
+type ProductBook struct {
+	Sequence int64           `json:"sequence"`
+	Bids     [][]interface{} `json:"bids"`
+	Asks     [][]interface{} `json:"asks"`
+}
+
+func (c *ApiClient) ProductTicker(product string) (*ProductTicker, error) {
+	endpoint := fmt.Sprintf("/products/%s/ticker", product)
+	var ticker ProductTicker
+	if err := c.getAndDecode(endpoint, &ticker); err != nil {
+		return nil, err
+	}
+	return &ticker, nil
+}
+
+// ProductBook returns the level 2 (aggregated) order book of a product.
+func (c *ApiClient) ProductBook(product string) (*ProductBook, error) {
+	endpoint := fmt.Sprintf("/products/%s/book?level=2", product)
+	var book ProductBook
+	if err := c.getAndDecode(endpoint, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (c *ApiClient) getAndDecode(endpoint string, v interface{}) error {
+	response, err := c.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", response.Status)
+	}
+	_, err = parseResponse(response, v)
+	return err
+}
@@ -0,0 +1,136 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gdax
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+const ExchangeName = "GDAX"
+
+var _ core.Exchange = (*Exchange)(nil)
+
+// Exchange adapts an ApiClient to the core.Exchange interface. Only the
+// public market data is available as the ApiClient is not authenticated.
+type Exchange struct {
+	client *ApiClient
+}
+
+func NewExchange(client *ApiClient) *Exchange {
+	return &Exchange{
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+func (e *Exchange) Tickers(products ...string) ([]core.Ticker, error) {
+	if len(products) == 0 {
+		all, err := e.client.Products()
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range all {
+			products = append(products, product.Id)
+		}
+	}
+	tickers := []core.Ticker{}
+	for _, product := range products {
+		ticker, err := e.client.ProductTicker(product)
+		if err != nil {
+			return nil, err
+		}
+		tickers = append(tickers, core.Ticker{
+			Exchange:  ExchangeName,
+			Symbol:    product,
+			Timestamp: ticker.Time,
+			Bid:       ticker.Bid,
+			Ask:       ticker.Ask,
+			Last:      ticker.Price,
+		})
+	}
+	return tickers, nil
+}
+
+func (e *Exchange) OrderBook(product string, depth int) (*core.OrderBook, error) {
+	book, err := e.client.ProductBook(product)
+	if err != nil {
+		return nil, err
+	}
+	return &core.OrderBook{
+		Exchange:  ExchangeName,
+		Symbol:    product,
+		Timestamp: time.Now(),
+		Bids:      parseBookEntries(book.Bids, depth),
+		Asks:      parseBookEntries(book.Asks, depth),
+	}, nil
+}
+
+func (e *Exchange) Balances() ([]core.Balance, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) PlaceOrder(order core.OrderRequest) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) CancelOrder(product string, orderID string) error {
+	return core.ErrNotSupported
+}
+
+func (e *Exchange) QueryOrder(product string, orderID string) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) Trades(product string) ([]core.Trade, error) {
+	return nil, core.ErrNotSupported
+}
+
+// Level 2 book entries are [price, size, num-orders] with the price and
+// size encoded as strings.
+func parseBookEntries(raw [][]interface{}, depth int) []core.OrderBookEntry {
+	entries := []core.OrderBookEntry{}
+	for _, level := range raw {
+		if depth > 0 && len(entries) == depth {
+			break
+		}
+		if len(level) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(fmt.Sprintf("%v", level[0]), 64)
+		size, _ := strconv.ParseFloat(fmt.Sprintf("%v", level[1]), 64)
+		entries = append(entries, core.OrderBookEntry{
+			Price:    price,
+			Quantity: size,
+		})
+	}
+	return entries
+}
@@ -24,44 +24,412 @@
 
 package kraken
 
-import "strings"
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
-var pairSubsTable [][]string
+// Kraken altnames that differ from the common symbol of the asset.
+var assetAliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+type AssetInfo struct {
+	// The Kraken name, ie: "XXBT".
+	Name string `json:"name"`
+
+	// The Kraken alternate name, ie: "XBT".
+	AltName string `json:"altname"`
+
+	// The normalized name, ie: "BTC".
+	Symbol string `json:"symbol"`
+
+	Decimals        int `json:"decimals"`
+	DisplayDecimals int `json:"display_decimals"`
+}
+
+type PairInfo struct {
+	// The Kraken name, ie: "XXBTZUSD".
+	Name string `json:"name"`
 
-var assetSubsTable [][]string
+	// The Kraken alternate name, ie: "XBTUSD".
+	AltName string `json:"altname"`
 
-func init() {
-	pairSubsTable = [][]string{
-		{"XXBTZ", "BTC/"},
-		{"XETHZ", "ETH/"},
-		{"XLTCZ", "LTC/"},
-		{"XXMRZ", "XMR/"},
-		{"XETCZ", "ETC/"},
-		{"BCH", "BCH/"},
+	// The websocket name, ie: "XBT/USD".
+	WSName string `json:"wsname"`
+
+	// The Kraken names of the base and quote assets, ie: "XXBT" and "ZUSD".
+	BaseAsset  string `json:"base_asset"`
+	QuoteAsset string `json:"quote_asset"`
+
+	// The normalized names of the base and quote assets, ie: "BTC" and
+	// "USD".
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+
+	PairDecimals int     `json:"pair_decimals"`
+	LotDecimals  int     `json:"lot_decimals"`
+	OrderMin     float64 `json:"ordermin"`
+}
+
+// Symbol returns the normalized name of the pair, ie: "BTC/USD".
+func (p PairInfo) Symbol() string {
+	return fmt.Sprintf("%s/%s", p.Base, p.Quote)
+}
+
+type RawAssetInfo struct {
+	AssetClass      string `json:"aclass"`
+	AltName         string `json:"altname"`
+	Decimals        int    `json:"decimals"`
+	DisplayDecimals int    `json:"display_decimals"`
+}
+
+type RawPairInfo struct {
+	AltName      string `json:"altname"`
+	WSName       string `json:"wsname"`
+	Base         string `json:"base"`
+	Quote        string `json:"quote"`
+	PairDecimals int    `json:"pair_decimals"`
+	LotDecimals  int    `json:"lot_decimals"`
+	OrderMin     string `json:"ordermin"`
+}
+
+func (c *Client) Assets() (map[string]RawAssetInfo, error) {
+	result := map[string]RawAssetInfo{}
+	if err := c.getAndDecode("/0/public/Assets", nil, &result); err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	assetSubsTable = [][]string{
-		{"XXBT", "BTC"},
-		{"XLTC", "LTC"},
-		{"XXMR", "XMR"},
-		{"XETH", "ETH"},
-		{"XZEC", "ZEC"},
-		{"ZUSD", "USD"},
+func (c *Client) AssetPairs() (map[string]RawPairInfo, error) {
+	result := map[string]RawPairInfo{}
+	if err := c.getAndDecode("/0/public/AssetPairs", nil, &result); err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
-func GetNormalizePairName(pair string) string {
-	for _, sub := range pairSubsTable {
-		pair = strings.Replace(pair, sub[0], sub[1], -1)
+// AssetRegistry maps between the Kraken names, alternate names and
+// websocket names of assets and pairs and their normalized form, ie: "BTC"
+// and "BTC/USD". It is loaded from the Assets and AssetPairs endpoints and
+// can be cached in a file.
+type AssetRegistry struct {
+	client        *Client
+	cacheFilename string
+
+	mu      sync.RWMutex
+	updated time.Time
+	assets  map[string]AssetInfo
+	pairs   map[string]PairInfo
+
+	// Lookups by any name.
+	assetsByName map[string]AssetInfo
+	pairsByName  map[string]PairInfo
+}
+
+// NewAssetRegistry creates an empty registry. If cacheFilename is not empty
+// the registry is saved there when refreshed and can be loaded from it.
+func NewAssetRegistry(client *Client, cacheFilename string) *AssetRegistry {
+	r := &AssetRegistry{
+		client:        client,
+		cacheFilename: cacheFilename,
 	}
+	r.set(time.Time{}, map[string]AssetInfo{}, map[string]PairInfo{})
+	return r
+}
 
-	return pair
+// DefaultAssetCacheFilename returns the path of the cache file in the user
+// cache directory.
+func DefaultAssetCacheFilename() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cryptotrader", "kraken-assets.json"), nil
 }
 
-func NormalizeAssetName(name string) string {
-	for _, sub := range assetSubsTable {
-		name = strings.Replace(name, sub[0], sub[1], -1)
+type assetCache struct {
+	Updated time.Time            `json:"updated"`
+	Assets  map[string]AssetInfo `json:"assets"`
+	Pairs   map[string]PairInfo  `json:"pairs"`
+}
+
+// Load loads the registry from the cache file, refreshing it from the API
+// if there is no cache file or it is older than maxAge. A maxAge of 0
+// never expires the cache.
+func (r *AssetRegistry) Load(maxAge time.Duration) error {
+	if r.cacheFilename != "" {
+		buf, err := ioutil.ReadFile(r.cacheFilename)
+		if err == nil {
+			cache := assetCache{}
+			if err := json.Unmarshal(buf, &cache); err == nil {
+				if maxAge == 0 || time.Since(cache.Updated) < maxAge {
+					r.set(cache.Updated, cache.Assets, cache.Pairs)
+					return nil
+				}
+			}
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return r.Refresh()
+}
+
+// Refresh reloads the registry from the API and saves the cache file.
+func (r *AssetRegistry) Refresh() error {
+	rawAssets, err := r.client.Assets()
+	if err != nil {
+		return err
+	}
+	rawPairs, err := r.client.AssetPairs()
+	if err != nil {
+		return err
+	}
+
+	assets := map[string]AssetInfo{}
+	for name, raw := range rawAssets {
+		symbol := raw.AltName
+		if alias, ok := assetAliases[symbol]; ok {
+			symbol = alias
+		}
+		assets[name] = AssetInfo{
+			Name:            name,
+			AltName:         raw.AltName,
+			Symbol:          symbol,
+			Decimals:        raw.Decimals,
+			DisplayDecimals: raw.DisplayDecimals,
+		}
+	}
+
+	pairs := map[string]PairInfo{}
+	for name, raw := range rawPairs {
+		// Dark pool pairs.
+		if strings.HasSuffix(name, ".d") {
+			continue
+		}
+		pair := PairInfo{
+			Name:         name,
+			AltName:      raw.AltName,
+			WSName:       raw.WSName,
+			BaseAsset:    raw.Base,
+			QuoteAsset:   raw.Quote,
+			Base:         normalizeAssetWith(assets, raw.Base),
+			Quote:        normalizeAssetWith(assets, raw.Quote),
+			PairDecimals: raw.PairDecimals,
+			LotDecimals:  raw.LotDecimals,
+		}
+		pair.OrderMin, _ = strconv.ParseFloat(raw.OrderMin, 64)
+		pairs[name] = pair
+	}
+
+	updated := time.Now()
+	r.set(updated, assets, pairs)
+
+	if r.cacheFilename != "" {
+		return r.save(assetCache{
+			Updated: updated,
+			Assets:  assets,
+			Pairs:   pairs,
+		})
+	}
+	return nil
+}
+
+func (r *AssetRegistry) save(cache assetCache) error {
+	buf, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.cacheFilename), 0755); err != nil {
+		return err
+	}
+	tmp := r.cacheFilename + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.cacheFilename)
+}
+
+func (r *AssetRegistry) set(updated time.Time, assets map[string]AssetInfo, pairs map[string]PairInfo) {
+	assetsByName := map[string]AssetInfo{}
+	for _, asset := range assets {
+		assetsByName[asset.Symbol] = asset
+		assetsByName[asset.AltName] = asset
+	}
+	// Kraken names last so they win over a clashing alternate name.
+	for name, asset := range assets {
+		assetsByName[name] = asset
 	}
 
+	pairsByName := map[string]PairInfo{}
+	for _, pair := range pairs {
+		pairsByName[pair.Symbol()] = pair
+		if pair.WSName != "" {
+			pairsByName[pair.WSName] = pair
+		}
+		pairsByName[pair.AltName] = pair
+	}
+	for name, pair := range pairs {
+		pairsByName[name] = pair
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = updated
+	r.assets = assets
+	r.pairs = pairs
+	r.assetsByName = assetsByName
+	r.pairsByName = pairsByName
+}
+
+// Updated returns when the registry was last refreshed from the API.
+func (r *AssetRegistry) Updated() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.updated
+}
+
+// Asset looks up an asset by its Kraken name, alternate name or normalized
+// name.
+func (r *AssetRegistry) Asset(name string) (AssetInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	asset, ok := r.assetsByName[name]
+	return asset, ok
+}
+
+// Pair looks up a pair by its Kraken name, alternate name, websocket name
+// or normalized name.
+func (r *AssetRegistry) Pair(name string) (PairInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pair, ok := r.pairsByName[name]
+	return pair, ok
+}
+
+// Pairs returns all the pairs sorted by normalized name.
+func (r *AssetRegistry) Pairs() []PairInfo {
+	r.mu.RLock()
+	pairs := []PairInfo{}
+	for _, pair := range r.pairs {
+		pairs = append(pairs, pair)
+	}
+	r.mu.RUnlock()
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].Symbol() < pairs[j].Symbol()
+	})
+	return pairs
+}
+
+// NormalizeAsset returns the normalized name of an asset, or a best guess
+// if it isn't in the registry.
+func (r *AssetRegistry) NormalizeAsset(name string) string {
+	if asset, ok := r.Asset(name); ok {
+		return asset.Symbol
+	}
+	return guessAssetName(name)
+}
+
+// NormalizePair returns the normalized name of a pair, or a best guess if
+// it isn't in the registry.
+func (r *AssetRegistry) NormalizePair(name string) string {
+	if pair, ok := r.Pair(name); ok {
+		return pair.Symbol()
+	}
+	return guessPairName(name)
+}
+
+func normalizeAssetWith(assets map[string]AssetInfo, name string) string {
+	if asset, ok := assets[name]; ok {
+		return asset.Symbol
+	}
+	return guessAssetName(name)
+}
+
+// guessAssetName strips the X or Z class prefix of the legacy 4 letter
+// names, ie: "XXBT" and "ZUSD".
+func guessAssetName(name string) string {
+	if len(name) == 4 && (name[0] == 'X' || name[0] == 'Z') {
+		name = name[1:]
+	}
+	if alias, ok := assetAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// Alternate names of common quote assets, used to split pair names that
+// aren't in the registry. Longer names that end with a shorter one come
+// first.
+var quoteAltNames = []string{
+	"USDT", "USDC", "USD", "EUR", "GBP", "CAD", "JPY", "CHF", "AUD",
+	"DAI", "XBT", "ETH",
+}
+
+// guessPairName splits the legacy 8 letter pair names, ie: "XXBTZUSD",
+// websocket names, ie: "XBT/USD", and alternate names ending in a common
+// quote asset, ie: "BCHUSD". Other names are returned as is.
+func guessPairName(name string) string {
+	if parts := strings.Split(name, "/"); len(parts) == 2 {
+		return fmt.Sprintf("%s/%s", guessAssetName(parts[0]),
+			guessAssetName(parts[1]))
+	}
+	if len(name) == 8 && (name[0] == 'X' || name[0] == 'Z') &&
+		(name[4] == 'X' || name[4] == 'Z') {
+		return fmt.Sprintf("%s/%s", guessAssetName(name[:4]),
+			guessAssetName(name[4:]))
+	}
+	for _, quote := range quoteAltNames {
+		if len(name) > len(quote)+1 && strings.HasSuffix(name, quote) &&
+			!strings.Contains(name, ".") {
+			return fmt.Sprintf("%s/%s",
+				guessAssetName(name[:len(name)-len(quote)]),
+				guessAssetName(quote))
+		}
+	}
 	return name
-}
\ No newline at end of file
+}
+
+var defaultRegistry struct {
+	sync.RWMutex
+	registry *AssetRegistry
+}
+
+// SetDefaultAssetRegistry sets the registry used by GetNormalizePairName and
+// NormalizeAssetName. Without one names are guessed from the legacy Kraken
+// naming scheme.
+func SetDefaultAssetRegistry(registry *AssetRegistry) {
+	defaultRegistry.Lock()
+	defer defaultRegistry.Unlock()
+	defaultRegistry.registry = registry
+}
+
+func getDefaultAssetRegistry() *AssetRegistry {
+	defaultRegistry.RLock()
+	defer defaultRegistry.RUnlock()
+	return defaultRegistry.registry
+}
+
+func GetNormalizePairName(pair string) string {
+	if registry := getDefaultAssetRegistry(); registry != nil {
+		return registry.NormalizePair(pair)
+	}
+	return guessPairName(pair)
+}
+
+func NormalizeAssetName(name string) string {
+	if registry := getDefaultAssetRegistry(); registry != nil {
+		return registry.NormalizeAsset(name)
+	}
+	return guessAssetName(name)
+}
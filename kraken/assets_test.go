@@ -0,0 +1,98 @@
+package kraken
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAssetRegistry(t *testing.T) {
+	requests := 0
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/0/public/Assets":
+			w.Write([]byte(`{"error":[],"result":{
+				"XXBT":{"aclass":"currency","altname":"XBT","decimals":10,"display_decimals":5},
+				"ZEUR":{"aclass":"currency","altname":"EUR","decimals":4,"display_decimals":2},
+				"DOT":{"aclass":"currency","altname":"DOT","decimals":10,"display_decimals":8}}}`))
+		case "/0/public/AssetPairs":
+			w.Write([]byte(`{"error":[],"result":{
+				"XXBTZEUR":{"altname":"XBTEUR","wsname":"XBT/EUR","base":"XXBT","quote":"ZEUR","pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001"},
+				"XXBTZEUR.d":{"altname":"XBTEUR.d","base":"XXBT","quote":"ZEUR","pair_decimals":1,"lot_decimals":8},
+				"DOTEUR":{"altname":"DOTEUR","wsname":"DOT/EUR","base":"DOT","quote":"ZEUR","pair_decimals":4,"lot_decimals":8,"ordermin":"1"}}}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "cache", "assets.json")
+
+	registry := NewAssetRegistry(client, filename)
+	if err := registry.Load(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+
+	for _, name := range []string{"XXBTZEUR", "XBTEUR", "XBT/EUR", "BTC/EUR"} {
+		pair, ok := registry.Pair(name)
+		if !ok || pair.Name != "XXBTZEUR" {
+			t.Fatalf("%s: unexpected pair: %+v", name, pair)
+		}
+	}
+	pair, _ := registry.Pair("DOTEUR")
+	if pair.Symbol() != "DOT/EUR" || pair.PairDecimals != 4 || pair.OrderMin != 1 {
+		t.Fatalf("unexpected pair: %+v", pair)
+	}
+	if _, ok := registry.Pair("XBTEUR.d"); ok {
+		t.Fatal("dark pool pair should be skipped")
+	}
+	if registry.NormalizeAsset("XXBT") != "BTC" || registry.NormalizeAsset("ZEUR") != "EUR" {
+		t.Fatal("unexpected normalized assets")
+	}
+
+	// A second registry loads from the cache.
+	cached := NewAssetRegistry(client, filename)
+	if err := cached.Load(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Fatalf("expected the cache to be used, got %d requests", requests)
+	}
+	if cached.NormalizePair("XBT/EUR") != "BTC/EUR" || len(cached.Pairs()) != 2 {
+		t.Fatalf("unexpected cached pairs: %+v", cached.Pairs())
+	}
+}
+
+func TestGuessNames(t *testing.T) {
+	tests := map[string]string{
+		"XXBTZUSD":  "BTC/USD",
+		"XETHZEUR":  "ETH/EUR",
+		"XXDGXXBT":  "DOGE/BTC",
+		"XBT/USD":   "BTC/USD",
+		"BCHUSD":    "BCH/USD",
+		"DOTUSD":    "DOT/USD",
+		"XDGXBT":    "DOGE/BTC",
+		"USDTUSD":   "USDT/USD",
+		"ETH2.SETH": "ETH2.SETH",
+	}
+	for name, expected := range tests {
+		if got := guessPairName(name); got != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, got)
+		}
+	}
+	if got := guessAssetName("ZUSD"); got != "USD" {
+		t.Errorf("expected USD, got %s", got)
+	}
+}
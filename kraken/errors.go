@@ -0,0 +1,96 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errors that an ApiError can be matched against with errors.Is.
+var (
+	ErrRateLimited        = errors.New("rate limit exceeded")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrUnknownOrder       = errors.New("unknown order")
+	ErrInvalidNonce       = errors.New("invalid nonce")
+	ErrInvalidKey         = errors.New("invalid key")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrInvalidArguments   = errors.New("invalid arguments")
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// ApiError is the error array of a Kraken response, ie:
+// ["EOrder:Insufficient funds"].
+type ApiError struct {
+	Errors []string
+}
+
+// NewApiError returns an ApiError for a non-empty error array, or nil.
+func NewApiError(errors []string) error {
+	if len(errors) == 0 {
+		return nil
+	}
+	return &ApiError{Errors: errors}
+}
+
+func (e *ApiError) Error() string {
+	return strings.Join(e.Errors, "; ")
+}
+
+func (e *ApiError) has(prefixes ...string) bool {
+	for _, err := range e.Errors {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(err, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Is allows an ApiError to be matched against the Err* values of this
+// package with errors.Is.
+func (e *ApiError) Is(target error) bool {
+	switch target {
+	case ErrRateLimited:
+		return e.has(ErrRateLimitExceeded, "EOrder:Rate limit exceeded",
+			"EGeneral:Too many requests")
+	case ErrInsufficientFunds:
+		return e.has("EOrder:Insufficient funds")
+	case ErrUnknownOrder:
+		return e.has("EOrder:Unknown order")
+	case ErrInvalidNonce:
+		return e.has("EAPI:Invalid nonce")
+	case ErrInvalidKey:
+		return e.has("EAPI:Invalid key", "EAPI:Invalid signature")
+	case ErrPermissionDenied:
+		return e.has("EGeneral:Permission denied")
+	case ErrInvalidArguments:
+		return e.has("EGeneral:Invalid arguments")
+	case ErrServiceUnavailable:
+		return e.has("EService:Unavailable", "EService:Busy")
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"fmt"
+	"github.com/khayrullo/cryptotrader/core"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ExchangeName = "Kraken"
+
+var _ core.Exchange = (*Exchange)(nil)
+
+// Exchange adapts a Client to the core.Exchange interface.
+type Exchange struct {
+	client *Client
+}
+
+func NewExchange(client *Client) *Exchange {
+	return &Exchange{
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+// Tickers returns the tickers of the requested pairs, in the order
+// requested. Kraken may respond with a different name for a pair than the
+// one requested, so tickers are matched to the requests by normalized name
+// and returned with the requested name.
+func (e *Exchange) Tickers(pairs ...string) ([]core.Ticker, error) {
+	response, err := e.client.Ticker(pairs...)
+	if err != nil {
+		return nil, err
+	}
+	tickers := []core.Ticker{}
+	for _, pair := range pairs {
+		ticker, ok := response[GetNormalizePairName(pair)]
+		if !ok {
+			continue
+		}
+		tickers = append(tickers, core.Ticker{
+			Exchange:  ExchangeName,
+			Symbol:    pair,
+			Timestamp: ticker.Timestamp,
+			Bid:       ticker.Bid,
+			Ask:       ticker.Ask,
+			Last:      ticker.Last,
+		})
+	}
+	return tickers, nil
+}
+
+func (e *Exchange) OrderBook(pair string, depth int) (*core.OrderBook, error) {
+	response, err := e.client.Depth(pair, depth)
+	if err != nil {
+		return nil, err
+	}
+	book := &core.OrderBook{
+		Exchange:  ExchangeName,
+		Symbol:    pair,
+		Timestamp: time.Now(),
+	}
+	for _, bid := range response.Bids {
+		book.Bids = append(book.Bids, core.OrderBookEntry{
+			Price:    bid.Price,
+			Quantity: bid.Volume,
+		})
+	}
+	for _, ask := range response.Asks {
+		book.Asks = append(book.Asks, core.OrderBookEntry{
+			Price:    ask.Price,
+			Quantity: ask.Volume,
+		})
+	}
+	return book, nil
+}
+
+// Balances returns the non-zero balances. Kraken only reports a total
+// balance per asset so everything is reported as free.
+func (e *Exchange) Balances() ([]core.Balance, error) {
+	response, err := e.client.Balance()
+	if err != nil {
+		return nil, err
+	}
+	balances := []core.Balance{}
+	for asset, amount := range response {
+		if amount == 0 {
+			continue
+		}
+		balances = append(balances, core.Balance{
+			Asset: asset,
+			Free:  amount,
+		})
+	}
+	return balances, nil
+}
+
+// PlaceOrder places a market or limit order. A ClientOrderID, if set, must
+// be a 32 bit integer as it is sent as the Kraken user reference.
+func (e *Exchange) PlaceOrder(order core.OrderRequest) (*core.Order, error) {
+	request := AddOrderRequest{
+		Pair:   order.Symbol,
+		Side:   OrderSide(strings.ToLower(string(order.Side))),
+		Volume: order.Quantity,
+	}
+	switch order.Type {
+	case core.OrderTypeMarket:
+		request.OrderType = OrderTypeMarket
+	case core.OrderTypeLimit:
+		request.OrderType = OrderTypeLimit
+		request.Price = strconv.FormatFloat(order.Price, 'f', -1, 64)
+	default:
+		return nil, fmt.Errorf("unsupported order type: %s", order.Type)
+	}
+	if order.ClientOrderID != "" {
+		userRef, err := strconv.ParseInt(order.ClientOrderID, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("client order ID must be a 32 bit integer: %s",
+				order.ClientOrderID)
+		}
+		request.UserRef = int32(userRef)
+	}
+
+	response, err := NewOrderService(e.client).AddOrder(request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.TxIDs) == 0 {
+		return nil, fmt.Errorf("no transaction ID in response")
+	}
+
+	return &core.Order{
+		Exchange:      ExchangeName,
+		Symbol:        order.Symbol,
+		OrderID:       response.TxIDs[0],
+		ClientOrderID: order.ClientOrderID,
+		Side:          order.Side,
+		Type:          order.Type,
+		Status:        core.OrderStatusNew,
+		Price:         order.Price,
+		Quantity:      order.Quantity,
+		Timestamp:     time.Now(),
+	}, nil
+}
+
+func (e *Exchange) CancelOrder(pair string, orderID string) error {
+	_, err := NewOrderService(e.client).CancelOrder(orderID)
+	return err
+}
+
+func (e *Exchange) QueryOrder(pair string, orderID string) (*core.Order, error) {
+	orders, err := NewOrderService(e.client).QueryOrders(orderID)
+	if err != nil {
+		return nil, err
+	}
+	order := orders[0]
+
+	result := &core.Order{
+		Exchange:    ExchangeName,
+		Symbol:      pair,
+		OrderID:     order.ID,
+		Side:        core.OrderSide(strings.ToUpper(string(order.Side))),
+		Type:        core.OrderType(strings.ToUpper(string(order.OrderType))),
+		Status:      coreOrderStatus(order),
+		Price:       order.Price,
+		Quantity:    order.Volume,
+		ExecutedQty: order.VolumeExecuted,
+		Timestamp:   order.OpenTime,
+	}
+	if order.UserRef != 0 {
+		result.ClientOrderID = strconv.FormatInt(order.UserRef, 10)
+	}
+	return result, nil
+}
+
+func coreOrderStatus(order Order) core.OrderStatus {
+	switch order.Status {
+	case OrderStatusPending, OrderStatusOpen:
+		if order.VolumeExecuted > 0 {
+			return core.OrderStatusPartiallyFilled
+		}
+		return core.OrderStatusNew
+	case OrderStatusClosed:
+		return core.OrderStatusFilled
+	case OrderStatusCanceled:
+		return core.OrderStatusCanceled
+	case OrderStatusExpired:
+		return core.OrderStatusExpired
+	}
+	return core.OrderStatusUnknown
+}
+
+// Trades returns the account trades on a pair, oldest first. Kraken can't
+// filter the trade history by pair, so the whole history is fetched.
+func (e *Exchange) Trades(pair string) ([]core.Trade, error) {
+	response, err := NewTradesService(e.client).Trades(GetTradesOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// The history uses the native pair name, which may not be the name
+	// passed in, such as XXBTZUSD for XBTUSD.
+	normalized := GetNormalizePairName(pair)
+	trades := []core.Trade{}
+	for _, trade := range response {
+		if GetNormalizePairName(trade.Pair) != normalized {
+			continue
+		}
+		trades = append(trades, core.Trade{
+			Exchange:  ExchangeName,
+			Symbol:    pair,
+			TradeID:   trade.TradeID,
+			OrderID:   trade.OrderID,
+			Side:      core.OrderSide(strings.ToUpper(string(trade.Side))),
+			Price:     trade.Price,
+			Quantity:  trade.Volume,
+			Fee:       trade.Fee,
+			FeeAsset:  quoteAsset(pair),
+			Timestamp: trade.Timestamp,
+		})
+	}
+	return trades, nil
+}
+
+// quoteAsset returns the normalized quote asset of a pair, the asset fees
+// are charged in by default, or an empty string if it isn't known.
+func quoteAsset(pair string) string {
+	normalized := GetNormalizePairName(pair)
+	if i := strings.Index(normalized, "/"); i > -1 {
+		return NormalizeAssetName(normalized[i+1:])
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package kraken
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestExchangeTradesMatchesPairNames(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"count":2,"trades":{` +
+			`"T1":{"ordertxid":"O1","pair":"XXBTZUSD","time":1500000001,"type":"buy","ordertype":"limit","price":"100.0","cost":"200.0","fee":"0.32","vol":"2.0","margin":"0.0","misc":""},` +
+			`"T2":{"ordertxid":"O2","pair":"XETHXXBT","time":1500000002,"type":"sell","ordertype":"limit","price":"0.1","cost":"0.1","fee":"0.0001","vol":"1.0","margin":"0.0","misc":""}}}}`))
+	})
+	defer server.Close()
+
+	// The history uses XXBTZUSD, so the altname must still match.
+	trades, err := NewExchange(client).Trades("XBTUSD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 1 || trades[0].TradeID != "T1" || trades[0].Symbol != "XBTUSD" ||
+		trades[0].FeeAsset != "USD" {
+		t.Fatalf("unexpected trades: %+v", trades)
+	}
+}
+
+func TestExchangeTickersUseRequestedNames(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{` +
+			`"XETHXXBT":{"a":["0.03","1","1.0"],"b":["0.02","1","1.0"],"c":["0.025","0.5"]},` +
+			`"XXBTZUSD":{"a":["101.0","1","1.0"],"b":["100.0","1","1.0"],"c":["100.5","0.5"]}}}`))
+	})
+	defer server.Close()
+
+	tickers, err := NewExchange(client).Tickers("XBTUSD", "XETHXXBT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickers) != 2 || tickers[0].Symbol != "XBTUSD" || tickers[0].Bid != 100 ||
+		tickers[1].Symbol != "XETHXXBT" || tickers[1].Ask != 0.03 {
+		t.Fatalf("unexpected tickers: %+v", tickers)
+	}
+}
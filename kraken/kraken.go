@@ -26,6 +26,7 @@ package kraken
 
 import (
 	"net/http"
+	"net/url"
 	"fmt"
 	"sort"
 	"time"
@@ -39,16 +40,63 @@ import (
 	"strconv"
 	"io/ioutil"
 	"bytes"
+	"github.com/khayrullo/cryptotrader/core"
 )
 
 const API_ROOT = "https://api.kraken.com"
 
+// The error returned when the API call counter has been exceeded.
+const ErrRateLimitExceeded = "EAPI:Rate limit exceeded"
+
 type Client struct {
 	apiKey    string
 	apiSecret []byte
+
+	core.HTTPClient
+}
+
+// ClientOption configures optional settings of a Client.
+type ClientOption func(*Client)
+
+// WithBaseURL is core.WithBaseURL. The default is API_ROOT.
+func WithBaseURL(baseURL string) ClientOption {
+	return withHTTPOption(core.WithBaseURL(baseURL))
+}
+
+// WithHTTPClient is core.WithHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return withHTTPOption(core.WithHTTPClient(httpClient))
+}
+
+// WithUserAgent is core.WithUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return withHTTPOption(core.WithUserAgent(userAgent))
 }
 
-func NewClient(apiKey string, apiSecret string) *Client {
+// WithTimeout is core.WithTimeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return withHTTPOption(core.WithTimeout(timeout))
+}
+
+// WithRateLimiter is core.WithRateLimiter.
+func WithRateLimiter(rateLimiter core.RateLimiter) ClientOption {
+	return withHTTPOption(core.WithRateLimiter(rateLimiter))
+}
+
+func withHTTPOption(opt core.HTTPOption) ClientOption {
+	return func(c *Client) {
+		opt(&c.HTTPClient)
+	}
+}
+
+func (c *Client) applyOptions(opts []ClientOption) {
+	c.HTTPClient = core.NewHTTPClient(API_ROOT)
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+func NewClient(apiKey string, apiSecret string, opts ...ClientOption) *Client {
 	var decodedApiSecret []byte
 	var err error
 	if apiSecret != "" {
@@ -60,10 +108,12 @@ func NewClient(apiKey string, apiSecret string) *Client {
 		decodedApiSecret = nil
 	}
 
-	return &Client{
+	client := &Client{
 		apiKey:    apiKey,
 		apiSecret: decodedApiSecret,
 	}
+	client.applyOptions(opts)
+	return client
 }
 
 // HasAuth returns true if client has authentication information.
@@ -76,7 +126,7 @@ func (c *Client) HasAuth() bool {
 
 func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Response, error) {
 
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
 	queryString := ""
 
 	if params != nil {
@@ -90,12 +140,12 @@ func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Resp
 	if err != nil {
 		return nil, err
 	}
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *Client) Post(endpoint string, params map[string]interface{}) (*http.Response, error) {
 
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
 	queryString := ""
 
 	nonce := c.getNonce()
@@ -116,11 +166,13 @@ func (c *Client) Post(endpoint string, params map[string]interface{}) (*http.Res
 		return nil, err
 	}
 
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
 	if c.apiKey != "" && c.apiSecret != nil {
 		c.authenticateRequest(request, endpoint, nonce, queryString)
 	}
 
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *Client) authenticateRequest(request *http.Request, endpoint string, nonce int64, postData string) {
@@ -153,12 +205,30 @@ func (c *Client) BuildQueryString(params map[string]interface{}) string {
 		if queryString != "" {
 			queryString = fmt.Sprintf("%s&", queryString)
 		}
-		queryString = fmt.Sprintf("%s%s=%v", queryString, key, params[key])
+		queryString = fmt.Sprintf("%s%s=%s", queryString, key,
+			url.QueryEscape(fmt.Sprintf("%v", params[key])))
 	}
 
 	return queryString
 }
 
+// The number of times in a row a rate limited call is retried before the
+// error is returned.
+const maxRateLimitRetries = 5
+
+// rateLimitExceeded is called after a call fails with ErrRateLimitExceeded.
+// With a RateLimiter the next call will wait for the counter to decay,
+// otherwise just sleep for a bit.
+func (c *Client) rateLimitExceeded() {
+	if limiter, ok := c.RateLimiter().(*RateLimiter); ok {
+		log.Println("warning: rate limit exceeded, waiting for counter to decay")
+		limiter.Exceeded()
+		return
+	}
+	log.Println("warning: rate limit exceeded, sleeping for 5s")
+	time.Sleep(5 * time.Second)
+}
+
 func (c *Client) getNonce() int64 {
 	return time.Now().UnixNano() / int64(time.Millisecond)
 }
@@ -167,19 +237,81 @@ type Ticker struct {
 	Pair      string
 	Timestamp time.Time
 	Ask       float64
+	AskVolume float64
 	Bid       float64
+	BidVolume float64
 	Last      float64
+
+	// The volume of the last trade.
+	LastVolume float64
+
+	// The stats since 00:00 UTC and over the last 24 hours.
+	VolumeToday float64
+	Volume24h   float64
+	VWAPToday   float64
+	VWAP24h     float64
+	TradesToday int64
+	Trades24h   int64
+	LowToday    float64
+	Low24h      float64
+	HighToday   float64
+	High24h     float64
+
+	// The opening price at 00:00 UTC.
+	Open float64
 }
 
 type RawTickerPair struct {
 	A []string `json:"a"` // Ask array.
 	B []string `json:"b"` // Bid array.
 	C []string `json:"c"` // Last array.
-	V []string `json:"v"`
-	P []string `json:"p"`
-	T []int64  `json:"t"`
-	H []string `json:"h"`
-	O string   `json:"o"`
+	V []string `json:"v"` // Volume array.
+	P []string `json:"p"` // VWAP array.
+	T []int64  `json:"t"` // Trade count array.
+	L []string `json:"l"` // Low array.
+	H []string `json:"h"` // High array.
+	O string   `json:"o"` // Opening price.
+}
+
+// parseFloatAt parses the float at index i of a Kraken ticker array,
+// returning 0 if it is missing.
+func parseFloatAt(values []string, i int) float64 {
+	if i >= len(values) {
+		return 0
+	}
+	value, _ := strconv.ParseFloat(values[i], 64)
+	return value
+}
+
+func int64At(values []int64, i int) int64 {
+	if i >= len(values) {
+		return 0
+	}
+	return values[i]
+}
+
+func NewTickerFromRaw(pair string, timestamp time.Time, raw RawTickerPair) Ticker {
+	ticker := Ticker{}
+	ticker.Pair = pair
+	ticker.Timestamp = timestamp
+	ticker.Ask = parseFloatAt(raw.A, 0)
+	ticker.AskVolume = parseFloatAt(raw.A, 2)
+	ticker.Bid = parseFloatAt(raw.B, 0)
+	ticker.BidVolume = parseFloatAt(raw.B, 2)
+	ticker.Last = parseFloatAt(raw.C, 0)
+	ticker.LastVolume = parseFloatAt(raw.C, 1)
+	ticker.VolumeToday = parseFloatAt(raw.V, 0)
+	ticker.Volume24h = parseFloatAt(raw.V, 1)
+	ticker.VWAPToday = parseFloatAt(raw.P, 0)
+	ticker.VWAP24h = parseFloatAt(raw.P, 1)
+	ticker.TradesToday = int64At(raw.T, 0)
+	ticker.Trades24h = int64At(raw.T, 1)
+	ticker.LowToday = parseFloatAt(raw.L, 0)
+	ticker.Low24h = parseFloatAt(raw.L, 1)
+	ticker.HighToday = parseFloatAt(raw.H, 0)
+	ticker.High24h = parseFloatAt(raw.H, 1)
+	ticker.Open, _ = strconv.ParseFloat(raw.O, 64)
+	return ticker
 }
 
 type RawTickerResponse struct {
@@ -205,6 +337,10 @@ func (c *Client) Ticker(pairs ... string) (tickers map[string]Ticker, err error)
 	} else {
 		r, err = c.Get(endpoint, params)
 	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
 
 	now := time.Now()
 
@@ -216,20 +352,14 @@ func (c *Client) Ticker(pairs ... string) (tickers map[string]Ticker, err error)
 	}
 
 	if len(rawResponse.Error) > 0 {
-		return nil, fmt.Errorf("%s", rawResponse.Error[0])
+		return nil, NewApiError(rawResponse.Error)
 	}
 
 	tickers = map[string]Ticker{}
 
 	for pair, val := range rawResponse.Result {
 		normalizedPair := GetNormalizePairName(pair)
-		ticker := Ticker{}
-		ticker.Timestamp = now
-		ticker.Pair = normalizedPair
-		ticker.Ask, _ = strconv.ParseFloat(val.A[0], 64)
-		ticker.Bid, _ = strconv.ParseFloat(val.B[0], 64)
-		ticker.Last, _ = strconv.ParseFloat(val.C[0], 64)
-		tickers[normalizedPair] = ticker
+		tickers[normalizedPair] = NewTickerFromRaw(normalizedPair, now, val)
 	}
 
 	return tickers, nil
@@ -252,3 +382,77 @@ func decodeBody(r *http.Response, v RawSetter) error {
 	v.SetRaw(string(raw))
 	return nil
 }
+
+// postAndDecode posts to a private endpoint and decodes the result into
+// result. A non-empty error array is returned as an ApiError.
+func (c *Client) postAndDecode(endpoint string, params map[string]interface{}, result interface{}) error {
+	httpResponse, err := c.Post(endpoint, params)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	return decodeResult(httpResponse, result)
+}
+
+// getAndDecode is postAndDecode for public endpoints.
+func (c *Client) getAndDecode(endpoint string, params map[string]interface{}, result interface{}) error {
+	httpResponse, err := c.Get(endpoint, params)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+	return decodeResult(httpResponse, result)
+}
+
+func decodeResult(r *http.Response, result interface{}) error {
+	var response struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&response); err != nil {
+		return err
+	}
+	if err := NewApiError(response.Error); err != nil {
+		return err
+	}
+	if result == nil || len(response.Result) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(response.Result))
+	decoder.UseNumber()
+	return decoder.Decode(result)
+}
+
+type RawBalanceResponse struct {
+	Error  []string          `json:"error"`
+	Result map[string]string `json:"result"`
+	Raw    string            `json:"-"`
+}
+
+func (r *RawBalanceResponse) SetRaw(raw string) {
+	r.Raw = raw
+}
+
+// Balance returns the account balance of each asset keyed by the normalized
+// asset name.
+func (c *Client) Balance() (map[string]float64, error) {
+	httpResponse, err := c.Post("/0/private/Balance", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	response := RawBalanceResponse{}
+	if err := decodeBody(httpResponse, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Error) > 0 {
+		return nil, fmt.Errorf("%s", response.Error[0])
+	}
+
+	balances := map[string]float64{}
+	for asset, amount := range response.Result {
+		balances[NormalizeAssetName(asset)], _ = strconv.ParseFloat(amount, 64)
+	}
+	return balances, nil
+}
@@ -27,7 +27,6 @@ package kraken
 import (
 	"fmt"
 	"github.com/khayrullo/cryptotrader/util"
-	"log"
 	"sort"
 	"strconv"
 	"time"
@@ -74,7 +73,7 @@ type RawLedgerResponse struct {
 	Error  []string `json:"error"`
 	Result struct {
 		Count  int64                     `json:"count"`
-		Ledger map[string]RawLedgerEntry `json:"ledger""`
+		Ledger map[string]RawLedgerEntry `json:"ledger"`
 	} `json:"result"`
 	Raw string `json:"-"`
 }
@@ -99,7 +98,7 @@ func (s *LedgerService) RawLedger(params map[string]interface{}) (*RawLedgerResp
 	}
 	response := RawLedgerResponse{}
 	if err := decodeBody(httpResponse, &response); err != nil {
-		return nil, nil
+		return nil, err
 	}
 	return &response, nil
 }
@@ -132,9 +131,8 @@ func (s *LedgerService) Ledger(options GetLedgerOptions) ([]LedgerEntry, error)
 		}
 
 		if len(response.Error) > 0 {
-			if response.Error[0] == "EAPI:Rate limit exceeded" {
-				log.Println("warning: rate limit exceeded, sleeping for 5s")
-				time.Sleep(5 * time.Second)
+			if response.Error[0] == ErrRateLimitExceeded {
+				s.client.rateLimitExceeded()
 				continue
 			}
 			return entries, fmt.Errorf("%s", response.Error[0])
@@ -0,0 +1,569 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// Kraken sends a heartbeat every second without other traffic while
+	// subscribed, and the managed websocket pings in between, so a
+	// connection quiet for this long is stale.
+	DefaultWebSocketReadTimeout = 10 * time.Second
+
+	DefaultWebSocketPingInterval = 5 * time.Second
+	DefaultMinReconnectDelay     = time.Second
+	DefaultMaxReconnectDelay     = time.Minute
+
+	// The book depth used when a book subscription doesn't set one.
+	DefaultBookDepth = 10
+)
+
+type WebSocketEventType int
+
+const (
+	// A message was received.
+	WebSocketEventMessage WebSocketEventType = iota
+
+	// The connection is made and the subscriptions are being restored.
+	WebSocketEventConnected
+
+	// The connection was lost or could not be made; Err holds the cause.
+	// Messages may have been missed until the next WebSocketEventConnected.
+	WebSocketEventDisconnected
+
+	// A message could not be decoded, or a book failed its checksum and is
+	// being resubscribed to. The connection is still up.
+	WebSocketEventError
+
+	// The websocket was closed with Close. No more events will be sent.
+	WebSocketEventClosed
+)
+
+func (t WebSocketEventType) String() string {
+	switch t {
+	case WebSocketEventMessage:
+		return "message"
+	case WebSocketEventConnected:
+		return "connected"
+	case WebSocketEventDisconnected:
+		return "disconnected"
+	case WebSocketEventError:
+		return "error"
+	case WebSocketEventClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("WebSocketEventType(%d)", int(t))
+}
+
+type WebSocketEvent struct {
+	Type    WebSocketEventType
+	Message *WebSocketMessage
+
+	// For book messages, a copy of the book of the pair with the message
+	// applied.
+	Book *Book
+
+	Err error
+}
+
+// ManagedWebSocketOption configures optional settings of a
+// ManagedWebSocket.
+type ManagedWebSocketOption func(*ManagedWebSocket)
+
+// WithWebSocketClientOptions sets the options of the WebSocketClient
+// created for each connection.
+func WithWebSocketClientOptions(opts ...WebSocketClientOption) ManagedWebSocketOption {
+	return func(s *ManagedWebSocket) {
+		s.clientOpts = append(s.clientOpts, opts...)
+	}
+}
+
+// WithWebSocketReadTimeout sets how long the connection may go without
+// receiving anything before it is considered stale and replaced.
+func WithWebSocketReadTimeout(timeout time.Duration) ManagedWebSocketOption {
+	return func(s *ManagedWebSocket) {
+		s.readTimeout = timeout
+	}
+}
+
+// WithWebSocketPingInterval sets how often a ping event is sent. It should
+// be less than the read timeout.
+func WithWebSocketPingInterval(interval time.Duration) ManagedWebSocketOption {
+	return func(s *ManagedWebSocket) {
+		s.pingInterval = interval
+	}
+}
+
+// WithWebSocketReconnectDelay sets the delay before the first reconnect
+// attempt, and the maximum delay it is doubled up to on consecutive
+// failures.
+func WithWebSocketReconnectDelay(min time.Duration, max time.Duration) ManagedWebSocketOption {
+	return func(s *ManagedWebSocket) {
+		s.minReconnectDelay = min
+		s.maxReconnectDelay = max
+	}
+}
+
+// WithWebSocketToken sets the function called for a token when connecting
+// with private subscriptions.
+func WithWebSocketToken(token func() (string, error)) ManagedWebSocketOption {
+	return func(s *ManagedWebSocket) {
+		s.tokenSource = token
+	}
+}
+
+type subscriptionEntry struct {
+	subscription Subscription
+	pairs        []string
+}
+
+// ManagedWebSocket is a websocket connection that reconnects when the
+// connection is lost or goes stale, restoring its subscriptions. Books
+// subscribed to are maintained and verified against the Kraken checksum.
+type ManagedWebSocket struct {
+	clientOpts        []WebSocketClientOption
+	readTimeout       time.Duration
+	pingInterval      time.Duration
+	minReconnectDelay time.Duration
+	maxReconnectDelay time.Duration
+	tokenSource       func() (string, error)
+
+	lock          sync.Mutex
+	subscriptions []subscriptionEntry
+	books         map[string]*Book
+	client        *WebSocketClient
+	token         string
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewManagedWebSocket creates a managed websocket for the public channels.
+// It does not connect until Run is called.
+func NewManagedWebSocket(opts ...ManagedWebSocketOption) *ManagedWebSocket {
+	s := &ManagedWebSocket{
+		readTimeout:       DefaultWebSocketReadTimeout,
+		pingInterval:      DefaultWebSocketPingInterval,
+		minReconnectDelay: DefaultMinReconnectDelay,
+		maxReconnectDelay: DefaultMaxReconnectDelay,
+		books:             map[string]*Book{},
+		closed:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewManagedPrivateWebSocket creates a managed websocket for the private
+// channels, getting a token from the client on each connect.
+func NewManagedPrivateWebSocket(client *Client, opts ...ManagedWebSocketOption) *ManagedWebSocket {
+	defaults := []ManagedWebSocketOption{
+		WithWebSocketClientOptions(WithWebSocketURL(WS_AUTH_URL)),
+		WithWebSocketToken(func() (string, error) {
+			response, err := client.GetWebSocketsToken()
+			if err != nil {
+				return "", err
+			}
+			return response.Token, nil
+		}),
+	}
+	return NewManagedWebSocket(append(defaults, opts...)...)
+}
+
+// subscriptionKey identifies a subscription regardless of its token.
+func subscriptionKey(subscription Subscription) Subscription {
+	subscription.Token = ""
+	if subscription.Name == ChannelBook && subscription.Depth == 0 {
+		subscription.Depth = DefaultBookDepth
+	}
+	return subscription
+}
+
+// Subscribe adds a subscription to the live connection, if any, and to the
+// set restored on reconnect.
+func (s *ManagedWebSocket) Subscribe(subscription Subscription, pairs ...string) error {
+	subscription = subscriptionKey(subscription)
+	if subscription.IsPrivate() && s.tokenSource == nil {
+		return fmt.Errorf("%s requires a token", subscription.Name)
+	}
+
+	s.lock.Lock()
+	s.addSubscription(subscription, pairs)
+	if subscription.Name == ChannelBook {
+		for _, pair := range pairs {
+			if _, ok := s.books[pair]; !ok {
+				s.books[pair] = NewBook(subscription.Depth)
+			}
+		}
+	}
+	client := s.client
+	s.lock.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	return s.send(client, subscription, pairs)
+}
+
+// Unsubscribe removes a subscription from the live connection, if any, and
+// from the set restored on reconnect.
+func (s *ManagedWebSocket) Unsubscribe(subscription Subscription, pairs ...string) error {
+	subscription = subscriptionKey(subscription)
+
+	s.lock.Lock()
+	s.removeSubscription(subscription, pairs)
+	if subscription.Name == ChannelBook {
+		for _, pair := range pairs {
+			delete(s.books, pair)
+		}
+	}
+	client := s.client
+	token := s.token
+	s.lock.Unlock()
+
+	if client == nil {
+		return nil
+	}
+	subscription.Token = token
+	_, err := client.Unsubscribe(subscription, pairs...)
+	return err
+}
+
+// Book returns the book maintained for a pair, or nil if the book channel
+// of the pair isn't subscribed to.
+func (s *ManagedWebSocket) Book(pair string) *Book {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.books[pair]
+}
+
+func (s *ManagedWebSocket) addSubscription(subscription Subscription, pairs []string) {
+	for i, entry := range s.subscriptions {
+		if entry.subscription == subscription {
+			for _, pair := range pairs {
+				if !containsString(entry.pairs, pair) {
+					s.subscriptions[i].pairs = append(s.subscriptions[i].pairs, pair)
+				}
+			}
+			return
+		}
+	}
+	s.subscriptions = append(s.subscriptions, subscriptionEntry{
+		subscription: subscription,
+		pairs:        append([]string{}, pairs...),
+	})
+}
+
+func (s *ManagedWebSocket) removeSubscription(subscription Subscription, pairs []string) {
+	remaining := []subscriptionEntry{}
+	for _, entry := range s.subscriptions {
+		if entry.subscription == subscription {
+			if len(pairs) == 0 {
+				continue
+			}
+			kept := []string{}
+			for _, pair := range entry.pairs {
+				if !containsString(pairs, pair) {
+					kept = append(kept, pair)
+				}
+			}
+			if len(kept) == 0 {
+				continue
+			}
+			entry.pairs = kept
+		}
+		remaining = append(remaining, entry)
+	}
+	s.subscriptions = remaining
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// send subscribes on the live connection, getting a token first for the
+// private channels if there isn't one yet.
+func (s *ManagedWebSocket) send(client *WebSocketClient, subscription Subscription, pairs []string) error {
+	if subscription.IsPrivate() {
+		token, err := s.getToken()
+		if err != nil {
+			return err
+		}
+		subscription.Token = token
+	}
+	_, err := client.Subscribe(subscription, pairs...)
+	return err
+}
+
+func (s *ManagedWebSocket) getToken() (string, error) {
+	s.lock.Lock()
+	token := s.token
+	s.lock.Unlock()
+	if token != "" {
+		return token, nil
+	}
+	token, err := s.tokenSource()
+	if err != nil {
+		return "", err
+	}
+	s.lock.Lock()
+	s.token = token
+	s.lock.Unlock()
+	return token, nil
+}
+
+// Close closes the websocket. Run will send a WebSocketEventClosed event,
+// if the channel is ready to receive it, and return.
+func (s *ManagedWebSocket) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		s.lock.Lock()
+		if s.client != nil {
+			s.client.Close()
+		}
+		s.lock.Unlock()
+	})
+}
+
+func (s *ManagedWebSocket) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run connects and sends events to channel until Close is called, so is
+// usually run in its own goroutine.
+func (s *ManagedWebSocket) Run(channel chan<- WebSocketEvent) {
+	delay := s.minReconnectDelay
+	for {
+		client, err := s.connect()
+		if err == nil {
+			delay = s.minReconnectDelay
+			if !s.emit(channel, WebSocketEvent{Type: WebSocketEventConnected}) {
+				break
+			}
+			err = s.readLoop(client, channel)
+			client.Close()
+			s.lock.Lock()
+			s.client = nil
+			s.lock.Unlock()
+		}
+		if s.isClosed() {
+			break
+		}
+		if !s.emit(channel, WebSocketEvent{
+			Type: WebSocketEventDisconnected,
+			Err:  err,
+		}) {
+			break
+		}
+
+		select {
+		case <-s.closed:
+		case <-time.After(delay):
+		}
+		if s.isClosed() {
+			break
+		}
+		delay *= 2
+		if delay > s.maxReconnectDelay {
+			delay = s.maxReconnectDelay
+		}
+	}
+
+	select {
+	case channel <- WebSocketEvent{Type: WebSocketEventClosed}:
+	default:
+	}
+}
+
+// emit sends an event unless the websocket is closed first.
+func (s *ManagedWebSocket) emit(channel chan<- WebSocketEvent, event WebSocketEvent) bool {
+	select {
+	case <-s.closed:
+		return false
+	case channel <- event:
+		return true
+	}
+}
+
+func (s *ManagedWebSocket) connect() (*WebSocketClient, error) {
+	client := NewWebSocketClient(s.clientOpts...)
+	if err := client.Connect(); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	if s.isClosed() {
+		s.lock.Unlock()
+		client.Close()
+		return nil, fmt.Errorf("websocket closed")
+	}
+	s.client = client
+	// Tokens are only valid for one connection.
+	s.token = ""
+	for _, book := range s.books {
+		book.Reset()
+	}
+	subscriptions := append([]subscriptionEntry{}, s.subscriptions...)
+	s.lock.Unlock()
+
+	for _, entry := range subscriptions {
+		if err := s.send(client, entry.subscription, entry.pairs); err != nil {
+			client.Close()
+			s.lock.Lock()
+			s.client = nil
+			s.lock.Unlock()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func (s *ManagedWebSocket) readLoop(client *WebSocketClient, channel chan<- WebSocketEvent) error {
+	conn := client.Conn
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ping := time.NewTicker(s.pingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ping.C:
+				client.Ping()
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+		_, body, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		message, err := DecodeWebSocketMessage(body)
+		if err != nil {
+			if !s.emit(channel, WebSocketEvent{
+				Type: WebSocketEventError,
+				Err:  err,
+			}) {
+				return nil
+			}
+			continue
+		}
+
+		event := WebSocketEvent{
+			Type:    WebSocketEventMessage,
+			Message: message,
+		}
+		switch {
+		case message.Event == EventHeartbeat || message.Event == EventPong:
+			// Only used to keep the read deadline.
+			continue
+		case message.SubscriptionStatus != nil:
+			s.handleSubscriptionStatus(message.SubscriptionStatus)
+		case message.BookUpdate != nil:
+			book := s.Book(message.Pair)
+			if book == nil {
+				break
+			}
+			if err := book.Apply(message.BookUpdate); err != nil {
+				if errors.Is(err, ErrBookNotSynced) {
+					// Updates still in flight after a resubscribe.
+					continue
+				}
+				s.resubscribeBook(client, message.Pair)
+				event = WebSocketEvent{
+					Type: WebSocketEventError,
+					Err:  fmt.Errorf("%s: %w", message.Pair, err),
+				}
+				break
+			}
+			event.Book = book.Copy()
+		}
+		if !s.emit(channel, event) {
+			return nil
+		}
+	}
+}
+
+// handleSubscriptionStatus drops rejected subscriptions so they aren't
+// restored on reconnect.
+func (s *ManagedWebSocket) handleSubscriptionStatus(status *SubscriptionStatus) {
+	if status.Status != "error" {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	subscription := subscriptionKey(status.Subscription)
+	if status.Pair != "" {
+		s.removeSubscription(subscription, []string{status.Pair})
+		if subscription.Name == ChannelBook {
+			delete(s.books, status.Pair)
+		}
+	} else if subscription.Name != "" {
+		s.removeSubscription(subscription, nil)
+	}
+}
+
+// resubscribeBook gets a new snapshot for a book that failed its checksum.
+func (s *ManagedWebSocket) resubscribeBook(client *WebSocketClient, pair string) {
+	s.lock.Lock()
+	var subscription *Subscription
+	for _, entry := range s.subscriptions {
+		if entry.subscription.Name == ChannelBook && containsString(entry.pairs, pair) {
+			sub := entry.subscription
+			subscription = &sub
+		}
+	}
+	s.lock.Unlock()
+	if subscription == nil {
+		return
+	}
+	if _, err := client.Unsubscribe(*subscription, pair); err != nil {
+		client.Close()
+		return
+	}
+	if _, err := client.Subscribe(*subscription, pair); err != nil {
+		client.Close()
+	}
+}
@@ -0,0 +1,186 @@
+package kraken
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestWebSocketServer(t *testing.T, handler func(n int32, conn *websocket.Conn)) *httptest.Server {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		handler(atomic.AddInt32(&connections, 1), conn)
+	}))
+}
+
+func readRequest(t *testing.T, conn *websocket.Conn) subscribeRequest {
+	for {
+		request := subscribeRequest{}
+		if err := conn.ReadJSON(&request); err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return request
+		}
+		if request.Event != EventPing {
+			return request
+		}
+	}
+}
+
+func waitForEvent(t *testing.T, events chan WebSocketEvent, eventType WebSocketEventType) WebSocketEvent {
+	select {
+	case event := <-events:
+		if event.Type != eventType {
+			t.Fatalf("expected %s event, got %s (%v)", eventType, event.Type, event.Err)
+		}
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for %s event", eventType)
+	}
+	return WebSocketEvent{}
+}
+
+func TestManagedWebSocketBook(t *testing.T) {
+	snapshot := `[1,{"as":[["5541.30000","2.50700000","1534614248.123678"]],"bs":[["5541.20000","1.52900000","1534614248.765567"]]},"book-10","XBT/USD"]`
+	checksum := crc32.ChecksumIEEE([]byte("554130000" + "250700000" + "554120000" + "100000000"))
+	update := fmt.Sprintf(`[1,{"b":[["5541.20000","1.00000000","1534614249.0"]],"c":"%d"},"book-10","XBT/USD"]`, checksum)
+	bad := `[1,{"b":[["5541.20000","2.00000000","1534614250.0"]],"c":"1"},"book-10","XBT/USD"]`
+
+	server := newTestWebSocketServer(t, func(n int32, conn *websocket.Conn) {
+		request := readRequest(t, conn)
+		if request.Event != EventSubscribe || request.Subscription.Name != ChannelBook ||
+			request.Subscription.Depth != DefaultBookDepth || request.Pair[0] != "XBT/USD" {
+			t.Errorf("unexpected request: %+v", request)
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"heartbeat"}`))
+		conn.WriteMessage(websocket.TextMessage, []byte(snapshot))
+		if n > 1 {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(update))
+		conn.WriteMessage(websocket.TextMessage, []byte(bad))
+		if request := readRequest(t, conn); request.Event != EventUnsubscribe {
+			t.Errorf("expected unsubscribe, got %+v", request)
+		}
+		if request := readRequest(t, conn); request.Event != EventSubscribe {
+			t.Errorf("expected subscribe, got %+v", request)
+		}
+		// Drop the connection to test the subscription is restored.
+	})
+	defer server.Close()
+
+	ws := NewManagedWebSocket(
+		WithWebSocketClientOptions(WithWebSocketURL(
+			"ws"+strings.TrimPrefix(server.URL, "http"))),
+		WithWebSocketReconnectDelay(time.Millisecond, 10*time.Millisecond))
+	if err := ws.Subscribe(BookSubscription(0), "XBT/USD"); err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan WebSocketEvent, 1)
+	go ws.Run(events)
+
+	waitForEvent(t, events, WebSocketEventConnected)
+	event := waitForEvent(t, events, WebSocketEventMessage)
+	if event.Book == nil || !event.Book.Synced() {
+		t.Fatalf("expected a synced book: %+v", event)
+	}
+	event = waitForEvent(t, events, WebSocketEventMessage)
+	if bid, _ := event.Book.BestBid(); bid.Volume != 1 {
+		t.Fatalf("unexpected best bid: %+v", bid)
+	}
+	event = waitForEvent(t, events, WebSocketEventError)
+	if !errors.Is(event.Err, ErrBookChecksum) {
+		t.Fatalf("expected checksum error, got %v", event.Err)
+	}
+	waitForEvent(t, events, WebSocketEventDisconnected)
+	waitForEvent(t, events, WebSocketEventConnected)
+	event = waitForEvent(t, events, WebSocketEventMessage)
+	if !ws.Book("XBT/USD").Synced() {
+		t.Fatal("expected the book to be synced after reconnect")
+	}
+
+	ws.Close()
+	select {
+	case event := <-events:
+		if event.Type != WebSocketEventClosed {
+			t.Fatalf("expected closed event, got %s", event.Type)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for closed event")
+	}
+}
+
+func TestManagedWebSocketPrivate(t *testing.T) {
+	server := newTestWebSocketServer(t, func(n int32, conn *websocket.Conn) {
+		request := readRequest(t, conn)
+		if request.Subscription.Name != ChannelOpenOrders ||
+			request.Subscription.Token != fmt.Sprintf("token-%d", n) {
+			t.Errorf("unexpected request: %+v", request)
+		}
+		status, _ := json.Marshal(map[string]interface{}{
+			"event":       EventSubscriptionStatus,
+			"channelName": ChannelOpenOrders,
+			"status":      "subscribed",
+			"reqid":       request.ReqID,
+		})
+		conn.WriteMessage(websocket.TextMessage, status)
+		if n > 1 {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}
+	})
+	defer server.Close()
+
+	tokens := 0
+	ws := NewManagedWebSocket(
+		WithWebSocketClientOptions(WithWebSocketURL(
+			"ws"+strings.TrimPrefix(server.URL, "http"))),
+		WithWebSocketReconnectDelay(time.Millisecond, 10*time.Millisecond),
+		WithWebSocketToken(func() (string, error) {
+			tokens++
+			return fmt.Sprintf("token-%d", tokens), nil
+		}))
+	if err := ws.Subscribe(OpenOrdersSubscription()); err != nil {
+		t.Fatal(err)
+	}
+	events := make(chan WebSocketEvent, 1)
+	go ws.Run(events)
+	defer ws.Close()
+
+	waitForEvent(t, events, WebSocketEventConnected)
+	event := waitForEvent(t, events, WebSocketEventMessage)
+	if event.Message.SubscriptionStatus == nil || event.Message.SubscriptionStatus.Status != "subscribed" {
+		t.Fatalf("unexpected message: %+v", event.Message)
+	}
+	waitForEvent(t, events, WebSocketEventDisconnected)
+	waitForEvent(t, events, WebSocketEventConnected)
+	waitForEvent(t, events, WebSocketEventMessage)
+	if tokens != 2 {
+		t.Fatalf("expected a token per connection, got %d", tokens)
+	}
+
+	if err := NewManagedWebSocket().Subscribe(OwnTradesSubscription()); err == nil {
+		t.Fatal("expected an error subscribing to a private channel without a token")
+	}
+}
@@ -0,0 +1,425 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/khayrullo/cryptotrader/util"
+	"strconv"
+	"time"
+)
+
+// OHLC intervals in minutes.
+const (
+	OHLCInterval1m  = 1
+	OHLCInterval5m  = 5
+	OHLCInterval15m = 15
+	OHLCInterval30m = 30
+	OHLCInterval1h  = 60
+	OHLCInterval4h  = 240
+	OHLCInterval1d  = 1440
+	OHLCInterval1w  = 10080
+	OHLCInterval15d = 21600
+)
+
+type Candle struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	VWAP   float64
+	Volume float64
+	Count  int64
+}
+
+type OHLCResponse struct {
+	Pair    string
+	Candles []Candle
+
+	// The cursor to pass as since to get the candles committed after
+	// these. The last candle is not final and is returned again.
+	Last int64
+}
+
+type DepthEntry struct {
+	Price     float64
+	Volume    float64
+	Timestamp time.Time
+}
+
+type DepthResponse struct {
+	Pair string
+
+	// Asks are sorted from lowest to highest price, bids from highest to
+	// lowest.
+	Asks []DepthEntry
+	Bids []DepthEntry
+}
+
+type RecentTrade struct {
+	Price     float64
+	Volume    float64
+	Time      time.Time
+	Side      OrderSide
+	OrderType OrderType
+	Misc      string
+
+	// Zero on older responses without trade IDs.
+	TradeID int64
+}
+
+type RecentTradesResponse struct {
+	Pair   string
+	Trades []RecentTrade
+
+	// The cursor to pass as since to get the following trades, a
+	// timestamp in nanoseconds.
+	Last int64
+}
+
+type SpreadEntry struct {
+	Time time.Time
+	Bid  float64
+	Ask  float64
+}
+
+type SpreadResponse struct {
+	Pair    string
+	Entries []SpreadEntry
+
+	// The cursor to pass as since to get the following entries.
+	Last int64
+}
+
+// rawPairResult holds a public market data result, which has the data keyed
+// by the pair name next to a "last" cursor.
+type rawPairResult struct {
+	Pair string
+	Data json.RawMessage
+	Last json.RawMessage
+}
+
+func (c *Client) getPairResult(endpoint string, params map[string]interface{}) (*rawPairResult, error) {
+	result := map[string]json.RawMessage{}
+	if err := c.getAndDecode(endpoint, params, &result); err != nil {
+		return nil, err
+	}
+	pairResult := rawPairResult{}
+	for key, value := range result {
+		if key == "last" {
+			pairResult.Last = value
+		} else {
+			pairResult.Pair = key
+			pairResult.Data = value
+		}
+	}
+	if pairResult.Pair == "" {
+		return nil, fmt.Errorf("no pair in response")
+	}
+	return &pairResult, nil
+}
+
+// decodeRows decodes the array of arrays format used by the market data
+// endpoints, with numbers as json.Number.
+func decodeRows(data json.RawMessage) ([][]interface{}, error) {
+	var rows [][]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// parseValue parses a number that Kraken may send as a string or a number.
+func parseValue(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case json.Number:
+		return v.Float64()
+	}
+	return 0, fmt.Errorf("unexpected value: %v", value)
+}
+
+func parseRow(row []interface{}, count int) ([]float64, error) {
+	if len(row) < count {
+		return nil, fmt.Errorf("expected %d values, got %d", count, len(row))
+	}
+	values := make([]float64, count)
+	for i := 0; i < count; i++ {
+		value, err := parseValue(row[i])
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// parseLast parses the last cursor, which is a number or a string
+// depending on the endpoint.
+func parseLast(last json.RawMessage) (int64, error) {
+	if len(last) == 0 {
+		return 0, nil
+	}
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(last))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(fmt.Sprintf("%v", value), 10, 64)
+}
+
+// OHLC returns the candles of a pair with an interval in minutes, starting
+// after the since cursor, or the last 720 if since is 0.
+func (c *Client) OHLC(pair string, interval int, since int64) (*OHLCResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if interval > 0 {
+		params["interval"] = interval
+	}
+	if since > 0 {
+		params["since"] = since
+	}
+	result, err := c.getPairResult("/0/public/OHLC", params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeRows(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := OHLCResponse{Pair: result.Pair}
+	for _, row := range rows {
+		values, err := parseRow(row, 8)
+		if err != nil {
+			return nil, err
+		}
+		response.Candles = append(response.Candles, Candle{
+			Time:   time.Unix(int64(values[0]), 0),
+			Open:   values[1],
+			High:   values[2],
+			Low:    values[3],
+			Close:  values[4],
+			VWAP:   values[5],
+			Volume: values[6],
+			Count:  int64(values[7]),
+		})
+	}
+	if response.Last, err = parseLast(result.Last); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Depth returns the order book of a pair with up to count levels per side,
+// or the Kraken default if count is 0.
+func (c *Client) Depth(pair string, count int) (*DepthResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if count > 0 {
+		params["count"] = count
+	}
+	result, err := c.getPairResult("/0/public/Depth", params)
+	if err != nil {
+		return nil, err
+	}
+	var raw struct {
+		Asks json.RawMessage `json:"asks"`
+		Bids json.RawMessage `json:"bids"`
+	}
+	if err := json.Unmarshal(result.Data, &raw); err != nil {
+		return nil, err
+	}
+
+	response := DepthResponse{Pair: result.Pair}
+	if response.Asks, err = parseDepthEntries(raw.Asks); err != nil {
+		return nil, err
+	}
+	if response.Bids, err = parseDepthEntries(raw.Bids); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func parseDepthEntries(data json.RawMessage) ([]DepthEntry, error) {
+	rows, err := decodeRows(data)
+	if err != nil {
+		return nil, err
+	}
+	entries := []DepthEntry{}
+	for _, row := range rows {
+		values, err := parseRow(row, 3)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, DepthEntry{
+			Price:     values[0],
+			Volume:    values[1],
+			Timestamp: util.Float64ToTime(values[2]),
+		})
+	}
+	return entries, nil
+}
+
+// RecentTrades returns up to 1000 public trades of a pair starting after
+// the since cursor, or the most recent trades if since is 0. The cursor is
+// a timestamp in nanoseconds, so a time can be converted with
+// t.UnixNano().
+func (c *Client) RecentTrades(pair string, since int64) (*RecentTradesResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if since > 0 {
+		params["since"] = since
+	}
+	result, err := c.getPairResult("/0/public/Trades", params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeRows(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := RecentTradesResponse{Pair: result.Pair}
+	for _, row := range rows {
+		values, err := parseRow(row, 3)
+		if err != nil {
+			return nil, err
+		}
+		trade := RecentTrade{
+			Price:  values[0],
+			Volume: values[1],
+			Time:   util.Float64ToTime(values[2]),
+		}
+		if len(row) > 5 {
+			side, _ := row[3].(string)
+			if side == "b" {
+				trade.Side = OrderSideBuy
+			} else {
+				trade.Side = OrderSideSell
+			}
+			orderType, _ := row[4].(string)
+			if orderType == "m" {
+				trade.OrderType = OrderTypeMarket
+			} else {
+				trade.OrderType = OrderTypeLimit
+			}
+			trade.Misc, _ = row[5].(string)
+		}
+		if len(row) > 6 {
+			if id, ok := row[6].(json.Number); ok {
+				trade.TradeID, _ = id.Int64()
+			}
+		}
+		response.Trades = append(response.Trades, trade)
+	}
+	if response.Last, err = parseLast(result.Last); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// RecentTradesRange returns the public trades of a pair between start and
+// end, following the last cursor from page to page. A zero start begins
+// with the most recent trades, and a zero end reads up to the most recent
+// trade. Rate limited calls are retried up to maxRateLimitRetries times in
+// a row.
+func (c *Client) RecentTradesRange(pair string, start time.Time, end time.Time) ([]RecentTrade, error) {
+	trades := []RecentTrade{}
+	since := int64(0)
+	if !start.IsZero() {
+		since = start.UnixNano()
+	}
+	retries := 0
+	for {
+		response, err := c.RecentTrades(pair, since)
+		if err != nil {
+			if errors.Is(err, ErrRateLimited) && retries < maxRateLimitRetries {
+				retries++
+				c.rateLimitExceeded()
+				continue
+			}
+			return nil, err
+		}
+		retries = 0
+		for _, trade := range response.Trades {
+			if !end.IsZero() && trade.Time.After(end) {
+				return trades, nil
+			}
+			trades = append(trades, trade)
+		}
+		if len(response.Trades) == 0 || response.Last == since {
+			return trades, nil
+		}
+		since = response.Last
+	}
+}
+
+// Spread returns the recent best bid and ask of a pair, starting after the
+// since cursor if not 0.
+func (c *Client) Spread(pair string, since int64) (*SpreadResponse, error) {
+	params := map[string]interface{}{
+		"pair": pair,
+	}
+	if since > 0 {
+		params["since"] = since
+	}
+	result, err := c.getPairResult("/0/public/Spread", params)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := decodeRows(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	response := SpreadResponse{Pair: result.Pair}
+	for _, row := range rows {
+		values, err := parseRow(row, 3)
+		if err != nil {
+			return nil, err
+		}
+		response.Entries = append(response.Entries, SpreadEntry{
+			Time: time.Unix(int64(values[0]), 0),
+			Bid:  values[1],
+			Ask:  values[2],
+		})
+	}
+	if response.Last, err = parseLast(result.Last); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,165 @@
+package kraken
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTickerStats(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"XXBTZEUR":{
+			"a":["30300.10000","1","1.000"],"b":["30300.00000","2","2.500"],
+			"c":["30303.20000","0.00067643"],"v":["4083.67001100","4412.73601799"],
+			"p":["30706.77771","30689.13205"],"t":[34619,38907],
+			"l":["29868.30000","29868.30000"],"h":["31631.00000","31631.00000"],
+			"o":"30502.80000"}}}`))
+	})
+	defer server.Close()
+	client.apiKey = ""
+
+	tickers, err := client.Ticker("XXBTZEUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticker, ok := tickers["BTC/EUR"]
+	if !ok {
+		t.Fatalf("missing ticker: %v", tickers)
+	}
+	if ticker.Ask != 30300.1 || ticker.BidVolume != 2.5 || ticker.LastVolume != 0.00067643 ||
+		ticker.Volume24h != 4412.73601799 || ticker.VWAPToday != 30706.77771 ||
+		ticker.Trades24h != 38907 || ticker.LowToday != 29868.3 ||
+		ticker.High24h != 31631 || ticker.Open != 30502.8 {
+		t.Fatalf("unexpected ticker: %+v", ticker)
+	}
+}
+
+func TestOHLC(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("interval") != "60" || query.Get("since") != "1688666400" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":[
+			[1688670000,"30306.1","30306.2","30305.7","30305.7","30306.1","3.39243896",23],
+			[1688673600,"30305.7","30305.8","30300.0","30302.1","30303.4","1.5",7]],
+			"last":1688670000}}`))
+	})
+	defer server.Close()
+
+	response, err := client.OHLC("XBTUSD", OHLCInterval1h, 1688666400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Pair != "XXBTZUSD" || response.Last != 1688670000 || len(response.Candles) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	candle := response.Candles[0]
+	if !candle.Time.Equal(time.Unix(1688670000, 0)) || candle.High != 30306.2 ||
+		candle.Volume != 3.39243896 || candle.Count != 23 {
+		t.Fatalf("unexpected candle: %+v", candle)
+	}
+}
+
+func TestDepth(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":{
+			"asks":[["30384.10000","2.059",1688671659],["30387.90000","1.500",1688671380]],
+			"bids":[["30297.00000","1.115",1688671636]]}}}`))
+	})
+	defer server.Close()
+
+	book, err := NewExchange(client).OrderBook("XXBTZUSD", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Asks) != 2 || book.Asks[1].Price != 30387.9 || book.Bids[0].Quantity != 1.115 {
+		t.Fatalf("unexpected book: %+v", book)
+	}
+}
+
+func TestRecentTradesRange(t *testing.T) {
+	pages := [][]string{
+		{`["30243.40000","0.34507674",1688669597.8277369,"b","m","",61044952]`,
+			`["30243.30000","0.00376960",1688669598.2804112,"s","l","",61044953]`},
+		{`["30240.00000","0.10000000",1688669600.5,"b","l","",61044954]`,
+			`["30241.00000","0.20000000",1688669700.5,"s","m","",61044955]`},
+		{},
+	}
+	sinces := []string{}
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		since := r.URL.Query().Get("since")
+		sinces = append(sinces, since)
+		page := len(sinces) - 1
+		last := fmt.Sprintf("%d", 1688669598280411200+page)
+		trades := ""
+		for i, trade := range pages[page] {
+			if i > 0 {
+				trades += ","
+			}
+			trades += trade
+		}
+		fmt.Fprintf(w, `{"error":[],"result":{"XXBTZUSD":[%s],"last":"%s"}}`, trades, last)
+	})
+	defer server.Close()
+
+	start := time.Unix(1688669590, 0)
+	trades, err := client.RecentTradesRange("XBTUSD", start, time.Unix(1688669650, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 {
+		t.Fatalf("expected 3 trades, got %d", len(trades))
+	}
+	if trades[0].Side != OrderSideBuy || trades[0].OrderType != OrderTypeMarket ||
+		trades[0].TradeID != 61044952 || trades[1].Side != OrderSideSell {
+		t.Fatalf("unexpected trades: %+v", trades)
+	}
+	if len(sinces) != 2 || sinces[0] != "1688669590000000000" || sinces[1] != "1688669598280411200" {
+		t.Fatalf("unexpected cursors: %v", sinces)
+	}
+}
+
+func TestRecentTradesRangeZeroStart(t *testing.T) {
+	sinces := []string{}
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		sinces = append(sinces, r.URL.Query().Get("since"))
+		w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":[],"last":"1688669598280411200"}}`))
+	})
+	defer server.Close()
+
+	if _, err := client.RecentTradesRange("XBTUSD", time.Time{}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(sinces) != 1 || sinces[0] != "" {
+		t.Fatalf("expected no since cursor, got %v", sinces)
+	}
+}
+
+func TestSpread(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"XXBTZUSD":[[1688671834,"30292.10000","30297.50000"]],"last":1688672106}}`))
+	})
+	defer server.Close()
+
+	response, err := client.Spread("XBTUSD", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Last != 1688672106 || len(response.Entries) != 1 ||
+		response.Entries[0].Bid != 30292.1 || response.Entries[0].Ask != 30297.5 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestMarketDataError(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+	})
+	defer server.Close()
+
+	if _, err := client.Depth("NOPE", 0); err == nil || err.Error() != "EQuery:Unknown asset pair" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
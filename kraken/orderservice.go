@@ -0,0 +1,555 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"github.com/khayrullo/cryptotrader/util"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type OrderSide string
+
+const (
+	OrderSideBuy  OrderSide = "buy"
+	OrderSideSell OrderSide = "sell"
+)
+
+type OrderType string
+
+const (
+	OrderTypeMarket            OrderType = "market"
+	OrderTypeLimit             OrderType = "limit"
+	OrderTypeStopLoss          OrderType = "stop-loss"
+	OrderTypeTakeProfit        OrderType = "take-profit"
+	OrderTypeStopLossLimit     OrderType = "stop-loss-limit"
+	OrderTypeTakeProfitLimit   OrderType = "take-profit-limit"
+	OrderTypeTrailingStop      OrderType = "trailing-stop"
+	OrderTypeTrailingStopLimit OrderType = "trailing-stop-limit"
+	OrderTypeSettlePosition    OrderType = "settle-position"
+)
+
+// requiresPrice returns true if the order type needs a price, which is the
+// limit price of a limit order and the trigger price of the others.
+func (t OrderType) requiresPrice() bool {
+	switch t {
+	case OrderTypeMarket, OrderTypeSettlePosition:
+		return false
+	}
+	return true
+}
+
+// requiresPrice2 returns true if the order type needs a secondary price,
+// the limit price of a triggered limit order.
+func (t OrderType) requiresPrice2() bool {
+	switch t {
+	case OrderTypeStopLossLimit, OrderTypeTakeProfitLimit,
+		OrderTypeTrailingStopLimit:
+		return true
+	}
+	return false
+}
+
+type OrderFlag string
+
+const (
+	OrderFlagPostOnly           OrderFlag = "post"
+	OrderFlagFeeInBase          OrderFlag = "fcib"
+	OrderFlagFeeInQuote         OrderFlag = "fciq"
+	OrderFlagNoMarketProtection OrderFlag = "nompp"
+	OrderFlagVolumeInQuote      OrderFlag = "viqc"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceGTD TimeInForce = "GTD"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusPending  OrderStatus = "pending"
+	OrderStatusOpen     OrderStatus = "open"
+	OrderStatusClosed   OrderStatus = "closed"
+	OrderStatusCanceled OrderStatus = "canceled"
+	OrderStatusExpired  OrderStatus = "expired"
+)
+
+// IsTerminal returns true if the order will not change anymore.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case OrderStatusClosed, OrderStatusCanceled, OrderStatusExpired:
+		return true
+	}
+	return false
+}
+
+// The maximum number of transaction IDs QueryOrders sends per request.
+const maxQueryOrdersIDs = 50
+
+type AddOrderRequest struct {
+	Pair      string
+	Side      OrderSide
+	OrderType OrderType
+	Volume    float64
+
+	// Price and Price2 are absolute prices, or offsets from the last
+	// traded price prefixed with "+" or "-" and optionally suffixed with
+	// "%", ie: "+5%". Trailing stops require an offset prefixed with "+".
+	Price  string
+	Price2 string
+
+	// The leverage, ie: "2" or "2:1". Empty for none.
+	Leverage string
+
+	OFlags      []OrderFlag
+	TimeInForce TimeInForce
+
+	// Scheduled start and expiration. Zero values are not sent.
+	StartTime  time.Time
+	ExpireTime time.Time
+
+	UserRef int32
+
+	// An optional conditional close order, placed when this one fills.
+	CloseOrderType OrderType
+	ClosePrice     string
+	ClosePrice2    string
+
+	// ValidateOnly has Kraken validate the order without placing it.
+	ValidateOnly bool
+}
+
+// Validate checks that the fields required by the order type are set.
+func (r *AddOrderRequest) Validate() error {
+	if r.Pair == "" {
+		return fmt.Errorf("pair is required")
+	}
+	if r.Side != OrderSideBuy && r.Side != OrderSideSell {
+		return fmt.Errorf("invalid side: %q", r.Side)
+	}
+	if r.OrderType == "" {
+		return fmt.Errorf("order type is required")
+	}
+	if r.Volume <= 0 && r.OrderType != OrderTypeSettlePosition {
+		return fmt.Errorf("volume must be greater than 0")
+	}
+	if r.OrderType.requiresPrice() && r.Price == "" {
+		return fmt.Errorf("%s order requires a price", r.OrderType)
+	}
+	if r.OrderType.requiresPrice2() && r.Price2 == "" {
+		return fmt.Errorf("%s order requires a secondary price", r.OrderType)
+	}
+	if r.CloseOrderType != "" && r.CloseOrderType.requiresPrice() &&
+		r.ClosePrice == "" {
+		return fmt.Errorf("%s close order requires a price", r.CloseOrderType)
+	}
+	if r.TimeInForce == TimeInForceGTD && r.ExpireTime.IsZero() {
+		return fmt.Errorf("GTD order requires an expiration time")
+	}
+	return nil
+}
+
+func (r *AddOrderRequest) params() map[string]interface{} {
+	params := map[string]interface{}{
+		"pair":      r.Pair,
+		"type":      r.Side,
+		"ordertype": r.OrderType,
+		"volume":    strconv.FormatFloat(r.Volume, 'f', -1, 64),
+	}
+	if r.Price != "" {
+		params["price"] = r.Price
+	}
+	if r.Price2 != "" {
+		params["price2"] = r.Price2
+	}
+	if r.Leverage != "" {
+		params["leverage"] = r.Leverage
+	}
+	if len(r.OFlags) > 0 {
+		flags := []string{}
+		for _, flag := range r.OFlags {
+			flags = append(flags, string(flag))
+		}
+		params["oflags"] = strings.Join(flags, ",")
+	}
+	if r.TimeInForce != "" {
+		params["timeinforce"] = r.TimeInForce
+	}
+	if !r.StartTime.IsZero() {
+		params["starttm"] = r.StartTime.Unix()
+	}
+	if !r.ExpireTime.IsZero() {
+		params["expiretm"] = r.ExpireTime.Unix()
+	}
+	if r.UserRef != 0 {
+		params["userref"] = r.UserRef
+	}
+	if r.CloseOrderType != "" {
+		params["close[ordertype]"] = r.CloseOrderType
+		if r.ClosePrice != "" {
+			params["close[price]"] = r.ClosePrice
+		}
+		if r.ClosePrice2 != "" {
+			params["close[price2]"] = r.ClosePrice2
+		}
+	}
+	if r.ValidateOnly {
+		params["validate"] = true
+	}
+	return params
+}
+
+type AddOrderResponse struct {
+	Description struct {
+		Order string `json:"order"`
+		Close string `json:"close"`
+	} `json:"descr"`
+
+	// The IDs of the placed orders, empty when only validating.
+	TxIDs []string `json:"txid"`
+}
+
+type CancelOrderResponse struct {
+	Count   int64 `json:"count"`
+	Pending bool  `json:"pending"`
+}
+
+type RawOrderDescription struct {
+	Pair      string `json:"pair"`
+	Type      string `json:"type"`
+	OrderType string `json:"ordertype"`
+	Price     string `json:"price"`
+	Price2    string `json:"price2"`
+	Leverage  string `json:"leverage"`
+	Order     string `json:"order"`
+	Close     string `json:"close"`
+}
+
+type RawOrder struct {
+	RefID          string              `json:"refid"`
+	UserRef        int64               `json:"userref"`
+	Status         string              `json:"status"`
+	Reason         string              `json:"reason"`
+	OpenTime       float64             `json:"opentm"`
+	StartTime      float64             `json:"starttm"`
+	ExpireTime     float64             `json:"expiretm"`
+	CloseTime      float64             `json:"closetm"`
+	Description    RawOrderDescription `json:"descr"`
+	Volume         string              `json:"vol"`
+	VolumeExecuted string              `json:"vol_exec"`
+	Cost           string              `json:"cost"`
+	Fee            string              `json:"fee"`
+	Price          string              `json:"price"`
+	StopPrice      string              `json:"stopprice"`
+	LimitPrice     string              `json:"limitprice"`
+	Misc           string              `json:"misc"`
+	OFlags         string              `json:"oflags"`
+	Trades         []string            `json:"trades"`
+}
+
+type Order struct {
+	ID      string
+	RefID   string
+	UserRef int64
+	Status  OrderStatus
+	Reason  string
+
+	// Zero if not set.
+	OpenTime   time.Time
+	StartTime  time.Time
+	ExpireTime time.Time
+	CloseTime  time.Time
+
+	Pair        string
+	Side        OrderSide
+	OrderType   OrderType
+	Price       float64
+	Price2      float64
+	Leverage    string
+	Description string
+
+	Volume         float64
+	VolumeExecuted float64
+	Cost           float64
+	Fee            float64
+
+	// The average price of the executed volume.
+	AveragePrice float64
+
+	StopPrice  float64
+	LimitPrice float64
+	Misc       string
+	OFlags     []OrderFlag
+
+	// Trade IDs, only set when requested.
+	Trades []string
+}
+
+func NewOrderFromRaw(id string, raw RawOrder) Order {
+	order := Order{}
+	order.ID = id
+	order.RefID = raw.RefID
+	order.UserRef = raw.UserRef
+	order.Status = OrderStatus(raw.Status)
+	order.Reason = raw.Reason
+	order.OpenTime = optionalTime(raw.OpenTime)
+	order.StartTime = optionalTime(raw.StartTime)
+	order.ExpireTime = optionalTime(raw.ExpireTime)
+	order.CloseTime = optionalTime(raw.CloseTime)
+	order.Pair = raw.Description.Pair
+	order.Side = OrderSide(raw.Description.Type)
+	order.OrderType = OrderType(raw.Description.OrderType)
+	order.Price, _ = strconv.ParseFloat(raw.Description.Price, 64)
+	order.Price2, _ = strconv.ParseFloat(raw.Description.Price2, 64)
+	order.Leverage = raw.Description.Leverage
+	order.Description = raw.Description.Order
+	order.Volume, _ = strconv.ParseFloat(raw.Volume, 64)
+	order.VolumeExecuted, _ = strconv.ParseFloat(raw.VolumeExecuted, 64)
+	order.Cost, _ = strconv.ParseFloat(raw.Cost, 64)
+	order.Fee, _ = strconv.ParseFloat(raw.Fee, 64)
+	order.AveragePrice, _ = strconv.ParseFloat(raw.Price, 64)
+	order.StopPrice, _ = strconv.ParseFloat(raw.StopPrice, 64)
+	order.LimitPrice, _ = strconv.ParseFloat(raw.LimitPrice, 64)
+	order.Misc = raw.Misc
+	if raw.OFlags != "" {
+		for _, flag := range strings.Split(raw.OFlags, ",") {
+			order.OFlags = append(order.OFlags, OrderFlag(flag))
+		}
+	}
+	order.Trades = raw.Trades
+	return order
+}
+
+func optionalTime(value float64) time.Time {
+	if value == 0 {
+		return time.Time{}
+	}
+	return util.Float64ToTime(value)
+}
+
+func ordersFromRaw(raw map[string]RawOrder) []Order {
+	orders := []Order{}
+	for id, order := range raw {
+		orders = append(orders, NewOrderFromRaw(id, order))
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OpenTime.Before(orders[j].OpenTime)
+	})
+	return orders
+}
+
+type OrderService struct {
+	client *Client
+}
+
+func NewOrderService(client *Client) *OrderService {
+	return &OrderService{client}
+}
+
+// AddOrder places an order, or only validates it if ValidateOnly is set.
+func (s *OrderService) AddOrder(request AddOrderRequest) (*AddOrderResponse, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+	response := AddOrderResponse{}
+	if err := s.client.postAndDecode("/0/private/AddOrder", request.params(),
+		&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// CancelOrder cancels an order by transaction ID, or all the orders with a
+// user reference.
+func (s *OrderService) CancelOrder(txid string) (*CancelOrderResponse, error) {
+	params := map[string]interface{}{
+		"txid": txid,
+	}
+	response := CancelOrderResponse{}
+	if err := s.client.postAndDecode("/0/private/CancelOrder", params,
+		&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type OpenOrdersOptions struct {
+	Trades  bool
+	UserRef int32
+}
+
+// OpenOrders returns the open orders, oldest first.
+func (s *OrderService) OpenOrders(options OpenOrdersOptions) ([]Order, error) {
+	params := map[string]interface{}{}
+	if options.Trades {
+		params["trades"] = true
+	}
+	if options.UserRef != 0 {
+		params["userref"] = options.UserRef
+	}
+	var result struct {
+		Open map[string]RawOrder `json:"open"`
+	}
+	if err := s.client.postAndDecode("/0/private/OpenOrders", params,
+		&result); err != nil {
+		return nil, err
+	}
+	return ordersFromRaw(result.Open), nil
+}
+
+type ClosedOrdersOptions struct {
+	Trades  bool
+	UserRef int32
+
+	// The range of orders to return. Zero values are not sent.
+	Start time.Time
+	End   time.Time
+
+	// Which time Start and End apply to: "open", "close" or "both". Kraken
+	// defaults to "both".
+	CloseTime string
+}
+
+func (o *ClosedOrdersOptions) params() map[string]interface{} {
+	params := map[string]interface{}{}
+	if o.Trades {
+		params["trades"] = true
+	}
+	if o.UserRef != 0 {
+		params["userref"] = o.UserRef
+	}
+	if !o.Start.IsZero() {
+		params["start"] = o.Start.Unix()
+	}
+	if !o.End.IsZero() {
+		params["end"] = o.End.Unix()
+	}
+	if o.CloseTime != "" {
+		params["closetime"] = o.CloseTime
+	}
+	return params
+}
+
+// ClosedOrdersPage returns a page of up to 50 closed orders, newest first,
+// starting at offset, and the total number of orders matching the options.
+func (s *OrderService) ClosedOrdersPage(options ClosedOrdersOptions, offset int64) ([]Order, int64, error) {
+	params := options.params()
+	if offset > 0 {
+		params["ofs"] = offset
+	}
+	var result struct {
+		Closed map[string]RawOrder `json:"closed"`
+		Count  int64               `json:"count"`
+	}
+	if err := s.client.postAndDecode("/0/private/ClosedOrders", params,
+		&result); err != nil {
+		return nil, 0, err
+	}
+	orders := ordersFromRaw(result.Closed)
+	sort.SliceStable(orders, func(i, j int) bool {
+		return orders[i].OpenTime.After(orders[j].OpenTime)
+	})
+	return orders, result.Count, nil
+}
+
+// ClosedOrders returns all the closed orders matching the options, oldest
+// first, paging through them with ofs. Rate limited calls are retried up to
+// maxRateLimitRetries times in a row.
+func (s *OrderService) ClosedOrders(options ClosedOrdersOptions) ([]Order, error) {
+	seen := map[string]bool{}
+	orders := []Order{}
+	offset := int64(0)
+	retries := 0
+	for {
+		page, count, err := s.ClosedOrdersPage(options, offset)
+		if err != nil {
+			if errors.Is(err, ErrRateLimited) && retries < maxRateLimitRetries {
+				retries++
+				s.client.rateLimitExceeded()
+				continue
+			}
+			return nil, err
+		}
+		retries = 0
+		if len(page) == 0 {
+			break
+		}
+		// Orders closing while paging shift the offsets, so the same
+		// order may be returned twice.
+		for _, order := range page {
+			if !seen[order.ID] {
+				seen[order.ID] = true
+				orders = append(orders, order)
+			}
+		}
+		offset += int64(len(page))
+		if offset >= count {
+			break
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].OpenTime.Before(orders[j].OpenTime)
+	})
+	return orders, nil
+}
+
+// QueryOrders returns orders by transaction ID, in the order requested,
+// with their trade IDs.
+func (s *OrderService) QueryOrders(txids ...string) ([]Order, error) {
+	orders := []Order{}
+	for len(txids) > 0 {
+		batch := txids
+		if len(batch) > maxQueryOrdersIDs {
+			batch = batch[:maxQueryOrdersIDs]
+		}
+		txids = txids[len(batch):]
+
+		params := map[string]interface{}{
+			"txid":   strings.Join(batch, ","),
+			"trades": true,
+		}
+		result := map[string]RawOrder{}
+		if err := s.client.postAndDecode("/0/private/QueryOrders", params,
+			&result); err != nil {
+			return nil, err
+		}
+		for _, txid := range batch {
+			raw, ok := result[txid]
+			if !ok {
+				return nil, fmt.Errorf("order %s: %w", txid, ErrUnknownOrder)
+			}
+			orders = append(orders, NewOrderFromRaw(txid, raw))
+		}
+	}
+	return orders, nil
+}
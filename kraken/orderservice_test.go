@@ -0,0 +1,178 @@
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	client := NewClient("key", "c2VjcmV0", WithBaseURL(server.URL))
+	return client, server
+}
+
+func TestAddOrderParams(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/0/private/AddOrder" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		r.ParseForm()
+		expected := map[string]string{
+			"pair":             "XXBTZUSD",
+			"type":             "buy",
+			"ordertype":        "stop-loss-limit",
+			"volume":           "1.25",
+			"price":            "+5%",
+			"price2":           "30000",
+			"leverage":         "2:1",
+			"oflags":           "post,fciq",
+			"close[ordertype]": "limit",
+			"close[price]":     "40000",
+			"validate":         "true",
+		}
+		for key, value := range expected {
+			if r.PostForm.Get(key) != value {
+				t.Errorf("%s: expected %q, got %q", key, value, r.PostForm.Get(key))
+			}
+		}
+		w.Write([]byte(`{"error":[],"result":{"descr":{"order":"buy 1.25 XBTUSD @ stop loss +5% -> limit 30000"}}}`))
+	})
+	defer server.Close()
+
+	response, err := NewOrderService(client).AddOrder(AddOrderRequest{
+		Pair:           "XXBTZUSD",
+		Side:           OrderSideBuy,
+		OrderType:      OrderTypeStopLossLimit,
+		Volume:         1.25,
+		Price:          "+5%",
+		Price2:         "30000",
+		Leverage:       "2:1",
+		OFlags:         []OrderFlag{OrderFlagPostOnly, OrderFlagFeeInQuote},
+		CloseOrderType: OrderTypeLimit,
+		ClosePrice:     "40000",
+		ValidateOnly:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.TxIDs) != 0 || !strings.HasPrefix(response.Description.Order, "buy") {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+}
+
+func TestAddOrderValidate(t *testing.T) {
+	request := AddOrderRequest{
+		Pair:      "XXBTZUSD",
+		Side:      OrderSideSell,
+		OrderType: OrderTypeTakeProfitLimit,
+		Volume:    1,
+		Price:     "50000",
+	}
+	if err := request.Validate(); err == nil {
+		t.Fatal("expected missing price2 error")
+	}
+	request.Price2 = "49000"
+	if err := request.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApiError(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":["EOrder:Insufficient funds"]}`))
+	})
+	defer server.Close()
+
+	_, err := NewOrderService(client).AddOrder(AddOrderRequest{
+		Pair:      "XXBTZUSD",
+		Side:      OrderSideBuy,
+		OrderType: OrderTypeMarket,
+		Volume:    1,
+	})
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+	if errors.Is(err, ErrUnknownOrder) {
+		t.Fatal("unexpected match of unknown order")
+	}
+	if err.Error() != "EOrder:Insufficient funds" {
+		t.Fatalf("unexpected message: %s", err)
+	}
+}
+
+func TestClosedOrdersPaging(t *testing.T) {
+	const total = 120
+	offsets := []string{}
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		offsets = append(offsets, r.PostForm.Get("ofs"))
+		if len(offsets) == 2 {
+			w.Write([]byte(`{"error":["EAPI:Rate limit exceeded"]}`))
+			return
+		}
+		ofs, _ := strconv.Atoi(r.PostForm.Get("ofs"))
+		orders := []string{}
+		// Newest first.
+		for i := total - 1 - ofs; i >= 0 && i > total-1-ofs-50; i-- {
+			orders = append(orders, fmt.Sprintf(
+				`"O%d":{"status":"closed","opentm":%d.5,"closetm":%d,"descr":{"pair":"XBTUSD","type":"sell","ordertype":"limit","price":"100"},"vol":"1","vol_exec":"1","price":"101"}`,
+				i, 1500000000+i, 1500000001+i))
+		}
+		fmt.Fprintf(w, `{"error":[],"result":{"closed":{%s},"count":%d}}`,
+			strings.Join(orders, ","), total)
+	})
+	defer server.Close()
+
+	// Don't sleep on the rate limit error.
+	limiter := NewRateLimiter(TierStarter)
+	now := time.Now()
+	limiter.now = func() time.Time {
+		return now
+	}
+	limiter.sleep = func(d time.Duration) {
+		now = now.Add(d)
+	}
+	WithRateLimiter(limiter)(client)
+
+	orders, err := NewOrderService(client).ClosedOrders(ClosedOrdersOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != total {
+		t.Fatalf("expected %d orders, got %d", total, len(orders))
+	}
+	if orders[0].ID != "O0" || orders[total-1].ID != "O119" {
+		t.Fatalf("unexpected order: %s ... %s", orders[0].ID, orders[total-1].ID)
+	}
+	if orders[0].AveragePrice != 101 || orders[0].Side != OrderSideSell ||
+		orders[0].Status != OrderStatusClosed {
+		t.Fatalf("unexpected order: %+v", orders[0])
+	}
+	if strings.Join(offsets, " ") != " 50 50 100" {
+		t.Fatalf("unexpected offsets: %q", offsets)
+	}
+}
+
+func TestQueryOrdersUnknown(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"A":{"status":"open","descr":{"type":"buy"},"vol":"2","vol_exec":"0.5"}}}`))
+	})
+	defer server.Close()
+
+	orders, err := NewOrderService(client).QueryOrders("A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coreOrderStatus(orders[0]) != "PARTIALLY_FILLED" {
+		t.Fatalf("unexpected status: %s", coreOrderStatus(orders[0]))
+	}
+	if _, err := NewOrderService(client).QueryOrders("A", "B"); !errors.Is(err, ErrUnknownOrder) {
+		t.Fatalf("expected unknown order, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+// Tier is the Kraken account verification tier, which determines the size
+// and decay rate of the private API call counter.
+type Tier int
+
+const (
+	TierStarter Tier = iota
+	TierIntermediate
+	TierPro
+)
+
+// RateLimiter implements core.RateLimiter by modelling the Kraken API call
+// counter. Each private call adds to the counter, 2 for the ledger and
+// trade history calls, and the counter decays at a fixed rate per second.
+// Public calls are limited to one per second.
+type RateLimiter struct {
+	mu       sync.Mutex
+	counter  float64
+	max      float64
+	decay    float64
+	lastTime time.Time
+
+	public *core.IntervalLimiter
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewRateLimiter(tier Tier) *RateLimiter {
+	limiter := &RateLimiter{
+		public: core.NewIntervalLimiter(1, time.Second),
+		now:    time.Now,
+		sleep:  time.Sleep,
+	}
+	switch tier {
+	case TierIntermediate:
+		limiter.max = 20
+		limiter.decay = 0.5
+	case TierPro:
+		limiter.max = 20
+		limiter.decay = 1
+	default:
+		limiter.max = 15
+		limiter.decay = 0.33
+	}
+	return limiter
+}
+
+func (l *RateLimiter) Wait(request *http.Request) {
+	path := request.URL.Path
+	if strings.HasPrefix(path, "/0/public/") {
+		l.public.Acquire(1)
+		return
+	}
+	cost := callCost(path)
+	if cost == 0 {
+		return
+	}
+	for {
+		wait := l.reserve(cost)
+		if wait <= 0 {
+			return
+		}
+		l.sleep(wait)
+	}
+}
+
+// Update does nothing as Kraken does not report the counter in responses.
+// Use Exceeded when a call fails with "EAPI:Rate limit exceeded".
+func (l *RateLimiter) Update(request *http.Request, response *http.Response) {
+}
+
+// Exceeded sets the counter to its maximum so the next private call waits
+// for it to decay.
+func (l *RateLimiter) Exceeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayCounter()
+	l.counter = l.max
+}
+
+// Counter returns the current estimated value of the call counter.
+func (l *RateLimiter) Counter() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayCounter()
+	return l.counter
+}
+
+func (l *RateLimiter) reserve(cost float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.decayCounter()
+	if l.counter+cost <= l.max {
+		l.counter += cost
+		return 0
+	}
+	seconds := (l.counter + cost - l.max) / l.decay
+	return time.Duration(math.Ceil(seconds*1000)) * time.Millisecond
+}
+
+func (l *RateLimiter) decayCounter() {
+	now := l.now()
+	if !l.lastTime.IsZero() {
+		l.counter -= now.Sub(l.lastTime).Seconds() * l.decay
+		if l.counter < 0 {
+			l.counter = 0
+		}
+	}
+	l.lastTime = now
+}
+
+// callCost returns how much a private call adds to the counter. Order
+// placement and cancellation are limited by the matching engine instead.
+func callCost(path string) float64 {
+	switch path {
+	case "/0/private/Ledgers", "/0/private/QueryLedgers",
+		"/0/private/TradesHistory", "/0/private/QueryTrades":
+		return 2
+	case "/0/private/AddOrder", "/0/private/CancelOrder":
+		return 0
+	}
+	return 1
+}
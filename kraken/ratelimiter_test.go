@@ -0,0 +1,44 @@
+package kraken
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterDecay(t *testing.T) {
+	now := time.Date(2018, 5, 1, 12, 0, 0, 0, time.UTC)
+	slept := time.Duration(0)
+
+	limiter := NewRateLimiter(TierPro)
+	limiter.now = func() time.Time {
+		return now
+	}
+	limiter.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	request, _ := http.NewRequest("POST", API_ROOT+"/0/private/Ledgers", nil)
+
+	// 10 ledger calls at a cost of 2 fill the counter of 20.
+	for i := 0; i < 10; i++ {
+		limiter.Wait(request)
+	}
+	if slept != 0 {
+		t.Fatalf("expected no wait, waited %v", slept)
+	}
+
+	// The next one has to wait for the counter to decay by 2.
+	limiter.Wait(request)
+	if slept != 2*time.Second {
+		t.Fatalf("expected to wait 2s, waited %v", slept)
+	}
+
+	// After being told the limit was exceeded wait for a full decay.
+	limiter.Exceeded()
+	limiter.Wait(request)
+	if slept != 4*time.Second {
+		t.Fatalf("expected to wait another 2s, waited %v", slept)
+	}
+}
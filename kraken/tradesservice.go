@@ -0,0 +1,199 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"errors"
+	"github.com/khayrullo/cryptotrader/util"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Trade is an entry of the account trade history.
+type Trade struct {
+	TradeID    string
+	OrderID    string
+	PositionID string
+	Pair       string
+	Timestamp  time.Time
+	Side       OrderSide
+	OrderType  OrderType
+	Price      float64
+	Cost       float64
+	Fee        float64
+	Volume     float64
+	Margin     float64
+	Misc       string
+
+	Raw RawTrade `json:"-"`
+}
+
+func NewTradeFromRaw(id string, raw RawTrade) Trade {
+	trade := Trade{}
+	trade.TradeID = id
+	trade.OrderID = raw.OrderTxID
+	trade.PositionID = raw.PosTxID
+	trade.Pair = raw.Pair
+	trade.Timestamp = util.Float64ToTime(raw.Time)
+	trade.Side = OrderSide(raw.Type)
+	trade.OrderType = OrderType(raw.OrderType)
+	trade.Price, _ = strconv.ParseFloat(raw.Price, 64)
+	trade.Cost, _ = strconv.ParseFloat(raw.Cost, 64)
+	trade.Fee, _ = strconv.ParseFloat(raw.Fee, 64)
+	trade.Volume, _ = strconv.ParseFloat(raw.Volume, 64)
+	trade.Margin, _ = strconv.ParseFloat(raw.Margin, 64)
+	trade.Misc = raw.Misc
+	trade.Raw = raw
+	return trade
+}
+
+type RawTrade struct {
+	OrderTxID string  `json:"ordertxid"`
+	PosTxID   string  `json:"postxid"`
+	Pair      string  `json:"pair"`
+	Time      float64 `json:"time"`
+	Type      string  `json:"type"`
+	OrderType string  `json:"ordertype"`
+	Price     string  `json:"price"`
+	Cost      string  `json:"cost"`
+	Fee       string  `json:"fee"`
+	Volume    string  `json:"vol"`
+	Margin    string  `json:"margin"`
+	Misc      string  `json:"misc"`
+}
+
+type RawTradesHistoryResponse struct {
+	Error  []string `json:"error"`
+	Result struct {
+		Count  int64               `json:"count"`
+		Trades map[string]RawTrade `json:"trades"`
+	} `json:"result"`
+	Raw string `json:"-"`
+}
+
+func (r *RawTradesHistoryResponse) SetRaw(raw string) {
+	r.Raw = raw
+}
+
+type TradesService struct {
+	client *Client
+}
+
+func NewTradesService(client *Client) *TradesService {
+	return &TradesService{client}
+}
+
+func (s *TradesService) RawTradesHistory(params map[string]interface{}) (*RawTradesHistoryResponse, error) {
+	endpoint := "/0/private/TradesHistory"
+	httpResponse, err := s.client.Post(endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	response := RawTradesHistoryResponse{}
+	if err := decodeBody(httpResponse, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+type GetTradesOptions struct {
+	// Only trades after Start and before End are returned. Zero values
+	// are not sent.
+	Start time.Time
+	End   time.Time
+
+	// The type of trades, ie: "all" or "no position". Kraken defaults to
+	// "all".
+	Type string
+}
+
+// Trades returns all the trades matching the options, oldest first, paging
+// through them with ofs. Rate limited calls are retried up to
+// maxRateLimitRetries times in a row.
+func (s *TradesService) Trades(options GetTradesOptions) ([]Trade, error) {
+	trades := []Trade{}
+	seen := map[string]bool{}
+	offset := 0
+	retries := 0
+
+	for {
+		params := map[string]interface{}{}
+
+		if !options.Start.IsZero() {
+			params["start"] = options.Start.Unix()
+		}
+		if !options.End.IsZero() {
+			params["end"] = options.End.Unix()
+		}
+		if options.Type != "" {
+			params["type"] = options.Type
+		}
+		if offset > 0 {
+			params["ofs"] = offset
+		}
+
+		response, err := s.RawTradesHistory(params)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response.Error) > 0 {
+			err := NewApiError(response.Error)
+			if errors.Is(err, ErrRateLimited) && retries < maxRateLimitRetries {
+				retries++
+				s.client.rateLimitExceeded()
+				continue
+			}
+			return trades, err
+		}
+		retries = 0
+
+		if len(response.Result.Trades) == 0 {
+			break
+		}
+
+		// New trades shift the offsets while paging, so the same trade may
+		// be returned twice.
+		for tradeId, v := range response.Result.Trades {
+			if !seen[tradeId] {
+				seen[tradeId] = true
+				trades = append(trades, NewTradeFromRaw(tradeId, v))
+			}
+		}
+
+		offset += len(response.Result.Trades)
+		if int64(offset) >= response.Result.Count {
+			break
+		}
+	}
+
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Timestamp.Before(trades[j].Timestamp)
+	})
+
+	return trades, nil
+}
@@ -0,0 +1,100 @@
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTradesPaging(t *testing.T) {
+	const total = 75
+	requests := 0
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		requests++
+		if r.PostForm.Get("start") != "1500000000" || r.PostForm.Get("end") != "1600000000" {
+			t.Errorf("unexpected range: %v", r.PostForm)
+		}
+		ofs, _ := strconv.Atoi(r.PostForm.Get("ofs"))
+		// Repeat the last trade of the previous page, as when a new trade
+		// shifts the offsets.
+		if ofs > 0 {
+			ofs--
+		}
+		trades := []string{}
+		for i := total - 1 - ofs; i >= 0 && i > total-1-ofs-50; i-- {
+			trades = append(trades, fmt.Sprintf(
+				`"T%d":{"ordertxid":"O%d","pair":"XXBTZUSD","time":%d.25,"type":"buy","ordertype":"limit","price":"100.0","cost":"200.0","fee":"0.32","vol":"2.0","margin":"0.0","misc":""}`,
+				i, i, 1500000000+i))
+		}
+		fmt.Fprintf(w, `{"error":[],"result":{"trades":{%s},"count":%d}}`,
+			strings.Join(trades, ","), total)
+	})
+	defer server.Close()
+
+	trades, err := NewTradesService(client).Trades(GetTradesOptions{
+		Start: time.Unix(1500000000, 0),
+		End:   time.Unix(1600000000, 0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != total {
+		t.Fatalf("expected %d trades, got %d", total, len(trades))
+	}
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+	first := trades[0]
+	if first.TradeID != "T0" || first.OrderID != "O0" || first.Side != OrderSideBuy ||
+		first.Cost != 200 || first.Fee != 0.32 || first.Volume != 2 {
+		t.Fatalf("unexpected trade: %+v", first)
+	}
+	if trades[total-1].TradeID != "T74" {
+		t.Fatalf("unexpected last trade: %+v", trades[total-1])
+	}
+}
+
+func TestTradesError(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":["EGeneral:Permission denied"]}`))
+	})
+	defer server.Close()
+
+	if _, err := NewTradesService(client).Trades(GetTradesOptions{}); err == nil ||
+		err.Error() != "EGeneral:Permission denied" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestTradesRateLimitRetries(t *testing.T) {
+	requests := 0
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"error":["EGeneral:Too many requests"]}`))
+	})
+	defer server.Close()
+
+	// Don't sleep on the rate limit error.
+	limiter := NewRateLimiter(TierStarter)
+	now := time.Now()
+	limiter.now = func() time.Time {
+		return now
+	}
+	limiter.sleep = func(d time.Duration) {
+		now = now.Add(d)
+	}
+	WithRateLimiter(limiter)(client)
+
+	_, err := NewTradesService(client).Trades(GetTradesOptions{})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if requests != maxRateLimitRetries+1 {
+		t.Fatalf("expected %d requests, got %d", maxRateLimitRetries+1, requests)
+	}
+}
@@ -0,0 +1,168 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"fmt"
+	"github.com/gorilla/websocket"
+	"sync"
+)
+
+const (
+	WS_URL      = "wss://ws.kraken.com"
+	WS_AUTH_URL = "wss://ws-auth.kraken.com"
+)
+
+type WebSocketsTokenResponse struct {
+	Token string `json:"token"`
+
+	// Seconds the token is valid for if not used to connect.
+	Expires int64 `json:"expires"`
+}
+
+// GetWebSocketsToken returns a token for the private websocket channels.
+func (c *Client) GetWebSocketsToken() (*WebSocketsTokenResponse, error) {
+	response := WebSocketsTokenResponse{}
+	if err := c.postAndDecode("/0/private/GetWebSocketsToken", nil,
+		&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// WebSocketClient is a single websocket connection. Use ManagedWebSocket
+// for a connection that handles heartbeats and reconnects.
+type WebSocketClient struct {
+	Conn *websocket.Conn
+
+	url    string
+	dialer *websocket.Dialer
+
+	writeLock sync.Mutex
+
+	lock   sync.Mutex
+	nextID int64
+}
+
+// WebSocketClientOption configures optional settings of a WebSocketClient.
+type WebSocketClientOption func(*WebSocketClient)
+
+// WithWebSocketURL sets the websocket URL. The default is WS_URL, or
+// WS_AUTH_URL for the private channels.
+func WithWebSocketURL(url string) WebSocketClientOption {
+	return func(c *WebSocketClient) {
+		c.url = url
+	}
+}
+
+// WithWebSocketDialer sets the websocket.Dialer used to connect. The
+// default is websocket.DefaultDialer.
+func WithWebSocketDialer(dialer *websocket.Dialer) WebSocketClientOption {
+	return func(c *WebSocketClient) {
+		c.dialer = dialer
+	}
+}
+
+func NewWebSocketClient(opts ...WebSocketClientOption) *WebSocketClient {
+	client := &WebSocketClient{
+		url:    WS_URL,
+		dialer: websocket.DefaultDialer,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+func (c *WebSocketClient) Connect() (err error) {
+	c.Conn, _, err = c.dialer.Dial(c.url, nil)
+	return err
+}
+
+func (c *WebSocketClient) Close() {
+	c.Conn.Close()
+}
+
+func (c *WebSocketClient) reqID() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.nextID++
+	return c.nextID
+}
+
+func (c *WebSocketClient) write(message interface{}) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.Conn.WriteJSON(message)
+}
+
+type subscribeRequest struct {
+	Event        string       `json:"event"`
+	ReqID        int64        `json:"reqid"`
+	Pair         []string     `json:"pair,omitempty"`
+	Subscription Subscription `json:"subscription"`
+}
+
+// Subscribe sends a subscribe request, returning its request ID. The result
+// is received as a subscriptionStatus event for each pair. The private
+// channels take no pairs.
+func (c *WebSocketClient) Subscribe(subscription Subscription, pairs ...string) (int64, error) {
+	return c.sendSubscription(EventSubscribe, subscription, pairs)
+}
+
+// Unsubscribe sends an unsubscribe request, returning its request ID.
+func (c *WebSocketClient) Unsubscribe(subscription Subscription, pairs ...string) (int64, error) {
+	return c.sendSubscription(EventUnsubscribe, subscription, pairs)
+}
+
+func (c *WebSocketClient) sendSubscription(event string, subscription Subscription, pairs []string) (int64, error) {
+	if subscription.IsPrivate() && subscription.Token == "" {
+		return 0, fmt.Errorf("%s requires a token", subscription.Name)
+	}
+	request := subscribeRequest{
+		Event:        event,
+		ReqID:        c.reqID(),
+		Pair:         pairs,
+		Subscription: subscription,
+	}
+	return request.ReqID, c.write(request)
+}
+
+// Ping sends a ping event, answered with a pong event.
+func (c *WebSocketClient) Ping() error {
+	return c.write(map[string]interface{}{
+		"event": EventPing,
+		"reqid": c.reqID(),
+	})
+}
+
+// Next reads and decodes the next message.
+func (c *WebSocketClient) Next() (*WebSocketMessage, error) {
+	_, body, err := c.Conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWebSocketMessage(body)
+}
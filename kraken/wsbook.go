@@ -0,0 +1,213 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrBookChecksum is returned by Book.Apply when the book no longer
+	// matches the Kraken checksum. The book must be resubscribed to.
+	ErrBookChecksum = errors.New("book checksum mismatch")
+
+	// ErrBookNotSynced is returned by Book.Apply for an update received
+	// before a snapshot.
+	ErrBookNotSynced = errors.New("book update before snapshot")
+)
+
+// The number of levels per side covered by the checksum.
+const bookChecksumLevels = 10
+
+// Book is an order book maintained from the snapshot and updates of a book
+// channel subscription.
+type Book struct {
+	depth int
+
+	lock   sync.RWMutex
+	synced bool
+	asks   []BookLevel
+	bids   []BookLevel
+}
+
+// NewBook creates a book for a subscription with the given depth. Levels
+// beyond the depth are dropped, as Kraken stops sending updates for them.
+func NewBook(depth int) *Book {
+	return &Book{depth: depth}
+}
+
+// Synced returns true once a snapshot has been applied.
+func (b *Book) Synced() bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.synced
+}
+
+// Reset clears the book until the next snapshot.
+func (b *Book) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.synced = false
+	b.asks = nil
+	b.bids = nil
+}
+
+// Apply applies a snapshot or update. If the update has a checksum it is
+// verified and ErrBookChecksum returned on mismatch, after which the book
+// is reset.
+func (b *Book) Apply(update *BookUpdate) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if update.Snapshot {
+		b.asks = nil
+		b.bids = nil
+		b.synced = true
+	} else if !b.synced {
+		return ErrBookNotSynced
+	}
+
+	for _, level := range update.Asks {
+		b.asks = applyBookLevel(b.asks, level, false, b.depth)
+	}
+	for _, level := range update.Bids {
+		b.bids = applyBookLevel(b.bids, level, true, b.depth)
+	}
+
+	if update.HasChecksum && b.checksum() != update.Checksum {
+		b.synced = false
+		b.asks = nil
+		b.bids = nil
+		return ErrBookChecksum
+	}
+	return nil
+}
+
+// applyBookLevel inserts, replaces or removes a level, keeping asks sorted
+// by ascending price and bids by descending price.
+func applyBookLevel(levels []BookLevel, level BookLevel, descending bool, depth int) []BookLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price <= level.Price
+		}
+		return levels[i].Price >= level.Price
+	})
+	found := i < len(levels) && levels[i].Price == level.Price
+	switch {
+	case level.Volume == 0:
+		if found {
+			levels = append(levels[:i], levels[i+1:]...)
+		}
+	case found:
+		levels[i] = level
+	default:
+		levels = append(levels, BookLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = level
+	}
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	return levels
+}
+
+// Checksum returns the Kraken CRC32 checksum of the top 10 levels.
+func (b *Book) Checksum() uint32 {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.checksum()
+}
+
+func (b *Book) checksum() uint32 {
+	var builder strings.Builder
+	write := func(levels []BookLevel) {
+		for i, level := range levels {
+			if i == bookChecksumLevels {
+				break
+			}
+			builder.WriteString(checksumValue(level.price))
+			builder.WriteString(checksumValue(level.volume))
+		}
+	}
+	write(b.asks)
+	write(b.bids)
+	return crc32.ChecksumIEEE([]byte(builder.String()))
+}
+
+// checksumValue removes the decimal point and leading zeros of a price or
+// volume.
+func checksumValue(value string) string {
+	return strings.TrimLeft(strings.Replace(value, ".", "", 1), "0")
+}
+
+// Copy returns a copy of the book that isn't changed by later updates.
+func (b *Book) Copy() *Book {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return &Book{
+		depth:  b.depth,
+		synced: b.synced,
+		asks:   append([]BookLevel{}, b.asks...),
+		bids:   append([]BookLevel{}, b.bids...),
+	}
+}
+
+// Asks returns a copy of the asks, lowest price first.
+func (b *Book) Asks() []BookLevel {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return append([]BookLevel{}, b.asks...)
+}
+
+// Bids returns a copy of the bids, highest price first.
+func (b *Book) Bids() []BookLevel {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return append([]BookLevel{}, b.bids...)
+}
+
+// BestAsk returns the lowest ask, and false if there are none.
+func (b *Book) BestAsk() (BookLevel, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if len(b.asks) == 0 {
+		return BookLevel{}, false
+	}
+	return b.asks[0], true
+}
+
+// BestBid returns the highest bid, and false if there are none.
+func (b *Book) BestBid() (BookLevel, bool) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	if len(b.bids) == 0 {
+		return BookLevel{}, false
+	}
+	return b.bids[0], true
+}
@@ -0,0 +1,75 @@
+package kraken
+
+import (
+	"hash/crc32"
+	"testing"
+)
+
+func bookLevel(price string, volume string) BookLevel {
+	levels, err := decodeBookLevels([]byte(`[["` + price + `","` + volume + `","1534614248.123678"]]`))
+	if err != nil {
+		panic(err)
+	}
+	return levels[0]
+}
+
+func TestBookApply(t *testing.T) {
+	book := NewBook(3)
+	if err := book.Apply(&BookUpdate{Asks: []BookLevel{bookLevel("1.0", "1")}}); err != ErrBookNotSynced {
+		t.Fatalf("expected not synced, got %v", err)
+	}
+
+	err := book.Apply(&BookUpdate{
+		Snapshot: true,
+		Asks: []BookLevel{
+			bookLevel("0.05005", "0.00000500"),
+			bookLevel("0.05010", "0.00000500"),
+			bookLevel("0.05015", "0.00000500"),
+		},
+		Bids: []BookLevel{
+			bookLevel("0.05000", "0.00000500"),
+			bookLevel("0.04995", "0.00000500"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := crc32.ChecksumIEEE([]byte(
+		"5005500" + "5010500" + "5015500" + "5000500" + "4995500"))
+	if book.Checksum() != expected {
+		t.Fatalf("unexpected checksum %d, expected %d", book.Checksum(), expected)
+	}
+
+	// Insert a better ask, pushing the worst one beyond the depth, and
+	// remove a bid.
+	update := &BookUpdate{
+		Asks: []BookLevel{bookLevel("0.05001", "0.00001000")},
+		Bids: []BookLevel{bookLevel("0.04995", "0.00000000")},
+	}
+	update.Checksum = crc32.ChecksumIEEE([]byte(
+		"50011000" + "5005500" + "5010500" + "5000500"))
+	update.HasChecksum = true
+	if err := book.Apply(update); err != nil {
+		t.Fatal(err)
+	}
+	asks := book.Asks()
+	if len(asks) != 3 || asks[0].Price != 0.05001 || asks[2].Price != 0.0501 {
+		t.Fatalf("unexpected asks: %+v", asks)
+	}
+	if bid, _ := book.BestBid(); bid.Price != 0.05 || len(book.Bids()) != 1 {
+		t.Fatalf("unexpected bids: %+v", book.Bids())
+	}
+
+	update = &BookUpdate{
+		Bids:        []BookLevel{bookLevel("0.05000", "0.00000600")},
+		Checksum:    1,
+		HasChecksum: true,
+	}
+	if err := book.Apply(update); err != ErrBookChecksum {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+	if book.Synced() {
+		t.Fatal("book should be reset after a checksum error")
+	}
+}
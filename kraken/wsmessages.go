@@ -0,0 +1,587 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kraken
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/khayrullo/cryptotrader/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Websocket channel names.
+const (
+	ChannelTicker     = "ticker"
+	ChannelOHLC       = "ohlc"
+	ChannelTrade      = "trade"
+	ChannelSpread     = "spread"
+	ChannelBook       = "book"
+	ChannelOwnTrades  = "ownTrades"
+	ChannelOpenOrders = "openOrders"
+)
+
+// Websocket event names.
+const (
+	EventHeartbeat          = "heartbeat"
+	EventPing               = "ping"
+	EventPong               = "pong"
+	EventSystemStatus       = "systemStatus"
+	EventSubscribe          = "subscribe"
+	EventUnsubscribe        = "unsubscribe"
+	EventSubscriptionStatus = "subscriptionStatus"
+	EventError              = "error"
+)
+
+// Subscription describes a websocket channel. Interval is the OHLC interval
+// in minutes and Depth the book depth. Token is required by the private
+// channels.
+type Subscription struct {
+	Name     string `json:"name"`
+	Interval int    `json:"interval,omitempty"`
+	Depth    int    `json:"depth,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// IsPrivate returns true for the channels of the authenticated endpoint.
+func (s Subscription) IsPrivate() bool {
+	return s.Name == ChannelOwnTrades || s.Name == ChannelOpenOrders
+}
+
+func TickerSubscription() Subscription {
+	return Subscription{Name: ChannelTicker}
+}
+
+func OHLCSubscription(interval int) Subscription {
+	return Subscription{Name: ChannelOHLC, Interval: interval}
+}
+
+func TradeSubscription() Subscription {
+	return Subscription{Name: ChannelTrade}
+}
+
+func SpreadSubscription() Subscription {
+	return Subscription{Name: ChannelSpread}
+}
+
+// BookSubscription subscribes to the order book with a depth of 10, 25,
+// 100, 500 or 1000 levels.
+func BookSubscription(depth int) Subscription {
+	return Subscription{Name: ChannelBook, Depth: depth}
+}
+
+func OwnTradesSubscription() Subscription {
+	return Subscription{Name: ChannelOwnTrades}
+}
+
+func OpenOrdersSubscription() Subscription {
+	return Subscription{Name: ChannelOpenOrders}
+}
+
+type SystemStatus struct {
+	ConnectionID json.Number `json:"connectionID"`
+	Status       string      `json:"status"`
+	Version      string      `json:"version"`
+}
+
+type SubscriptionStatus struct {
+	ChannelName  string       `json:"channelName"`
+	Pair         string       `json:"pair"`
+	Status       string       `json:"status"`
+	Subscription Subscription `json:"subscription"`
+	ErrorMessage string       `json:"errorMessage"`
+	ReqID        int64        `json:"reqid"`
+}
+
+type WSCandle struct {
+	Candle
+	EndTime time.Time
+}
+
+type WSSpread struct {
+	Time      time.Time
+	Bid       float64
+	Ask       float64
+	BidVolume float64
+	AskVolume float64
+}
+
+// BookLevel is a price level of a book update. A zero volume removes the
+// level.
+type BookLevel struct {
+	Price     float64
+	Volume    float64
+	Timestamp time.Time
+
+	// The price and volume as sent, used for the checksum.
+	price  string
+	volume string
+}
+
+// BookUpdate is a book snapshot, or an update of the levels that changed.
+type BookUpdate struct {
+	Snapshot bool
+	Asks     []BookLevel
+	Bids     []BookLevel
+
+	// The checksum of the top 10 levels after applying the update. Only
+	// sent with updates.
+	Checksum    uint32
+	HasChecksum bool
+}
+
+// OrderUpdate is an entry of the openOrders channel. The snapshot and new
+// orders have a description and set Order; later updates only carry the
+// fields that changed, ie: the status or the executed volume.
+type OrderUpdate struct {
+	ID     string
+	Status OrderStatus
+
+	// Nil if the update has no order description.
+	Order *Order
+
+	// Only valid if the update includes them.
+	VolumeExecuted float64
+	Cost           float64
+	Fee            float64
+	AveragePrice   float64
+
+	Raw json.RawMessage
+}
+
+// WebSocketMessage is a decoded websocket message. Event messages set
+// Event, channel messages set Channel and the field for it.
+type WebSocketMessage struct {
+	Event              string
+	SystemStatus       *SystemStatus
+	SubscriptionStatus *SubscriptionStatus
+
+	// The error message of an "error" event.
+	ErrorMessage string
+
+	// The name of the channel, ie: "book", and the full name sent by
+	// Kraken, ie: "book-10".
+	Channel     string
+	ChannelName string
+	Pair        string
+
+	// The sequence number of private channel messages.
+	Sequence int64
+
+	Ticker     *Ticker
+	Candle     *WSCandle
+	Trades     []RecentTrade
+	Spread     *WSSpread
+	BookUpdate *BookUpdate
+	OwnTrades  []Trade
+	OpenOrders []OrderUpdate
+
+	Raw []byte
+}
+
+// DecodeWebSocketMessage decodes a websocket message. Unknown events and
+// channels are returned with only the names and Raw set.
+func DecodeWebSocketMessage(raw []byte) (*WebSocketMessage, error) {
+	message := &WebSocketMessage{Raw: raw}
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("empty message")
+	}
+	if trimmed[0] == '{' {
+		return message, decodeEvent(message, trimmed)
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(trimmed, &parts); err != nil {
+		return nil, err
+	}
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("unexpected message: %s", raw)
+	}
+
+	// Public channel messages are [channelID, payload..., channelName,
+	// pair], private ones [payload, channelName, {"sequence": n}].
+	var payloads []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(parts[0]), []byte("[")) {
+		payloads = parts[:1]
+		if err := json.Unmarshal(parts[1], &message.ChannelName); err != nil {
+			return nil, err
+		}
+		var sequence struct {
+			Sequence int64 `json:"sequence"`
+		}
+		json.Unmarshal(parts[2], &sequence)
+		message.Sequence = sequence.Sequence
+	} else {
+		if len(parts) < 4 {
+			return nil, fmt.Errorf("unexpected message: %s", raw)
+		}
+		payloads = parts[1 : len(parts)-2]
+		if err := json.Unmarshal(parts[len(parts)-2], &message.ChannelName); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(parts[len(parts)-1], &message.Pair); err != nil {
+			return nil, err
+		}
+	}
+	message.Channel = message.ChannelName
+	if i := strings.Index(message.Channel, "-"); i > -1 {
+		message.Channel = message.Channel[:i]
+	}
+
+	var err error
+	switch message.Channel {
+	case ChannelTicker:
+		message.Ticker, err = decodeWSTicker(message.Pair, payloads[0])
+	case ChannelOHLC:
+		message.Candle, err = decodeWSCandle(payloads[0])
+	case ChannelTrade:
+		message.Trades, err = decodeWSTrades(payloads[0])
+	case ChannelSpread:
+		message.Spread, err = decodeWSSpread(payloads[0])
+	case ChannelBook:
+		message.BookUpdate, err = decodeWSBook(payloads)
+	case ChannelOwnTrades:
+		message.OwnTrades, err = decodeWSOwnTrades(payloads[0])
+	case ChannelOpenOrders:
+		message.OpenOrders, err = decodeWSOpenOrders(payloads[0])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", message.ChannelName, err)
+	}
+	return message, nil
+}
+
+func decodeEvent(message *WebSocketMessage, raw []byte) error {
+	var event struct {
+		Event        string `json:"event"`
+		ErrorMessage string `json:"errorMessage"`
+	}
+	if err := json.Unmarshal(raw, &event); err != nil {
+		return err
+	}
+	message.Event = event.Event
+	switch event.Event {
+	case EventSystemStatus:
+		message.SystemStatus = &SystemStatus{}
+		return json.Unmarshal(raw, message.SystemStatus)
+	case EventSubscriptionStatus:
+		message.SubscriptionStatus = &SubscriptionStatus{}
+		if err := json.Unmarshal(raw, message.SubscriptionStatus); err != nil {
+			return err
+		}
+		message.ChannelName = message.SubscriptionStatus.ChannelName
+		message.Pair = message.SubscriptionStatus.Pair
+	case EventError:
+		message.ErrorMessage = event.ErrorMessage
+	}
+	return nil
+}
+
+// decodeRow decodes an array of values sent as strings or numbers.
+func decodeRow(raw json.RawMessage) ([]interface{}, error) {
+	var row []interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func decodeWSTicker(pair string, raw json.RawMessage) (*Ticker, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	values := map[string][]float64{}
+	for key, field := range fields {
+		row, err := decodeRow(field)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range row {
+			v, err := parseValue(value)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = append(values[key], v)
+		}
+	}
+	at := func(key string, i int) float64 {
+		if i < len(values[key]) {
+			return values[key][i]
+		}
+		return 0
+	}
+
+	normalizedPair := GetNormalizePairName(pair)
+	return &Ticker{
+		Pair:        normalizedPair,
+		Timestamp:   time.Now(),
+		Ask:         at("a", 0),
+		AskVolume:   at("a", 2),
+		Bid:         at("b", 0),
+		BidVolume:   at("b", 2),
+		Last:        at("c", 0),
+		LastVolume:  at("c", 1),
+		VolumeToday: at("v", 0),
+		Volume24h:   at("v", 1),
+		VWAPToday:   at("p", 0),
+		VWAP24h:     at("p", 1),
+		TradesToday: int64(at("t", 0)),
+		Trades24h:   int64(at("t", 1)),
+		LowToday:    at("l", 0),
+		Low24h:      at("l", 1),
+		HighToday:   at("h", 0),
+		High24h:     at("h", 1),
+		Open:        at("o", 0),
+	}, nil
+}
+
+func decodeWSCandle(raw json.RawMessage) (*WSCandle, error) {
+	row, err := decodeRow(raw)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseRow(row, 9)
+	if err != nil {
+		return nil, err
+	}
+	return &WSCandle{
+		Candle: Candle{
+			Time:   util.Float64ToTime(values[0]),
+			Open:   values[2],
+			High:   values[3],
+			Low:    values[4],
+			Close:  values[5],
+			VWAP:   values[6],
+			Volume: values[7],
+			Count:  int64(values[8]),
+		},
+		EndTime: util.Float64ToTime(values[1]),
+	}, nil
+}
+
+func decodeWSTrades(raw json.RawMessage) ([]RecentTrade, error) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+	trades := []RecentTrade{}
+	for _, rawRow := range rows {
+		row, err := decodeRow(rawRow)
+		if err != nil {
+			return nil, err
+		}
+		values, err := parseRow(row, 3)
+		if err != nil {
+			return nil, err
+		}
+		trade := RecentTrade{
+			Price:  values[0],
+			Volume: values[1],
+			Time:   util.Float64ToTime(values[2]),
+		}
+		if len(row) > 5 {
+			if side, _ := row[3].(string); side == "b" {
+				trade.Side = OrderSideBuy
+			} else {
+				trade.Side = OrderSideSell
+			}
+			if orderType, _ := row[4].(string); orderType == "m" {
+				trade.OrderType = OrderTypeMarket
+			} else {
+				trade.OrderType = OrderTypeLimit
+			}
+			trade.Misc, _ = row[5].(string)
+		}
+		trades = append(trades, trade)
+	}
+	return trades, nil
+}
+
+func decodeWSSpread(raw json.RawMessage) (*WSSpread, error) {
+	row, err := decodeRow(raw)
+	if err != nil {
+		return nil, err
+	}
+	values, err := parseRow(row, 3)
+	if err != nil {
+		return nil, err
+	}
+	spread := &WSSpread{
+		Bid:  values[0],
+		Ask:  values[1],
+		Time: util.Float64ToTime(values[2]),
+	}
+	if len(row) >= 5 {
+		spread.BidVolume, _ = parseValue(row[3])
+		spread.AskVolume, _ = parseValue(row[4])
+	}
+	return spread, nil
+}
+
+func decodeWSBook(payloads []json.RawMessage) (*BookUpdate, error) {
+	update := &BookUpdate{}
+	for _, payload := range payloads {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return nil, err
+		}
+		for key, field := range fields {
+			var err error
+			switch key {
+			case "as":
+				update.Snapshot = true
+				update.Asks, err = decodeBookLevels(field)
+			case "bs":
+				update.Snapshot = true
+				update.Bids, err = decodeBookLevels(field)
+			case "a":
+				update.Asks, err = decodeBookLevels(field)
+			case "b":
+				update.Bids, err = decodeBookLevels(field)
+			case "c":
+				var checksum string
+				if err = json.Unmarshal(field, &checksum); err == nil {
+					var value uint64
+					value, err = strconv.ParseUint(checksum, 10, 32)
+					update.Checksum = uint32(value)
+					update.HasChecksum = true
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return update, nil
+}
+
+func decodeBookLevels(raw json.RawMessage) ([]BookLevel, error) {
+	var rows [][]string
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, err
+	}
+	levels := []BookLevel{}
+	for _, row := range rows {
+		if len(row) < 3 {
+			return nil, fmt.Errorf("unexpected book level: %v", row)
+		}
+		level := BookLevel{
+			price:  row[0],
+			volume: row[1],
+		}
+		var err error
+		if level.Price, err = strconv.ParseFloat(row[0], 64); err != nil {
+			return nil, err
+		}
+		if level.Volume, err = strconv.ParseFloat(row[1], 64); err != nil {
+			return nil, err
+		}
+		timestamp, err := strconv.ParseFloat(row[2], 64)
+		if err != nil {
+			return nil, err
+		}
+		level.Timestamp = util.Float64ToTime(timestamp)
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// wsRawTrade is a RawTrade as sent on the websocket, with the time as a
+// string.
+type wsRawTrade struct {
+	RawTrade
+	Time json.Number `json:"time"`
+}
+
+func decodeWSOwnTrades(raw json.RawMessage) ([]Trade, error) {
+	var entries []map[string]wsRawTrade
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	trades := []Trade{}
+	for _, entry := range entries {
+		for id, raw := range entry {
+			raw.RawTrade.Time, _ = raw.Time.Float64()
+			trades = append(trades, NewTradeFromRaw(id, raw.RawTrade))
+		}
+	}
+	return trades, nil
+}
+
+// wsRawOrder is a RawOrder as sent on the websocket, with the times as
+// strings and the average price as avg_price.
+type wsRawOrder struct {
+	RawOrder
+	OpenTime     json.Number `json:"opentm"`
+	StartTime    json.Number `json:"starttm"`
+	ExpireTime   json.Number `json:"expiretm"`
+	CloseTime    json.Number `json:"closetm"`
+	AveragePrice string      `json:"avg_price"`
+}
+
+func decodeWSOpenOrders(raw json.RawMessage) ([]OrderUpdate, error) {
+	var entries []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	updates := []OrderUpdate{}
+	for _, entry := range entries {
+		for id, rawOrder := range entry {
+			order := wsRawOrder{}
+			if err := json.Unmarshal(rawOrder, &order); err != nil {
+				return nil, err
+			}
+			order.RawOrder.OpenTime, _ = order.OpenTime.Float64()
+			order.RawOrder.StartTime, _ = order.StartTime.Float64()
+			order.RawOrder.ExpireTime, _ = order.ExpireTime.Float64()
+			order.RawOrder.CloseTime, _ = order.CloseTime.Float64()
+			if order.AveragePrice != "" {
+				order.RawOrder.Price = order.AveragePrice
+			}
+
+			update := OrderUpdate{
+				ID:     id,
+				Status: OrderStatus(order.Status),
+				Raw:    rawOrder,
+			}
+			update.VolumeExecuted, _ = strconv.ParseFloat(order.VolumeExecuted, 64)
+			update.Cost, _ = strconv.ParseFloat(order.Cost, 64)
+			update.Fee, _ = strconv.ParseFloat(order.Fee, 64)
+			update.AveragePrice, _ = strconv.ParseFloat(order.RawOrder.Price, 64)
+			if order.Description.Pair != "" {
+				full := NewOrderFromRaw(id, order.RawOrder)
+				update.Order = &full
+			}
+			updates = append(updates, update)
+		}
+	}
+	return updates, nil
+}
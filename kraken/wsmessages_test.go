@@ -0,0 +1,99 @@
+package kraken
+
+import (
+	"testing"
+)
+
+func decodeTestMessage(t *testing.T, raw string) *WebSocketMessage {
+	message, err := DecodeWebSocketMessage([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+func TestDecodeEvents(t *testing.T) {
+	message := decodeTestMessage(t, `{"connectionID":8628615390848610000,"event":"systemStatus","status":"online","version":"1.0.0"}`)
+	if message.SystemStatus == nil || message.SystemStatus.Status != "online" {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+
+	message = decodeTestMessage(t, `{"channelID":10001,"channelName":"book-10","event":"subscriptionStatus","pair":"XBT/EUR","reqid":42,"status":"subscribed","subscription":{"depth":10,"name":"book"}}`)
+	status := message.SubscriptionStatus
+	if status == nil || status.ReqID != 42 || status.Subscription.Depth != 10 || message.Pair != "XBT/EUR" {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+
+	message = decodeTestMessage(t, `{"event":"heartbeat"}`)
+	if message.Event != EventHeartbeat {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+}
+
+func TestDecodeTicker(t *testing.T) {
+	message := decodeTestMessage(t, `[0,{"a":["5525.40000",1,"1.000"],"b":["5525.10000",1,"1.000"],"c":["5525.10000","0.00398963"],"v":["2634.11501494","3591.17907851"],"p":["5631.44067","5653.78939"],"t":[11493,16267],"l":["5505.00000","5505.00000"],"h":["5783.00000","5783.00000"],"o":["5760.70000","5763.40000"]},"ticker","XBT/USD"]`)
+	ticker := message.Ticker
+	if message.Channel != ChannelTicker || ticker == nil {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+	if ticker.Pair != "BTC/USD" || ticker.Ask != 5525.4 || ticker.LastVolume != 0.00398963 ||
+		ticker.Trades24h != 16267 || ticker.Open != 5760.7 {
+		t.Fatalf("unexpected ticker: %+v", ticker)
+	}
+}
+
+func TestDecodeChannels(t *testing.T) {
+	message := decodeTestMessage(t, `[42,["1542057314.748456","1542057360.435743","3586.70000","3586.70000","3586.60000","3586.60000","3586.68894","0.03373000",2],"ohlc-5","XBT/USD"]`)
+	if message.Channel != ChannelOHLC || message.ChannelName != "ohlc-5" ||
+		message.Candle.Close != 3586.6 || message.Candle.Count != 2 {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+
+	message = decodeTestMessage(t, `[0,[["5541.20000","0.15850568","1534614057.321597","s","l",""],["6060.00000","0.02455000","1534614057.324998","b","m",""]],"trade","XBT/USD"]`)
+	if len(message.Trades) != 2 || message.Trades[0].Side != OrderSideSell ||
+		message.Trades[1].OrderType != OrderTypeMarket {
+		t.Fatalf("unexpected trades: %+v", message.Trades)
+	}
+
+	message = decodeTestMessage(t, `[0,["5698.40000","5700.00000","1542057299.545897","1.01234567","0.98765432"],"spread","XBT/USD"]`)
+	if message.Spread.Bid != 5698.4 || message.Spread.AskVolume != 0.98765432 {
+		t.Fatalf("unexpected spread: %+v", message.Spread)
+	}
+
+	message = decodeTestMessage(t, `[1234,{"a":[["5541.30000","2.50700000","1534614248.456738"]]},{"b":[["5541.20000","1.52900000","1534614248.765567"]],"c":"974942666"},"book-10","XBT/USD"]`)
+	update := message.BookUpdate
+	if update.Snapshot || len(update.Asks) != 1 || len(update.Bids) != 1 ||
+		!update.HasChecksum || update.Checksum != 974942666 {
+		t.Fatalf("unexpected book update: %+v", update)
+	}
+
+	message = decodeTestMessage(t, `[0,{"as":[["5541.30000","2.50700000","1534614248.123678"]],"bs":[["5541.20000","1.52900000","1534614248.765567"]]},"book-100","XBT/USD"]`)
+	if !message.BookUpdate.Snapshot || message.BookUpdate.Asks[0].Volume != 2.507 {
+		t.Fatalf("unexpected book snapshot: %+v", message.BookUpdate)
+	}
+}
+
+func TestDecodePrivateChannels(t *testing.T) {
+	message := decodeTestMessage(t, `[[{"TDLH43-DVQXD-2KHVYY":{"cost":"1000000.00000","fee":"1600.00000","margin":"0.00000","ordertxid":"TDLH43-DVQXD-2KHVYY","ordertype":"limit","pair":"XBT/EUR","postxid":"OGTT3Y-C6I3P-XRI6HX","price":"100000.00000","time":"1560516023.070651","type":"sell","vol":"1000000000.00000000"}}],"ownTrades",{"sequence":2948}]`)
+	if message.Channel != ChannelOwnTrades || message.Sequence != 2948 || len(message.OwnTrades) != 1 {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+	trade := message.OwnTrades[0]
+	if trade.TradeID != "TDLH43-DVQXD-2KHVYY" || trade.Side != OrderSideSell ||
+		trade.Fee != 1600 || trade.Timestamp.Unix() != 1560516023 {
+		t.Fatalf("unexpected trade: %+v", trade)
+	}
+
+	message = decodeTestMessage(t, `[[{"OGTT3Y-C6I3P-XRI6HX":{"status":"open","opentm":"1560516023.070651","descr":{"pair":"XBT/EUR","type":"buy","ordertype":"limit","price":"34.50000"},"vol":"10.00345345","vol_exec":"0.00000000","cost":"0.00000","fee":"0.00000","avg_price":"0.00000"}},{"OGTT3Y-C6I3P-XRI6HX":{"status":"closed","vol_exec":"10.00345345","avg_price":"34.40000"}}],"openOrders",{"sequence":234}]`)
+	if len(message.OpenOrders) != 2 {
+		t.Fatalf("unexpected message: %+v", message)
+	}
+	opened, closed := message.OpenOrders[0], message.OpenOrders[1]
+	if opened.Order == nil || opened.Order.Price != 34.5 || opened.Order.OpenTime.Unix() != 1560516023 {
+		t.Fatalf("unexpected open order: %+v", opened)
+	}
+	if closed.Order != nil || closed.Status != OrderStatusClosed ||
+		closed.VolumeExecuted != 10.00345345 || closed.AveragePrice != 34.4 {
+		t.Fatalf("unexpected order update: %+v", closed)
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"net/http"
 	"time"
 	"sort"
+	"github.com/khayrullo/cryptotrader/core"
 )
 
 const API_ROOT = "https://api.kucoin.com"
@@ -40,21 +41,67 @@ const API_ROOT = "https://api.kucoin.com"
 type Client struct {
 	apiKey    string
 	apiSecret string
+
+	core.HTTPClient
+}
+
+// ClientOption configures optional settings of a Client.
+type ClientOption func(*Client)
+
+// WithBaseURL is core.WithBaseURL. The default is API_ROOT.
+func WithBaseURL(baseURL string) ClientOption {
+	return withHTTPOption(core.WithBaseURL(baseURL))
+}
+
+// WithHTTPClient is core.WithHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return withHTTPOption(core.WithHTTPClient(httpClient))
+}
+
+// WithUserAgent is core.WithUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return withHTTPOption(core.WithUserAgent(userAgent))
+}
+
+// WithTimeout is core.WithTimeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return withHTTPOption(core.WithTimeout(timeout))
+}
+
+// WithRateLimiter is core.WithRateLimiter.
+func WithRateLimiter(rateLimiter core.RateLimiter) ClientOption {
+	return withHTTPOption(core.WithRateLimiter(rateLimiter))
+}
+
+func withHTTPOption(opt core.HTTPOption) ClientOption {
+	return func(c *Client) {
+		opt(&c.HTTPClient)
+	}
+}
+
+func (c *Client) applyOptions(opts []ClientOption) {
+	c.HTTPClient = core.NewHTTPClient(API_ROOT)
+	for _, opt := range opts {
+		opt(c)
+	}
 }
 
-func NewClient(key string, secret string) *Client {
+func NewClient(key string, secret string, opts ...ClientOption) *Client {
 	client := Client{}
 	client.apiKey = key
 	client.apiSecret = secret
+	client.applyOptions(opts)
 	return &client
 }
 
-func NewAnonymousClient() *Client {
-	return &Client{}
+func NewAnonymousClient(opts ...ClientOption) *Client {
+	client := &Client{}
+	client.applyOptions(opts)
+	return client
 }
 
 func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", API_ROOT, endpoint)
+	url := fmt.Sprintf("%s%s", c.BaseURL(), endpoint)
 	queryString := ""
 
 	if params != nil {
@@ -71,7 +118,7 @@ func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Resp
 	if c.apiKey != "" && c.apiSecret != "" {
 		c.authenticateRequest(request, endpoint, queryString)
 	}
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *Client) authenticateRequest(request *http.Request, endpoint string,
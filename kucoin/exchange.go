@@ -0,0 +1,151 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package kucoin
+
+import (
+	"fmt"
+
+	"github.com/khayrullo/cryptotrader/core"
+	"github.com/khayrullo/cryptotrader/util"
+)
+
+const ExchangeName = "KuCoin"
+
+var _ core.Exchange = (*Exchange)(nil)
+
+// Exchange adapts a Client to the core.Exchange interface. Symbols are in
+// the KuCoin "COIN-PAIR" format, for example "ETH-BTC".
+type Exchange struct {
+	client *Client
+}
+
+func NewExchange(client *Client) *Exchange {
+	return &Exchange{
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+func (e *Exchange) Tickers(symbols ...string) ([]core.Ticker, error) {
+	response, err := e.client.GetTick()
+	if err != nil {
+		return nil, err
+	}
+	if !response.Success {
+		return nil, fmt.Errorf("%s", response.Message)
+	}
+
+	wanted := map[string]bool{}
+	for _, symbol := range symbols {
+		wanted[symbol] = true
+	}
+
+	tickers := []core.Ticker{}
+	for _, entry := range response.Entries {
+		if len(wanted) > 0 && !wanted[entry.Symbol] {
+			continue
+		}
+		tickers = append(tickers, core.Ticker{
+			Exchange:  ExchangeName,
+			Symbol:    entry.Symbol,
+			Timestamp: util.MillisToTime(entry.DateTimeMillis),
+			Bid:       entry.Buy,
+			Ask:       entry.Sell,
+			Last:      entry.LastDealPrice,
+		})
+	}
+	return tickers, nil
+}
+
+func (e *Exchange) OrderBook(symbol string, depth int) (*core.OrderBook, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) Balances() ([]core.Balance, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) PlaceOrder(order core.OrderRequest) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) CancelOrder(symbol string, orderID string) error {
+	return core.ErrNotSupported
+}
+
+func (e *Exchange) QueryOrder(symbol string, orderID string) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+// Trades returns the dealt orders for a symbol. KuCoin only provides dealt
+// orders across all symbols, so every page has to be fetched and filtered.
+func (e *Exchange) Trades(symbol string) ([]core.Trade, error) {
+	trades := []core.Trade{}
+	count := 0
+	for page := 0; ; page++ {
+		response, err := e.client.GetDealtOrders(100, page)
+		if err != nil {
+			return nil, err
+		}
+		if !response.Success {
+			return nil, fmt.Errorf("%s", response.Message)
+		}
+		if len(response.Data.Trades) == 0 {
+			break
+		}
+
+		for _, trade := range response.Data.Trades {
+			count++
+			tradeSymbol := fmt.Sprintf("%s-%s", trade.CoinType, trade.CoinTypePair)
+			if tradeSymbol != symbol {
+				continue
+			}
+			feeAsset := trade.CoinTypePair
+			if trade.Direction == "BUY" {
+				feeAsset = trade.CoinType
+			}
+			trades = append(trades, core.Trade{
+				Exchange:  ExchangeName,
+				Symbol:    tradeSymbol,
+				TradeID:   trade.OID,
+				OrderID:   trade.OrderID,
+				Side:      core.OrderSide(trade.Direction),
+				Price:     trade.DealPrice,
+				Quantity:  trade.Amount,
+				Fee:       trade.Fee,
+				FeeAsset:  feeAsset,
+				Timestamp: trade.Timestamp,
+			})
+		}
+
+		if int64(count) >= response.Data.Total {
+			break
+		}
+	}
+	return trades, nil
+}
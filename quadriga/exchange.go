@@ -0,0 +1,169 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2018 Cranky Kernel
+//
+// Permission is hereby granted, free of charge, to any person
+// obtaining a copy of this software and associated documentation
+// files (the "Software"), to deal in the Software without
+// restriction, including without limitation the rights to use, copy,
+// modify, merge, publish, distribute, sublicense, and/or sell copies
+// of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package quadriga
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+
+	"github.com/khayrullo/cryptotrader/core"
+)
+
+const ExchangeName = "QuadrigaCX"
+
+var _ core.Exchange = (*Exchange)(nil)
+
+// Exchange adapts a Client to the core.Exchange interface. Symbols are
+// QuadrigaCX book names, for example "btc_cad".
+type Exchange struct {
+	client *Client
+}
+
+func NewExchange(client *Client) *Exchange {
+	return &Exchange{
+		client: client,
+	}
+}
+
+func (e *Exchange) Name() string {
+	return ExchangeName
+}
+
+type tickerResponse struct {
+	Last float64 `json:"last,string"`
+	Bid  float64 `json:"bid,string"`
+	Ask  float64 `json:"ask,string"`
+}
+
+type orderBookResponse struct {
+	Bids [][]string `json:"bids"`
+	Asks [][]string `json:"asks"`
+}
+
+func (e *Exchange) Tickers(books ...string) ([]core.Ticker, error) {
+	if len(books) == 0 {
+		var err error
+		books, err = e.client.Books()
+		if err != nil {
+			return nil, err
+		}
+	}
+	tickers := []core.Ticker{}
+	for _, book := range books {
+		var response tickerResponse
+		if err := e.getAndDecode("/v2/ticker", map[string]interface{}{
+			"book": book,
+		}, &response); err != nil {
+			return nil, err
+		}
+		tickers = append(tickers, core.Ticker{
+			Exchange:  ExchangeName,
+			Symbol:    book,
+			Timestamp: time.Now(),
+			Bid:       response.Bid,
+			Ask:       response.Ask,
+			Last:      response.Last,
+		})
+	}
+	return tickers, nil
+}
+
+func (e *Exchange) OrderBook(book string, depth int) (*core.OrderBook, error) {
+	var response orderBookResponse
+	if err := e.getAndDecode("/v2/order_book", map[string]interface{}{
+		"book": book,
+	}, &response); err != nil {
+		return nil, err
+	}
+	orderBook := &core.OrderBook{
+		Exchange:  ExchangeName,
+		Symbol:    book,
+		Timestamp: time.Now(),
+	}
+	orderBook.Bids = parseOrderBookEntries(response.Bids, depth)
+	orderBook.Asks = parseOrderBookEntries(response.Asks, depth)
+	return orderBook, nil
+}
+
+func (e *Exchange) Balances() ([]core.Balance, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) PlaceOrder(order core.OrderRequest) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) CancelOrder(book string, orderID string) error {
+	return core.ErrNotSupported
+}
+
+func (e *Exchange) QueryOrder(book string, orderID string) (*core.Order, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) Trades(book string) ([]core.Trade, error) {
+	return nil, core.ErrNotSupported
+}
+
+func (e *Exchange) getAndDecode(endpoint string, params map[string]interface{}, v interface{}) error {
+	response, err := e.client.Get(endpoint, params)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != 200 {
+		return fmt.Errorf("%s: %s", response.Status, string(body))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func parseOrderBookEntries(raw [][]string, depth int) []core.OrderBookEntry {
+	entries := []core.OrderBookEntry{}
+	for _, level := range raw {
+		if depth > 0 && len(entries) == depth {
+			break
+		}
+		if len(level) < 2 {
+			continue
+		}
+		price, _ := strconv.ParseFloat(level[0], 64)
+		quantity, _ := strconv.ParseFloat(level[1], 64)
+		entries = append(entries, core.OrderBookEntry{
+			Price:    price,
+			Quantity: quantity,
+		})
+	}
+	return entries
+}
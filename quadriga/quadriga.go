@@ -35,6 +35,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"io/ioutil"
+	"strings"
+	"github.com/khayrullo/cryptotrader/core"
 )
 
 const API_ROOT = "https://api.quadrigacx.com"
@@ -43,19 +45,65 @@ type Client struct {
 	clientId  string
 	apiKey    string
 	apiSecret string
+
+	core.HTTPClient
+}
+
+// ClientOption configures optional settings of a Client.
+type ClientOption func(*Client)
+
+// WithBaseURL is core.WithBaseURL. The default is API_ROOT.
+func WithBaseURL(baseURL string) ClientOption {
+	return withHTTPOption(core.WithBaseURL(baseURL))
+}
+
+// WithHTTPClient is core.WithHTTPClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return withHTTPOption(core.WithHTTPClient(httpClient))
 }
 
-func NewClient(clientId interface{}, apiKey string, apiSecret string) *Client {
+// WithUserAgent is core.WithUserAgent.
+func WithUserAgent(userAgent string) ClientOption {
+	return withHTTPOption(core.WithUserAgent(userAgent))
+}
+
+// WithTimeout is core.WithTimeout.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return withHTTPOption(core.WithTimeout(timeout))
+}
+
+// WithRateLimiter is core.WithRateLimiter.
+func WithRateLimiter(rateLimiter core.RateLimiter) ClientOption {
+	return withHTTPOption(core.WithRateLimiter(rateLimiter))
+}
+
+func withHTTPOption(opt core.HTTPOption) ClientOption {
+	return func(c *Client) {
+		opt(&c.HTTPClient)
+	}
+}
+
+func (c *Client) applyOptions(opts []ClientOption) {
+	c.HTTPClient = core.NewHTTPClient(API_ROOT)
+	for _, opt := range opts {
+		opt(c)
+	}
+}
+
+func NewClient(clientId interface{}, apiKey string, apiSecret string, opts ...ClientOption) *Client {
 	quadriga := &Client{
 		clientId:  fmt.Sprintf("%s", clientId),
 		apiKey:    apiKey,
 		apiSecret: apiSecret,
 	}
+	quadriga.applyOptions(opts)
 	return quadriga
 }
 
-func NewAnonymousClient() *Client {
-	return &Client{}
+func NewAnonymousClient(opts ...ClientOption) *Client {
+	client := &Client{}
+	client.applyOptions(opts)
+	return client
 }
 
 func (c *Client) Post(endpoint string, params map[string]interface{}) (*http.Response, error) {
@@ -66,7 +114,7 @@ func (c *Client) Post(endpoint string, params map[string]interface{}) (*http.Res
 		return nil, err
 	}
 	request.Header.Set("Content-Type", "application/json")
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Response, error) {
@@ -79,7 +127,7 @@ func (c *Client) Get(endpoint string, params map[string]interface{}) (*http.Resp
 	if err != nil {
 		return nil, err
 	}
-	return http.DefaultClient.Do(request)
+	return c.Do(request)
 }
 
 func (c *Client) authenticateParams(params map[string]interface{}) map[string]interface{} {
@@ -104,7 +152,7 @@ func (c *Client) authenticateParams(params map[string]interface{}) map[string]in
 }
 
 func (c *Client) buildURL(endpoint string) string {
-	return fmt.Sprintf("%s/%s", API_ROOT, endpoint)
+	return fmt.Sprintf("%s/%s", c.BaseURL(), strings.TrimLeft(endpoint, "/"))
 }
 
 // buildQueryString converts the params map into a query string sorted by
@@ -173,6 +221,10 @@ func (c *Client) Books() ([]string, error) {
 // RequestEngineOrders calls an undocumented URL that the Quadriga frontend
 // userse to get the order book.
 func (c *Client) RequestEngineOrders(book string) (*http.Response, error) {
-	return http.DefaultClient.Get(fmt.Sprintf(
-		"https://www.quadrigacx.com/engine/orders/%s", book))
+	request, err := http.NewRequest("GET", fmt.Sprintf(
+		"https://www.quadrigacx.com/engine/orders/%s", book), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(request)
 }